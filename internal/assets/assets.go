@@ -57,3 +57,8 @@ func GetMetadata(imageName string) *data.AssetMetadata {
 	}
 	return m
 }
+
+// FindMetadata returns the metadata attached to the image, or nil if the metadata doesn't exist.
+func FindMetadata(imageName string) *data.AssetMetadata {
+	return theAssets.metadata["images/"+imageName+"_metadata.json"]
+}
@@ -28,13 +28,21 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/tint"
 )
 
 const (
-	PlayerEventID = -1
-	frameInerval  = 60
-	iconWidth     = 16
-	iconHeight    = 16
+	PlayerEventID   = -1
+	FollowerEventID = -2
+	frameInerval    = 60
+	iconWidth       = 16
+	iconHeight      = 16
+
+	defaultJumpFrames = 30
+
+	// bushDepth is the height of the translucent part of a character on a bush tile.
+	bushDepth = 6
 )
 
 var characterFileRegexp = regexp.MustCompile(".*_([0-9]+)_([0-9]+)(_loop)?")
@@ -70,6 +78,11 @@ type Character struct {
 	idleFrameCount  int
 	moveCount       int
 	moveDir         data.Dir
+	jumpX           int
+	jumpY           int
+	jumpHeight      int
+	jumpCount       int
+	jumpMaxCount    int
 	visible         bool
 	through         bool
 	erased          bool
@@ -78,8 +91,21 @@ type Character struct {
 	targetOpacity   int
 	opacityCount    int
 	opacityMaxCount int
+	animation       string
+	animationCount  int
+
+	// scale is nil when the character is not scaled.
+	scale     *interpolation.I
+	flipX     bool
+	flipY     bool
+	tint      tint.Tint
+	blendType data.ShowPictureBlendType
+	zOffset   int
+	shadow    bool
 
 	// Not dumped
+	bush           bool
+	ladder         bool
 	sizeW          int
 	sizeH          int
 	dirCount       int
@@ -88,6 +114,8 @@ type Character struct {
 	imageH         int
 	storedState    *StoredState
 	imageInfoCache *ImageInfo
+	spriteCache    *data.SpriteMetadata
+	spriteCached   bool
 }
 
 func NewPlayer(x, y int) *Character {
@@ -122,11 +150,35 @@ func NewEvent(id int, x, y int) *Character {
 	}
 }
 
+// NewFollower creates a character that trails the player.
+// A follower is not an event and cannot be specified by an event ID.
+func NewFollower(x, y int, imageType data.ImageType, imageName string) *Character {
+	c := &Character{
+		eventID:       FollowerEventID,
+		speed:         data.Speed3,
+		x:             x,
+		y:             y,
+		dir:           data.DirDown,
+		visible:       true,
+		frame:         1,
+		steppingDir:   1,
+		walking:       true,
+		opacity:       255,
+		targetOpacity: 255,
+	}
+	c.SetImage(imageType, imageName)
+	return c
+}
+
 func (c *Character) SetSizeForTesting(w, h int) {
 	c.imageW = w
 	c.imageH = h
 }
 
+func (c *Character) DrawFrameForTesting() int {
+	return c.drawFrame()
+}
+
 func (c *Character) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e := easymsgpack.NewEncoder(enc)
 	e.BeginMap()
@@ -179,9 +231,26 @@ func (c *Character) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("idleFrameCount")
 	e.EncodeInt(c.idleFrameCount)
 
+	e.EncodeString("animation")
+	e.EncodeString(c.animation)
+
+	e.EncodeString("animationCount")
+	e.EncodeInt(c.animationCount)
+
 	e.EncodeString("moveDir")
 	e.EncodeInt(int(c.moveDir))
 
+	e.EncodeString("jumpX")
+	e.EncodeInt(c.jumpX)
+	e.EncodeString("jumpY")
+	e.EncodeInt(c.jumpY)
+	e.EncodeString("jumpHeight")
+	e.EncodeInt(c.jumpHeight)
+	e.EncodeString("jumpCount")
+	e.EncodeInt(c.jumpCount)
+	e.EncodeString("jumpMaxCount")
+	e.EncodeInt(c.jumpMaxCount)
+
 	e.EncodeString("visible")
 	e.EncodeBool(c.visible)
 
@@ -202,6 +271,31 @@ func (c *Character) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("opacityMaxCount")
 	e.EncodeInt(c.opacityMaxCount)
 
+	e.EncodeString("scale")
+	if c.scale != nil {
+		e.EncodeInterface(c.scale)
+	} else {
+		e.EncodeNil()
+	}
+
+	e.EncodeString("flipX")
+	e.EncodeBool(c.flipX)
+
+	e.EncodeString("flipY")
+	e.EncodeBool(c.flipY)
+
+	e.EncodeString("tint")
+	e.EncodeInterface(&c.tint)
+
+	e.EncodeString("blendType")
+	e.EncodeString(string(c.blendType))
+
+	e.EncodeString("zOffset")
+	e.EncodeInt(c.zOffset)
+
+	e.EncodeString("shadow")
+	e.EncodeBool(c.shadow)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -244,8 +338,22 @@ func (c *Character) DecodeMsgpack(dec *msgpack.Decoder) error {
 			c.moveCount = d.DecodeInt()
 		case "idleFrameCount":
 			c.idleFrameCount = d.DecodeInt()
+		case "animation":
+			c.animation = d.DecodeString()
+		case "animationCount":
+			c.animationCount = d.DecodeInt()
 		case "moveDir":
 			c.moveDir = data.Dir(d.DecodeInt())
+		case "jumpX":
+			c.jumpX = d.DecodeInt()
+		case "jumpY":
+			c.jumpY = d.DecodeInt()
+		case "jumpHeight":
+			c.jumpHeight = d.DecodeInt()
+		case "jumpCount":
+			c.jumpCount = d.DecodeInt()
+		case "jumpMaxCount":
+			c.jumpMaxCount = d.DecodeInt()
 		case "visible":
 			c.visible = d.DecodeBool()
 		case "through":
@@ -262,6 +370,23 @@ func (c *Character) DecodeMsgpack(dec *msgpack.Decoder) error {
 			c.opacityCount = d.DecodeInt()
 		case "opacityMaxCount":
 			c.opacityMaxCount = d.DecodeInt()
+		case "scale":
+			if !d.SkipCodeIfNil() {
+				c.scale = &interpolation.I{}
+				d.DecodeInterface(c.scale)
+			}
+		case "flipX":
+			c.flipX = d.DecodeBool()
+		case "flipY":
+			c.flipY = d.DecodeBool()
+		case "tint":
+			d.DecodeInterface(&c.tint)
+		case "blendType":
+			c.blendType = data.ShowPictureBlendType(d.DecodeString())
+		case "zOffset":
+			c.zOffset = d.DecodeInt()
+		case "shadow":
+			c.shadow = d.DecodeBool()
 		}
 	}
 	if err := d.Error(); err != nil {
@@ -313,11 +438,35 @@ func (c *Character) getImage() *ebiten.Image {
 	panic("character: invalid image type:" + c.imageType)
 }
 
+// sprite returns the sprite metadata of the image, or nil if the image doesn't have it.
+func (c *Character) sprite() *data.SpriteMetadata {
+	if c.spriteCached {
+		return c.spriteCache
+	}
+	c.spriteCached = true
+	c.spriteCache = nil
+	if c.imageName == "" || c.imageType != data.ImageTypeCharacters {
+		return nil
+	}
+	if m := assets.FindMetadata("characters/" + c.imageName); m != nil {
+		c.spriteCache = m.Sprite
+	}
+	return c.spriteCache
+}
+
 func (c *Character) imageInfo() *ImageInfo {
 	if c.imageInfoCache != nil {
 		return c.imageInfoCache
 	}
 
+	if s := c.sprite(); s != nil {
+		c.imageInfoCache = &ImageInfo{
+			SizeW: s.FrameWidth,
+			SizeH: s.FrameHeight,
+		}
+		return c.imageInfoCache
+	}
+
 	arr := characterFileRegexp.FindStringSubmatch(c.imageName)
 	if len(arr) != 4 {
 		log.Printf("Invalid image is loaded: %s", c.imageName)
@@ -342,6 +491,10 @@ func (c *Character) imageInfo() *ImageInfo {
 
 }
 
+func (c *Character) ImageName() string {
+	return c.imageName
+}
+
 func (c *Character) ImageSize() (int, int) {
 	if c.imageName == "" {
 		return 0, 0
@@ -384,6 +537,9 @@ func (c *Character) DirCount() int {
 	if c.imageName == "" || c.imageType == data.ImageTypeIcons {
 		return 1
 	}
+	if s := c.sprite(); s != nil && len(s.Dirs) > 0 {
+		return len(s.Dirs)
+	}
 	if c.dirCount == 0 {
 		_, imageH := c.ImageSize()
 		_, h := c.Size()
@@ -395,10 +551,14 @@ func (c *Character) DirCount() int {
 	return c.dirCount
 }
 
+// FrameCount returns the number of the frames for the walking cycle.
 func (c *Character) FrameCount() int {
 	if c.imageName == "" || c.imageType == data.ImageTypeIcons {
 		return 1
 	}
+	if s := c.sprite(); s != nil && s.WalkFrames > 0 {
+		return s.WalkFrames
+	}
 	if c.frameCount == 0 {
 		imageW, _ := c.ImageSize()
 		w, _ := c.Size()
@@ -416,21 +576,12 @@ func (c *Character) BaseFrame() int {
 }
 
 func (c *Character) Position() (int, int) {
+	if c.jumpCount > 0 {
+		return c.x + c.jumpX, c.y + c.jumpY
+	}
 	if c.moveCount > 0 {
-		x, y := c.x, c.y
-		switch c.moveDir {
-		case data.DirLeft:
-			x--
-		case data.DirRight:
-			x++
-		case data.DirUp:
-			y--
-		case data.DirDown:
-			y++
-		default:
-			panic(fmt.Sprintf("character: invalid moveDir: %d at Position", c.moveDir))
-		}
-		return x, y
+		dx, dy := c.moveDir.Delta()
+		return c.x + dx, c.y + dy
 	}
 	return c.x, c.y
 }
@@ -440,22 +591,28 @@ func (c *Character) DrawFootPosition() (int, int) {
 	y := (c.y + 1) * consts.TileSize
 	if c.moveCount > 0 {
 		d := (c.speed.Frames() - c.moveCount) * consts.TileSize / c.speed.Frames()
-		switch c.moveDir {
-		case data.DirLeft:
-			x -= d
-		case data.DirRight:
-			x += d
-		case data.DirUp:
-			y -= d
-		case data.DirDown:
-			y += d
-		default:
-			panic(fmt.Sprintf("character: invalid moveDir: %d at DrawFootPosition", c.moveDir))
-		}
+		dx, dy := c.moveDir.Delta()
+		x += dx * d
+		y += dy * d
+	}
+	if c.jumpCount > 0 {
+		d := c.jumpMaxCount - c.jumpCount
+		x += c.jumpX * consts.TileSize * d / c.jumpMaxCount
+		y += c.jumpY * consts.TileSize * d / c.jumpMaxCount
 	}
 	return x, y
 }
 
+// jumpOffset returns the height of the character from the ground while jumping.
+func (c *Character) jumpOffset() int {
+	if c.jumpCount == 0 {
+		return 0
+	}
+	// The arc is a parabola that is 0 at both ends and jumpHeight at the middle.
+	t := float64(c.jumpMaxCount-c.jumpCount) / float64(c.jumpMaxCount)
+	return int(4 * float64(c.jumpHeight) * t * (1 - t))
+}
+
 func (c *Character) DrawPosition() (int, int) {
 	x, y := c.DrawFootPosition()
 	charW, charH := c.Size()
@@ -467,11 +624,50 @@ func (c *Character) Dir() data.Dir {
 }
 
 func (c *Character) IsMoving() bool {
-	return c.moveCount > 0
+	return c.moveCount > 0 || c.jumpCount > 0
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func (c *Character) IsJumping() bool {
+	return c.jumpCount > 0
+}
+
+// Jump starts jumping to the relative position (dx, dy) in tiles.
+// Jump doesn't check passability: the caller should validate the landing tile.
+//
+// count is the duration of the jump in frames, and height is the peak height of the arc in pixels.
+// Zero count or height means the default value.
+func (c *Character) Jump(dx, dy int, count int, height int) {
+	if count <= 0 {
+		count = defaultJumpFrames
+	}
+	if height <= 0 {
+		// The farther the character jumps, the higher the arc is.
+		d := abs(dx)
+		if d < abs(dy) {
+			d = abs(dy)
+		}
+		height = consts.TileSize * (d + 1) / 2
+	}
+	c.jumpX = dx
+	c.jumpY = dy
+	c.jumpHeight = height
+	c.jumpCount = count
+	c.jumpMaxCount = count
 }
 
 func (c *Character) Move(dir data.Dir) {
-	c.Turn(dir)
+	if c.ladder && dir != data.DirUp && dir != data.DirDown {
+		c.Turn(data.DirUp)
+	} else {
+		c.Turn(dir)
+	}
 	c.moveDir = dir
 	// TODO: Rename this
 	c.moveCount = c.speed.Frames()
@@ -528,6 +724,19 @@ func (c *Character) SetWalking(walking bool) {
 	c.walking = walking
 }
 
+// SetBush sets whether the character is on a bush tile.
+// The state is not dumped since this is updated every frame based on the tile.
+func (c *Character) SetBush(bush bool) {
+	c.bush = bush
+}
+
+// SetLadder sets whether the character is on a ladder tile.
+// A character on a ladder tile faces up when it starts a step in a direction other than up or down.
+// The state is not dumped since this is updated every frame based on the tile.
+func (c *Character) SetLadder(ladder bool) {
+	c.ladder = ladder
+}
+
 func (c *Character) SetThrough(through bool) {
 	c.through = through
 }
@@ -544,6 +753,9 @@ func (c *Character) SetImage(imageType data.ImageType, imageName string) {
 	c.frameCount = 0
 	c.steppingDir = 1
 	c.imageInfoCache = nil
+	c.spriteCached = false
+	c.animation = ""
+	c.animationCount = 0
 
 	if prevFrameCount != c.FrameCount() {
 		c.frame = c.BaseFrame()
@@ -572,10 +784,68 @@ func (c *Character) IsChangingOpacity() bool {
 	return c.opacityCount > 0
 }
 
+// SetScale changes the scale of the character in count frames. The character is scaled around its foot.
+func (c *Character) SetScale(scale float64, count int) {
+	if c.scale == nil {
+		c.scale = interpolation.New(1)
+	}
+	c.scale.Set(scale, count)
+}
+
+func (c *Character) IsChangingScale() bool {
+	return c.scale != nil && c.scale.IsChanging()
+}
+
+func (c *Character) currentScale() float64 {
+	if c.scale == nil {
+		return 1
+	}
+	return c.scale.Current()
+}
+
+func (c *Character) SetFlip(flipX, flipY bool) {
+	c.flipX = flipX
+	c.flipY = flipY
+}
+
+func (c *Character) Flip() (bool, bool) {
+	return c.flipX, c.flipY
+}
+
+// SetTint changes the tint of the character in count frames.
+// The values are in [-1, 1] as tint.Tint.
+func (c *Character) SetTint(red, green, blue, gray float64, count int) {
+	c.tint.Set(red, green, blue, gray, count)
+}
+
+func (c *Character) IsChangingTint() bool {
+	return c.tint.IsChanging()
+}
+
+func (c *Character) SetBlendType(blendType data.ShowPictureBlendType) {
+	c.blendType = blendType
+}
+
+// SetShadow sets whether a blob shadow is drawn under the character.
+func (c *Character) SetShadow(shadow bool) {
+	c.shadow = shadow
+}
+
+func (c *Character) SetZOffset(zOffset int) {
+	c.zOffset = zOffset
+}
+
+// DrawOrderY returns the Y position to determine the draw order. A character with a larger value is drawn later.
+func (c *Character) DrawOrderY() int {
+	_, y := c.DrawFootPosition()
+	return y + c.zOffset
+}
+
 func (c *Character) TransferImmediately(x, y int) {
 	c.x = x
 	c.y = y
 	c.moveCount = 0
+	c.jumpCount = 0
 }
 
 func (c *Character) Erase() {
@@ -593,8 +863,12 @@ func (c *Character) UpdateWithPage(page *data.Page) {
 	c.sizeH = 0
 	c.frameCount = 0
 	c.imageInfoCache = nil
+	c.spriteCached = false
+	c.animation = ""
+	c.animationCount = 0
 	if page == nil {
 		c.imageName = ""
+		c.shadow = false
 		c.dirFix = false
 		c.dir = data.Dir(0)
 		c.frame = 0
@@ -604,6 +878,8 @@ func (c *Character) UpdateWithPage(page *data.Page) {
 	}
 	c.imageName = page.Image
 	c.imageType = page.ImageType
+	c.zOffset = page.ZOffset
+	c.shadow = page.Shadow
 	c.dirFix = page.DirFix
 	c.dir = page.Dir
 	c.frame = page.Frame
@@ -652,9 +928,14 @@ func (c *Character) Update() {
 	} else {
 		c.opacity = c.targetOpacity
 	}
+	if c.scale != nil {
+		c.scale.Update()
+	}
+	c.tint.Update()
 	if c.erased {
 		return
 	}
+	c.updateAnimation()
 	if c.stepping {
 		c.progressFrame(1)
 	}
@@ -667,30 +948,29 @@ func (c *Character) Update() {
 		c.idleFrameCount++
 		return
 	}
+	c.idleFrameCount = 0
+	if c.jumpCount > 0 {
+		c.jumpCount--
+		if c.jumpCount == 0 {
+			c.x += c.jumpX
+			c.y += c.jumpY
+			c.jumpX = 0
+			c.jumpY = 0
+		}
+		return
+	}
+
 	if !c.stepping && c.walking {
 		c.progressFrame(2)
 	}
 
-	c.idleFrameCount = 0
 	if c.moveCount > 0 {
 		c.moveCount--
 	}
 	if c.moveCount == 0 {
-		nx, ny := c.x, c.y
-		switch c.moveDir {
-		case data.DirLeft:
-			nx--
-		case data.DirRight:
-			nx++
-		case data.DirUp:
-			ny--
-		case data.DirDown:
-			ny++
-		default:
-			panic(fmt.Sprintf("character: invalid moveDir: %d", c.moveDir))
-		}
-		c.x = nx
-		c.y = ny
+		dx, dy := c.moveDir.Delta()
+		c.x += dx
+		c.y += dy
 
 		if c.newSpeed != 0 {
 			c.speed = c.newSpeed
@@ -699,6 +979,128 @@ func (c *Character) Update() {
 	}
 }
 
+// PlayAnimation starts the animation of the given name defined in the image's sprite metadata.
+// Empty name stops the current animation.
+func (c *Character) PlayAnimation(name string) {
+	c.animation = name
+	c.animationCount = 0
+}
+
+// IsAnimating reports whether a non-looping animation started by PlayAnimation is playing.
+func (c *Character) IsAnimating() bool {
+	if c.animation == "" {
+		return false
+	}
+	s := c.sprite()
+	if s == nil {
+		return false
+	}
+	a := s.Animation(c.animation)
+	if a == nil {
+		return false
+	}
+	return !a.Loop
+}
+
+func (c *Character) updateAnimation() {
+	if c.animation == "" {
+		return
+	}
+	var a *data.SpriteAnimation
+	if s := c.sprite(); s != nil {
+		a = s.Animation(c.animation)
+	}
+	if a == nil {
+		log.Printf("character: animation not found: %s (%s)", c.animation, c.imageName)
+		c.animation = ""
+		c.animationCount = 0
+		return
+	}
+	c.animationCount++
+	if !a.Loop && c.animationCount >= a.Length() {
+		c.animation = ""
+		c.animationCount = 0
+	}
+}
+
+// animationFrame returns the column to draw for the playing animation or the idle animation.
+// animationFrame returns false when no animation is playing.
+func (c *Character) animationFrame() (int, bool) {
+	s := c.sprite()
+	if s == nil {
+		return 0, false
+	}
+	if c.animation != "" {
+		if a := s.Animation(c.animation); a != nil {
+			return a.FrameAt(c.animationCount), true
+		}
+	}
+	if s.Idle != "" && !c.stepping && !c.IsMoving() {
+		if a := s.Animation(s.Idle); a != nil {
+			return a.FrameAt(c.idleFrameCount), true
+		}
+	}
+	return 0, false
+}
+
+// drawFrame returns the column to draw.
+func (c *Character) drawFrame() int {
+	if f, ok := c.animationFrame(); ok {
+		return f
+	}
+	return c.frame
+}
+
+var theShadowImage *ebiten.Image
+
+// shadowImage returns a black ellipse whose alpha falls off from the center.
+func shadowImage() *ebiten.Image {
+	if theShadowImage != nil {
+		return theShadowImage
+	}
+	const (
+		w = 32
+		h = 16
+	)
+	pix := make([]byte, 4*w*h)
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			dx := (float64(i) + 0.5 - w/2) / (w / 2)
+			dy := (float64(j) + 0.5 - h/2) / (h / 2)
+			d := dx*dx + dy*dy
+			if d >= 1 {
+				continue
+			}
+			// Only the alpha is set since the color is black.
+			pix[4*(j*w+i)+3] = byte((1 - d) * 0xff)
+		}
+	}
+	theShadowImage, _ = ebiten.NewImage(w, h, ebiten.FilterDefault)
+	theShadowImage.ReplacePixels(pix)
+	return theShadowImage
+}
+
+// drawShadow draws the blob shadow at the character's foot. The shadow shrinks while the character is jumping.
+func (c *Character) drawShadow(screen *ebiten.Image, offsetX, offsetY int) {
+	img := shadowImage()
+	w, h := img.Size()
+	scale := float64(consts.TileSize) * 3 / 4 / float64(w)
+	if c.jumpHeight > 0 {
+		scale *= 1 - 0.5*float64(c.jumpOffset())/float64(c.jumpHeight)
+	}
+	x, y := c.DrawFootPosition()
+	op := &ebiten.DrawImageOptions{}
+	// The bottom of the shadow is at the foot.
+	op.GeoM.Translate(-float64(w)/2, -float64(h))
+	op.GeoM.Scale(scale*c.currentScale(), scale*c.currentScale())
+	op.GeoM.Translate(float64(x+offsetX), float64(y+offsetY))
+	op.ColorM.Scale(1, 1, 1, 0.4*float64(c.opacity)/255)
+	screen.DrawImage(img, op)
+}
+
+// dirToIndex returns the row index for the direction in an image with 8 directions.
+// The rows are up, right, down, left, up-right, down-right, down-left and up-left in this order,
+// so that the first 4 rows are compatible with an image with 4 directions.
 func (c *Character) dirToIndex(dir data.Dir) int {
 	switch c.dir {
 	case data.DirUp:
@@ -709,6 +1111,14 @@ func (c *Character) dirToIndex(dir data.Dir) int {
 		return 2
 	case data.DirLeft:
 		return 3
+	case data.DirUpRight:
+		return 4
+	case data.DirDownRight:
+		return 5
+	case data.DirDownLeft:
+		return 6
+	case data.DirUpLeft:
+		return 7
 	}
 
 	return 0
@@ -720,36 +1130,90 @@ func (c *Character) Draw(screen *ebiten.Image, offsetX, offsetY int) {
 	}
 	charW, charH := c.Size()
 	dirIndex := c.dirToIndex(c.dir)
+	if c.DirCount() < 8 && dirIndex >= 4 {
+		// An image without diagonal directions uses the horizontal directions instead.
+		switch c.dir {
+		case data.DirUpRight, data.DirDownRight:
+			dirIndex = 1
+		case data.DirDownLeft, data.DirUpLeft:
+			dirIndex = 3
+		}
+	}
 
-	sx := c.frame * charW
+	sx := c.drawFrame() * charW
 	sy := 0
 	scaleX := 1.0
 	scaleY := 1.0
-	switch c.DirCount() {
-	case 1:
-		sy = 0
-	case 2:
-		sy = dirIndex / 2 * charH
-	case 3:
-		if dirIndex == 3 {
-			// Reuse the second frame and mirror it
-			sy = 1 * charH
+	if s := c.sprite(); s != nil && len(s.Dirs) > 0 {
+		if dirIndex >= len(s.Dirs) {
+			dirIndex = 0
+		}
+		d := s.Dirs[dirIndex]
+		sy = d.Row * charH
+		if d.Mirror {
 			scaleX = -1.0
-		} else {
+		}
+	} else {
+		switch c.DirCount() {
+		case 1:
+			sy = 0
+		case 2:
+			sy = dirIndex / 2 * charH
+		case 3:
+			if dirIndex == 3 {
+				// Reuse the second frame and mirror it
+				sy = 1 * charH
+				scaleX = -1.0
+			} else {
+				sy = dirIndex * charH
+			}
+		case 4, 8:
 			sy = dirIndex * charH
+		default:
+			panic(fmt.Sprintf("character: not supported DirCount %s %d", c.imageName, c.DirCount()))
 		}
-	case 4:
-		sy = dirIndex * charH
-	default:
-		panic(fmt.Sprintf("character: not supported DirCount %s %d", c.imageName, c.DirCount()))
 	}
 
-	op := &ebiten.DrawImageOptions{}
+	if c.flipX {
+		scaleX = -scaleX
+	}
+	if c.flipY {
+		scaleY = -scaleY
+	}
+	scale := c.currentScale()
+
+	if c.shadow {
+		c.drawShadow(screen, offsetX, offsetY)
+	}
+
 	x, y := c.DrawPosition()
-	op.GeoM.Translate(float64(-charW/2), float64(-charH/2))
-	op.GeoM.Scale(scaleX, scaleY)
-	op.GeoM.Translate(float64(charW/2), float64(charH/2))
-	op.GeoM.Translate(float64(x+offsetX), float64(y+offsetY))
-	op.ColorM.Scale(1, 1, 1, float64(c.opacity)/255)
-	screen.DrawImage(c.getImage().SubImage(image.Rect(sx, sy, sx+charW, sy+charH)).(*ebiten.Image), op)
+	img := c.getImage()
+	// drawPart draws the rows from top to bottom of the character.
+	drawPart := func(top, bottom int, alpha float64) {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(0, float64(top))
+		op.GeoM.Translate(float64(-charW/2), float64(-charH/2))
+		op.GeoM.Scale(scaleX, scaleY)
+		op.GeoM.Translate(float64(charW/2), float64(charH/2))
+		if scale != 1 {
+			// Scale around the foot.
+			op.GeoM.Translate(float64(-charW/2), float64(-charH))
+			op.GeoM.Scale(scale, scale)
+			op.GeoM.Translate(float64(charW/2), float64(charH))
+		}
+		op.GeoM.Translate(float64(x+offsetX), float64(y+offsetY-c.jumpOffset()))
+		c.tint.Apply(&op.ColorM)
+		op.ColorM.Scale(1, 1, 1, float64(c.opacity)/255*alpha)
+		if c.blendType == data.ShowPictureBlendTypeAdd {
+			op.CompositeMode = ebiten.CompositeModeLighter
+		}
+		screen.DrawImage(img.SubImage(image.Rect(sx, sy+top, sx+charW, sy+bottom)).(*ebiten.Image), op)
+	}
+
+	if !c.bush || c.IsJumping() || charH <= bushDepth {
+		drawPart(0, charH, 1)
+		return
+	}
+	drawPart(0, charH-bushDepth, 1)
+	drawPart(charH-bushDepth, charH, 0.5)
 }
@@ -17,6 +17,9 @@ package character_test
 import (
 	"testing"
 
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
 	. "github.com/hajimehoshi/rpgsnack-runtime/internal/character"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 )
@@ -70,3 +73,205 @@ func TestCharacterDirCount(t *testing.T) {
 		t.Errorf("output: %d, want %d", frameCount, 4)
 	}
 }
+
+func TestCharacterJump(t *testing.T) {
+	c := NewEvent(1, 1, 1)
+	c.Jump(2, -1, 10, 0)
+	if !c.IsMoving() {
+		t.Errorf("IsMoving(): got: false, want: true")
+	}
+	if x, y := c.Position(); x != 3 || y != 0 {
+		t.Errorf("Position(): got: (%d, %d), want: (3, 0)", x, y)
+	}
+
+	for i := 0; i < 5; i++ {
+		c.Update()
+	}
+
+	// The mid-jump state must survive saving and loading.
+	bin, err := msgpack.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := &Character{}
+	if err := msgpack.Unmarshal(bin, c2); err != nil {
+		t.Fatal(err)
+	}
+	x0, y0 := c.DrawFootPosition()
+	x1, y1 := c2.DrawFootPosition()
+	if x0 != x1 || y0 != y1 {
+		t.Errorf("DrawFootPosition(): got: (%d, %d), want: (%d, %d)", x1, y1, x0, y0)
+	}
+
+	for i := 0; i < 5; i++ {
+		c2.Update()
+	}
+	if c2.IsMoving() {
+		t.Errorf("IsMoving(): got: true, want: false")
+	}
+	if x, y := c2.Position(); x != 3 || y != 0 {
+		t.Errorf("Position(): got: (%d, %d), want: (3, 0)", x, y)
+	}
+}
+
+func TestCharacterLadder(t *testing.T) {
+	cases := []struct {
+		Ladder bool
+		DirFix bool
+		Dir    data.Dir
+		Want   data.Dir
+	}{
+		{false, false, data.DirRight, data.DirRight},
+		{true, false, data.DirRight, data.DirUp},
+		{true, false, data.DirLeft, data.DirUp},
+		{true, false, data.DirDown, data.DirDown},
+		{true, false, data.DirUp, data.DirUp},
+		{true, true, data.DirRight, data.DirLeft},
+		{true, true, data.DirDown, data.DirLeft},
+	}
+	for _, tc := range cases {
+		c := NewEvent(1, 1, 1)
+		c.SetDir(data.DirLeft)
+		c.SetDirFix(tc.DirFix)
+		c.SetLadder(tc.Ladder)
+		c.Move(tc.Dir)
+		if got := c.Dir(); got != tc.Want {
+			t.Errorf("ladder: %t, dirFix: %t, Move(%d): Dir(): got: %d, want: %d", tc.Ladder, tc.DirFix, tc.Dir, got, tc.Want)
+		}
+	}
+}
+
+func TestCharacterTurnOnLadder(t *testing.T) {
+	c := NewEvent(1, 1, 1)
+	c.SetLadder(true)
+	c.Turn(data.DirLeft)
+	for i := 0; i < 10; i++ {
+		c.Update()
+	}
+	// The ladder affects only the steps: a character on a ladder can still turn.
+	if got := c.Dir(); got != data.DirLeft {
+		t.Errorf("Dir(): got: %d, want: %d", got, data.DirLeft)
+	}
+}
+
+// newSpriteTestEvent returns an event with an image that has 3 walk frames and 3 animation frames.
+func newSpriteTestEvent(t *testing.T) *Character {
+	const name = "sprite_test"
+	m := map[string]*data.AssetMetadata{
+		"images/characters/" + name + "_metadata.json": {
+			Sprite: &data.SpriteMetadata{
+				FrameWidth:  24,
+				FrameHeight: 32,
+				Dirs: []*data.SpriteDir{
+					{Row: 0},
+					{Row: 1},
+					{Row: 2},
+					{Row: 3},
+				},
+				WalkFrames: 3,
+				Animations: []*data.SpriteAnimation{
+					{
+						Name: "attack",
+						Frames: []*data.SpriteAnimationFrame{
+							{Frame: 3, Duration: 2},
+							{Frame: 4, Duration: 2},
+						},
+					},
+					{
+						Name: "wave",
+						Frames: []*data.SpriteAnimationFrame{
+							{Frame: 4, Duration: 1},
+							{Frame: 5, Duration: 1},
+						},
+						Loop: true,
+					},
+				},
+			},
+		},
+	}
+	if err := assets.Set(nil, m); err != nil {
+		t.Fatal(err)
+	}
+	c := NewEvent(1, 1, 1)
+	c.SetImage(data.ImageTypeCharacters, name)
+	c.SetSizeForTesting(24*6, 32*4)
+	return c
+}
+
+func TestCharacterWalkFramesWithSprite(t *testing.T) {
+	c := newSpriteTestEvent(t)
+	if got, want := c.FrameCount(), 3; got != want {
+		t.Errorf("FrameCount(): got: %d, want: %d", got, want)
+	}
+	if got, want := c.BaseFrame(), 1; got != want {
+		t.Errorf("BaseFrame(): got: %d, want: %d", got, want)
+	}
+
+	// The walking cycle doesn't use the animation columns.
+	c.SetStepping(true)
+	frames := map[int]bool{}
+	for i := 0; i < 1000; i++ {
+		c.Update()
+		frames[c.DrawFrameForTesting()] = true
+	}
+	for f := range frames {
+		if f < 0 || 3 <= f {
+			t.Errorf("the frame while stepping: got: %d, want: 0 <= frame < 3", f)
+		}
+	}
+	if !frames[0] || !frames[2] {
+		t.Errorf("the frames while stepping: got: %v, want: all of 0, 1 and 2", frames)
+	}
+}
+
+func TestCharacterPlayAnimation(t *testing.T) {
+	c := newSpriteTestEvent(t)
+	base := c.DrawFrameForTesting()
+
+	c.PlayAnimation("attack")
+	if !c.IsAnimating() {
+		t.Errorf("IsAnimating(): got: false, want: true")
+	}
+	for i, want := range []int{3, 3, 4, 4} {
+		if got := c.DrawFrameForTesting(); got != want {
+			t.Errorf("DrawFrameForTesting() at %d: got: %d, want: %d", i, got, want)
+		}
+		c.Update()
+	}
+
+	// A non-looping animation ends and the character shows the frame before the animation.
+	if c.IsAnimating() {
+		t.Errorf("IsAnimating() after the animation: got: true, want: false")
+	}
+	if got, want := c.DrawFrameForTesting(), base; got != want {
+		t.Errorf("DrawFrameForTesting() after the animation: got: %d, want: %d", got, want)
+	}
+}
+
+func TestCharacterPlayLoopAnimation(t *testing.T) {
+	c := newSpriteTestEvent(t)
+
+	c.PlayAnimation("wave")
+	// A looping animation doesn't block the interpreter.
+	if c.IsAnimating() {
+		t.Errorf("IsAnimating(): got: true, want: false")
+	}
+	for i, want := range []int{4, 5, 4, 5, 4} {
+		if got := c.DrawFrameForTesting(); got != want {
+			t.Errorf("DrawFrameForTesting() at %d: got: %d, want: %d", i, got, want)
+		}
+		c.Update()
+	}
+
+	c.PlayAnimation("")
+	if got, want := c.DrawFrameForTesting(), c.BaseFrame(); got != want {
+		t.Errorf("DrawFrameForTesting() after stopping: got: %d, want: %d", got, want)
+	}
+
+	// An unknown animation is stopped at the next update.
+	c.PlayAnimation("unknown")
+	c.Update()
+	if got, want := c.DrawFrameForTesting(), c.BaseFrame(); got != want {
+		t.Errorf("DrawFrameForTesting() with an unknown animation: got: %d, want: %d", got, want)
+	}
+}
@@ -190,3 +190,32 @@ func (c *CommandIterator) Goto(label string) bool {
 	copy(c.indices, p)
 	return true
 }
+
+// Path returns the location of the current command like commands/5/branches/1/0.
+// commandsPath is the location of the commands given to New like maps/1/rooms/2/events/3/pages/0/commands.
+func (c *CommandIterator) Path(commandsPath string) string {
+	p := commandsPath
+	for i, idx := range c.indices {
+		if i%2 == 1 {
+			p += "/branches"
+		}
+		p = fmt.Sprintf("%s/%d", p, idx)
+	}
+	return p
+}
+
+// Walk calls f for the commands and their branches in the same order as CommandIterator visits them.
+// path is the location of the commands, and f is called with the location of each command in the same format as Path.
+// The commands of SetRoute are not visited.
+func Walk(commands []*data.Command, path string, f func(path string, command *data.Command)) {
+	for ci, c := range commands {
+		if c == nil {
+			continue
+		}
+		p := fmt.Sprintf("%s/%d", path, ci)
+		f(p, c)
+		for bi, b := range c.Branches {
+			Walk(b, fmt.Sprintf("%s/branches/%d", p, bi), f)
+		}
+	}
+}
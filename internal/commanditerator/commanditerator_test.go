@@ -154,3 +154,65 @@ func TestGoto(t *testing.T) {
 		}
 	}
 }
+
+func TestPathAndWalk(t *testing.T) {
+	commands := []*data.Command{
+		makeLabelCommand("foo"),
+		makeBranches(
+			[]*data.Command{
+				makeLabelCommand("bar"),
+			},
+			[]*data.Command{
+				makeLabelCommand("baz"),
+				makeLabelCommand("qux"),
+			},
+		),
+		makeLabelCommand("quux"),
+	}
+
+	want := []string{
+		"commands/0",
+		"commands/1",
+		"commands/1/branches/0/0",
+		"commands/1/branches/1/0",
+		"commands/1/branches/1/1",
+		"commands/2",
+	}
+	got := []string{}
+	Walk(commands, "commands", func(path string, command *data.Command) {
+		got = append(got, path)
+	})
+	if len(got) != len(want) {
+		t.Fatalf("Walk: got: %v, want: %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("Walk #%d: got: %s, want: %s", i, got[i], want[i])
+		}
+	}
+
+	// Path returns the same locations as Walk.
+	c := New(commands)
+	if got, want := c.Path("commands"), "commands/0"; got != want {
+		t.Errorf("Path(): got: %s, want: %s", got, want)
+	}
+	c.Advance()
+	c.Choose(1)
+	c.Advance()
+	if got, want := c.Path("commands"), "commands/1/branches/1/1"; got != want {
+		t.Errorf("Path(): got: %s, want: %s", got, want)
+	}
+
+	// Path survives marshaling.
+	b, err := msgpack.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := &CommandIterator{}
+	if err := msgpack.Unmarshal(b, c2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c2.Path("commands"), "commands/1/branches/1/1"; got != want {
+		t.Errorf("Path() after marshaling: got: %s, want: %s", got, want)
+	}
+}
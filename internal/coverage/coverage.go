@@ -0,0 +1,210 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package coverage records which commands and branches are executed in a play session.
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/commanditerator"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+// EventPageTree returns the location of the command tree of an event page.
+func EventPageTree(mapID, roomID, eventID, pageIndex int) string {
+	return fmt.Sprintf("maps/%d/rooms/%d/events/%d/pages/%d", mapID, roomID, eventID, pageIndex)
+}
+
+// CommonEventTree returns the location of the command tree of a common event.
+func CommonEventTree(id int) string {
+	return fmt.Sprintf("commonEvents/%d", id)
+}
+
+// ItemTree returns the location of the command tree of an item.
+func ItemTree(id int) string {
+	return fmt.Sprintf("items/%d", id)
+}
+
+// CombineTree returns the location of the command tree of a combine.
+func CombineTree(id int) string {
+	return fmt.Sprintf("combines/%d", id)
+}
+
+// HintTree returns the location of the command tree of a hint.
+func HintTree(id int) string {
+	return fmt.Sprintf("hints/%d", id)
+}
+
+// CommandsPath returns the location of the commands of the tree.
+func CommandsPath(tree string) string {
+	return tree + "/commands"
+}
+
+// RouteCommandsPath returns the location of the route commands of the SetRoute command at path,
+// or the route commands of the event page at path.
+func RouteCommandsPath(path string) string {
+	return path + "/route/commands"
+}
+
+// Walk calls f for every command in the game.
+//
+// tree is the location of the command tree like maps/1/rooms/2/events/3/pages/0 or commonEvents/4.
+// path is the location of the command like maps/1/rooms/2/events/3/pages/0/commands/5/branches/1/0.
+// Route commands of SetRoute belong to the same tree as the SetRoute command.
+func Walk(game *data.Game, f func(tree, path string, command *data.Command)) {
+	for _, m := range game.Maps {
+		for _, r := range m.Rooms() {
+			for _, e := range r.Events {
+				for pi, p := range e.Pages() {
+					tree := EventPageTree(m.ID(), r.ID, e.ID(), pi)
+					walk(p.Commands, tree, CommandsPath(tree), f)
+					if p.Route != nil {
+						walk(p.Route.Commands, tree, RouteCommandsPath(tree), f)
+					}
+				}
+			}
+		}
+	}
+	for _, e := range game.CommonEvents {
+		tree := CommonEventTree(e.ID)
+		walk(e.Commands, tree, CommandsPath(tree), f)
+	}
+	for _, i := range game.Items {
+		tree := ItemTree(i.ID)
+		walk(i.Commands, tree, CommandsPath(tree), f)
+	}
+	for _, c := range game.Combines {
+		tree := CombineTree(c.ID)
+		walk(c.Commands, tree, CommandsPath(tree), f)
+	}
+	for _, h := range game.Hints {
+		tree := HintTree(h.ID)
+		walk(h.Commands, tree, CommandsPath(tree), f)
+	}
+}
+
+func walk(commands []*data.Command, tree, path string, f func(tree, path string, command *data.Command)) {
+	commanditerator.Walk(commands, path, func(path string, command *data.Command) {
+		f(tree, path, command)
+		if command.Name == data.CommandNameSetRoute {
+			walk(command.Args.(*data.CommandArgsSetRoute).Commands, tree, RouteCommandsPath(path), f)
+		}
+	})
+}
+
+// BranchPath returns the location of the branch of the command at path.
+func BranchPath(path string, branch int) string {
+	return fmt.Sprintf("%s/branches/%d", path, branch)
+}
+
+// Coverage is the executed commands and branches.
+type Coverage struct {
+	Commands []string `json:"commands"`
+	Branches []string `json:"branches"`
+}
+
+type recorder struct {
+	paths    map[string]struct{}
+	commands map[string]struct{}
+	branches map[string]struct{}
+}
+
+// theRecorder is nil unless Start is called.
+var theRecorder *recorder
+
+// Start starts recording.
+// prev is the JSON of the previous coverage of the same session. prev can be nil.
+//
+// Commands are identified by their locations, so that commands restored from a save data are recorded too.
+func Start(game *data.Game, prev []byte) error {
+	r := &recorder{
+		paths:    map[string]struct{}{},
+		commands: map[string]struct{}{},
+		branches: map[string]struct{}{},
+	}
+	Walk(game, func(tree, path string, command *data.Command) {
+		r.paths[path] = struct{}{}
+	})
+	if prev != nil {
+		var c *Coverage
+		if err := json.Unmarshal(prev, &c); err != nil {
+			return fmt.Errorf("coverage: Start failed: %v", err)
+		}
+		for _, p := range c.Commands {
+			r.commands[p] = struct{}{}
+		}
+		for _, p := range c.Branches {
+			r.branches[p] = struct{}{}
+		}
+	}
+	theRecorder = r
+	return nil
+}
+
+// Stop stops recording.
+func Stop() {
+	theRecorder = nil
+}
+
+func IsRecording() bool {
+	return theRecorder != nil
+}
+
+// RecordCommand records that the command at path is executed.
+// A path that is not in the game, like the path of a command generated at runtime, is ignored.
+func RecordCommand(path string) {
+	if theRecorder == nil {
+		return
+	}
+	if _, ok := theRecorder.paths[path]; !ok {
+		return
+	}
+	theRecorder.commands[path] = struct{}{}
+}
+
+// RecordBranch records that the branch of the command at path is chosen.
+func RecordBranch(path string, branch int) {
+	if theRecorder == nil {
+		return
+	}
+	if _, ok := theRecorder.paths[path]; !ok {
+		return
+	}
+	theRecorder.branches[BranchPath(path, branch)] = struct{}{}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Marshal returns the JSON of the recorded coverage.
+// Marshal returns nil if the coverage is not recorded.
+func Marshal() ([]byte, error) {
+	if theRecorder == nil {
+		return nil, nil
+	}
+	c := &Coverage{
+		Commands: sortedKeys(theRecorder.commands),
+		Branches: sortedKeys(theRecorder.branches),
+	}
+	return json.Marshal(c)
+}
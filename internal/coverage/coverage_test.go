@@ -0,0 +1,143 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package coverage_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/coverage"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+func nop() *data.Command {
+	return &data.Command{
+		Name: data.CommandNameNop,
+	}
+}
+
+func testGame() *data.Game {
+	return &data.Game{
+		CommonEvents: []*data.CommonEvent{
+			{
+				ID: 1,
+				Commands: []*data.Command{
+					nop(),
+					{
+						Name: data.CommandNameIf,
+						Args: &data.CommandArgsIf{},
+						Branches: [][]*data.Command{
+							{nop()},
+							{nop()},
+						},
+					},
+					{
+						Name: data.CommandNameSetRoute,
+						Args: &data.CommandArgsSetRoute{
+							Commands: []*data.Command{nop()},
+						},
+					},
+				},
+			},
+		},
+		Items: []*data.Item{
+			{
+				ID:       2,
+				Commands: []*data.Command{nop()},
+			},
+		},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	type entry struct {
+		Tree string
+		Path string
+	}
+	var got []entry
+	Walk(testGame(), func(tree, path string, command *data.Command) {
+		got = append(got, entry{Tree: tree, Path: path})
+	})
+	want := []entry{
+		{Tree: "commonEvents/1", Path: "commonEvents/1/commands/0"},
+		{Tree: "commonEvents/1", Path: "commonEvents/1/commands/1"},
+		{Tree: "commonEvents/1", Path: "commonEvents/1/commands/1/branches/0/0"},
+		{Tree: "commonEvents/1", Path: "commonEvents/1/commands/1/branches/1/0"},
+		{Tree: "commonEvents/1", Path: "commonEvents/1/commands/2"},
+		{Tree: "commonEvents/1", Path: "commonEvents/1/commands/2/route/commands/0"},
+		{Tree: "items/2", Path: "items/2/commands/0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk: got: %v, want: %v", got, want)
+	}
+}
+
+func unmarshal(t *testing.T) *Coverage {
+	b, err := Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c *Coverage
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestRecord(t *testing.T) {
+	prev := []byte(`{"commands":["items/2/commands/0"],"branches":[]}`)
+	if err := Start(testGame(), prev); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	RecordCommand("commonEvents/1/commands/1")
+	RecordBranch("commonEvents/1/commands/1", 1)
+	RecordCommand("commonEvents/1/commands/2/route/commands/0")
+	// Commands that are not in the game are ignored.
+	RecordCommand("commonEvents/1/commands/3")
+	RecordBranch("commonEvents/2/commands/0", 0)
+
+	c := unmarshal(t)
+	wantCommands := []string{
+		"commonEvents/1/commands/1",
+		"commonEvents/1/commands/2/route/commands/0",
+		"items/2/commands/0",
+	}
+	if !reflect.DeepEqual(c.Commands, wantCommands) {
+		t.Errorf("commands: got: %v, want: %v", c.Commands, wantCommands)
+	}
+	wantBranches := []string{
+		"commonEvents/1/commands/1/branches/1",
+	}
+	if !reflect.DeepEqual(c.Branches, wantBranches) {
+		t.Errorf("branches: got: %v, want: %v", c.Branches, wantBranches)
+	}
+}
+
+func TestNotRecording(t *testing.T) {
+	if IsRecording() {
+		t.Fatal("IsRecording must be false before Start")
+	}
+	RecordCommand("commonEvents/1/commands/0")
+	b, err := Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != nil {
+		t.Errorf("Marshal: got: %s, want: nil", b)
+	}
+}
@@ -164,6 +164,18 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 		c.Args = a
 	case CommandNameReturn:
 	case CommandNameEraseEvent:
+	case CommandNameSpawnEvent:
+		a := &CommandArgsSpawnEvent{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameDestroyEvent:
+		a := &CommandArgsDestroyEvent{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNameWait:
 		a := &CommandArgsWait{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -176,6 +188,12 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return err
 		}
 		c.Args = a
+	case CommandNameShowEmotion:
+		a := &CommandArgsShowEmotion{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNameShowMessage:
 		a := &CommandArgsShowMessage{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -240,6 +258,54 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return err
 		}
 		c.Args = a
+	case CommandNameControlCamera:
+		a := &CommandArgsControlCamera{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameAddFollower:
+		a := &CommandArgsAddFollower{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameRemoveFollower:
+		a := &CommandArgsRemoveFollower{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameGatherFollowers:
+		a := &CommandArgsGatherFollowers{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameSetTile:
+		a := &CommandArgsSetTile{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameSetAmbientLight:
+		a := &CommandArgsSetAmbientLight{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameAddLight:
+		a := &CommandArgsAddLight{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNameRemoveLight:
+		a := &CommandArgsRemoveLight{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNameTintScreen:
 		a := &CommandArgsTintScreen{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -374,6 +440,18 @@ func (c *Command) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return err
 		}
 		c.Args = a
+	case CommandNameJumpCharacter:
+		a := &CommandArgsJumpCharacter{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
+	case CommandNamePlayCharacterAnimation:
+		a := &CommandArgsPlayCharacterAnimation{}
+		if err := msgpack.Unmarshal(argsBin, a); err != nil {
+			return err
+		}
+		c.Args = a
 	case CommandNameSetCharacterProperty:
 		a := &CommandArgsSetCharacterProperty{}
 		if err := msgpack.Unmarshal(argsBin, a); err != nil {
@@ -521,8 +599,11 @@ const (
 	CommandNameCallCommonEvent   CommandName = "call_common_event"
 	CommandNameReturn            CommandName = "return"
 	CommandNameEraseEvent        CommandName = "erase_event"
+	CommandNameSpawnEvent        CommandName = "spawn_event"
+	CommandNameDestroyEvent      CommandName = "destroy_event"
 	CommandNameWait              CommandName = "wait"
 	CommandNameShowBalloon       CommandName = "show_balloon"
+	CommandNameShowEmotion       CommandName = "show_emotion"
 	CommandNameShowMessage       CommandName = "show_message"
 	CommandNameShowHint          CommandName = "show_hint"
 	CommandNameShowChoices       CommandName = "show_choices"
@@ -535,6 +616,14 @@ const (
 	CommandNameSetRoute          CommandName = "set_route"
 	CommandNameTintScreen        CommandName = "tint_screen"
 	CommandNameShake             CommandName = "shake"
+	CommandNameControlCamera     CommandName = "control_camera"
+	CommandNameAddFollower       CommandName = "add_follower"
+	CommandNameRemoveFollower    CommandName = "remove_follower"
+	CommandNameGatherFollowers   CommandName = "gather_followers"
+	CommandNameSetTile           CommandName = "set_tile"
+	CommandNameSetAmbientLight   CommandName = "set_ambient_light"
+	CommandNameAddLight          CommandName = "add_light"
+	CommandNameRemoveLight       CommandName = "remove_light"
 	CommandNamePlaySE            CommandName = "play_se"
 	CommandNamePlayBGM           CommandName = "play_bgm"
 	CommandNameStopBGM           CommandName = "stop_bgm"
@@ -578,12 +667,14 @@ const (
 	CommandNameChangeForeground   CommandName = "change_foreground"
 
 	// Route commands
-	CommandNameMoveCharacter        CommandName = "move_character"
-	CommandNameTurnCharacter        CommandName = "turn_character"
-	CommandNameRotateCharacter      CommandName = "rotate_character"
-	CommandNameSetCharacterProperty CommandName = "set_character_property"
-	CommandNameSetCharacterImage    CommandName = "set_character_image"
-	CommandNameSetCharacterOpacity  CommandName = "set_character_opacity"
+	CommandNameMoveCharacter          CommandName = "move_character"
+	CommandNameTurnCharacter          CommandName = "turn_character"
+	CommandNameRotateCharacter        CommandName = "rotate_character"
+	CommandNameJumpCharacter          CommandName = "jump_character"
+	CommandNamePlayCharacterAnimation CommandName = "play_character_animation"
+	CommandNameSetCharacterProperty   CommandName = "set_character_property"
+	CommandNameSetCharacterImage      CommandName = "set_character_image"
+	CommandNameSetCharacterOpacity    CommandName = "set_character_opacity"
 
 	// Special commands
 	CommandNameSpecial                       CommandName = "special"
@@ -621,6 +712,30 @@ type CommandArgsCallEvent struct {
 	PageIndex int `msgpack:"pageIndex"`
 }
 
+// CommandArgsSpawnEvent is the arguments of the spawn_event command.
+//
+// RoomID is the room that has the source event. 0 means the current room.
+// If IDVariable is not 0, the new event ID is stored in the variable.
+//
+// A spawned event is placed in the current room. It is saved with the game, and lives until it is destroyed by
+// destroy_event or the player is transferred, even to the same room.
+type CommandArgsSpawnEvent struct {
+	RoomID     int       `msgpack:"roomId"`
+	EventID    int       `msgpack:"eventId"`
+	X          int       `msgpack:"x"`
+	Y          int       `msgpack:"y"`
+	ValueType  ValueType `msgpack:"valueType"`
+	IDVariable int       `msgpack:"idVariable"`
+}
+
+// CommandArgsDestroyEvent is the arguments of the destroy_event command.
+//
+// Only spawned events can be destroyed. EventID 0 means the event executing the command.
+type CommandArgsDestroyEvent struct {
+	EventID   int       `msgpack:"eventId"`
+	ValueType ValueType `msgpack:"valueType"`
+}
+
 type CommandArgsCallCommonEvent struct {
 	EventID int `msgpack:"eventId"`
 }
@@ -636,6 +751,15 @@ type CommandArgsShowBalloon struct {
 	MessageStyleID int         `msgpack:"messageStyleId"`
 }
 
+// CommandArgsShowEmotion is the arguments of the show_emotion command.
+//
+// EventID 0 means the event executing the command.
+type CommandArgsShowEmotion struct {
+	EventID int         `msgpack:"eventId"`
+	Emotion EmotionType `msgpack:"emotion"`
+	Wait    bool        `msgpack:"wait"`
+}
+
 type CommandArgsShowMessage struct {
 	EventID        int                 `msgpack:"eventId"`
 	ContentID      UUID                `msgpack:"content"`
@@ -866,6 +990,98 @@ type CommandArgsShake struct {
 	Direction ShakeDirection `msgpack:"direction"`
 }
 
+// CommandArgsControlCamera is the arguments of the control_camera command.
+//
+// For ControlCameraTypePan, X and Y are the tile position to put at the center of the screen.
+// For ControlCameraTypeOffset, X and Y are the offset in pixels.
+// Zoom is the scale in percentage for ControlCameraTypeZoom.
+type CommandArgsControlCamera struct {
+	Type      ControlCameraType `msgpack:"type"`
+	EventID   int               `msgpack:"eventId"`
+	X         int               `msgpack:"x"`
+	Y         int               `msgpack:"y"`
+	ValueType ValueType         `msgpack:"valueType"`
+	Zoom      int               `msgpack:"zoom"`
+	Time      int               `msgpack:"time"`
+	Easing    Easing            `msgpack:"easing"`
+	Wait      bool              `msgpack:"wait"`
+}
+
+type CommandArgsAddFollower struct {
+	Image     string    `msgpack:"image"`
+	ImageType ImageType `msgpack:"imageType"`
+}
+
+// CommandArgsRemoveFollower is the arguments of the remove_follower command.
+// An empty Image removes all the followers.
+type CommandArgsRemoveFollower struct {
+	Image string `msgpack:"image"`
+}
+
+// CommandArgsGatherFollowers is the arguments of the gather_followers command.
+// If Gather is true, the followers walk to the player and hide there. Otherwise, the followers trail the
+// player again.
+type CommandArgsGatherFollowers struct {
+	Gather bool `msgpack:"gather"`
+	Wait   bool `msgpack:"wait"`
+}
+
+// CommandArgsSetAmbientLight is the arguments of the set_ambient_light command.
+//
+// Red, Green and Blue are the color of the darkness overlay, and Darkness is its opacity. All the values are in
+// [0, 255].
+type CommandArgsSetAmbientLight struct {
+	Red      int  `msgpack:"red"`
+	Green    int  `msgpack:"green"`
+	Blue     int  `msgpack:"blue"`
+	Darkness int  `msgpack:"darkness"`
+	Time     int  `msgpack:"time"`
+	Wait     bool `msgpack:"wait"`
+}
+
+// CommandArgsAddLight is the arguments of the add_light command.
+//
+// A light with the same ID is replaced. If Target is LightTargetCharacter, the light follows the character of
+// EventID (0 means the event executing the command). Otherwise, the light is put at the tile (X, Y).
+// Radius is in pixels, and Flicker is the amplitude of the flicker in percent.
+type CommandArgsAddLight struct {
+	ID      int         `msgpack:"id"`
+	Target  LightTarget `msgpack:"target"`
+	EventID int         `msgpack:"eventId"`
+	X       int         `msgpack:"x"`
+	Y       int         `msgpack:"y"`
+	Radius  int         `msgpack:"radius"`
+	Red     int         `msgpack:"red"`
+	Green   int         `msgpack:"green"`
+	Blue    int         `msgpack:"blue"`
+	Flicker int         `msgpack:"flicker"`
+}
+
+type LightTarget string
+
+const (
+	LightTargetCharacter LightTarget = "character"
+	LightTargetTile      LightTarget = "tile"
+)
+
+// CommandArgsRemoveLight is the arguments of the remove_light command. ID 0 means all the lights.
+type CommandArgsRemoveLight struct {
+	ID int `msgpack:"id"`
+}
+
+// CommandArgsSetTile is the arguments of the set_tile command.
+//
+// RoomID 0 means the current room. Tile 0 means erasing the tile.
+type CommandArgsSetTile struct {
+	RoomID      int                `msgpack:"roomId"`
+	Layer       int                `msgpack:"layer"`
+	X           int                `msgpack:"x"`
+	Y           int                `msgpack:"y"`
+	ValueType   ValueType          `msgpack:"valueType"`
+	Tile        int                `msgpack:"tile"`
+	PassageType SetTilePassageType `msgpack:"passageType"`
+}
+
 type CommandArgsTintScreen struct {
 	Red   int  `msgpack:"red"`
 	Green int  `msgpack:"green"`
@@ -1121,9 +1337,34 @@ type CommandArgsRotateCharacter struct {
 	Angle int `msgpack:"angle"`
 }
 
+// CommandArgsJumpCharacter is the arguments of the jump_character command.
+//
+// X and Y are the relative position in tiles to land. Frames is the duration of the jump and Height is
+// the peak height of the arc in pixels. Zero Frames or Height means the default value.
+type CommandArgsJumpCharacter struct {
+	X      int `msgpack:"x"`
+	Y      int `msgpack:"y"`
+	Frames int `msgpack:"frames"`
+	Height int `msgpack:"height"`
+}
+
+// CommandArgsPlayCharacterAnimation is the arguments of the play_character_animation command.
+//
+// Name is the animation name in the image's SpriteMetadata. Empty Name stops the current animation.
+// If Wait is true, the route waits until the animation finishes. Wait is ignored for looping animations.
+type CommandArgsPlayCharacterAnimation struct {
+	Name string `msgpack:"name"`
+	Wait bool   `msgpack:"wait"`
+}
+
+// CommandArgsSetCharacterProperty is the arguments of the set_character_property command.
+//
+// Time and Wait are used only for the interpolated properties (scale and tint).
 type CommandArgsSetCharacterProperty struct {
 	Type  SetCharacterPropertyType `msgpack:"type"`
 	Value interface{}              `msgpack:"value"`
+	Time  int                      `msgpack:"time"`
+	Wait  bool                     `msgpack:"wait"`
 }
 
 type CommandArgsSetCharacterOpacity struct {
@@ -1153,8 +1394,32 @@ func (c *CommandArgsSetCharacterProperty) EncodeMsgpack(enc *msgpack.Encoder) er
 		e.EncodeBool(c.Value.(bool))
 	case SetCharacterPropertyTypeSpeed:
 		e.EncodeInt(int(c.Value.(Speed)))
+	case SetCharacterPropertyTypeScale:
+		e.EncodeInt(c.Value.(int))
+	case SetCharacterPropertyTypeFlipX:
+		e.EncodeBool(c.Value.(bool))
+	case SetCharacterPropertyTypeFlipY:
+		e.EncodeBool(c.Value.(bool))
+	case SetCharacterPropertyTypeTint:
+		e.BeginArray()
+		for _, v := range c.Value.([]int) {
+			e.EncodeInt(v)
+		}
+		e.EndArray()
+	case SetCharacterPropertyTypeBlendType:
+		e.EncodeString(string(c.Value.(ShowPictureBlendType)))
+	case SetCharacterPropertyTypeZOffset:
+		e.EncodeInt(c.Value.(int))
+	case SetCharacterPropertyTypeShadow:
+		e.EncodeBool(c.Value.(bool))
 	}
 
+	e.EncodeString("time")
+	e.EncodeInt(c.Time)
+
+	e.EncodeString("wait")
+	e.EncodeBool(c.Wait)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -1201,6 +1466,10 @@ func (c *CommandArgsSetCharacterProperty) DecodeMsgpack(dec *msgpack.Decoder) er
 			c.Type = SetCharacterPropertyType(d.DecodeString())
 		case "value":
 			d.DecodeAny(&value)
+		case "time":
+			c.Time = d.DecodeInt()
+		case "wait":
+			c.Wait = d.DecodeBool()
 		default:
 			if err := d.Error(); err != nil {
 				return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack failed: %v", err)
@@ -1229,6 +1498,40 @@ func (c *CommandArgsSetCharacterProperty) DecodeMsgpack(dec *msgpack.Decoder) er
 			return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack: speed must be an integer; got %v", value)
 		}
 		c.Value = Speed(v)
+	case SetCharacterPropertyTypeScale:
+		v, ok := InterfaceToInt(value)
+		if !ok {
+			return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack: scale must be an integer; got %v", value)
+		}
+		c.Value = v
+	case SetCharacterPropertyTypeFlipX:
+		c.Value = value.(bool)
+	case SetCharacterPropertyTypeFlipY:
+		c.Value = value.(bool)
+	case SetCharacterPropertyTypeTint:
+		vs, ok := value.([]interface{})
+		if !ok || len(vs) != 4 {
+			return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack: tint must be an array of 4 integers; got %v", value)
+		}
+		tint := make([]int, len(vs))
+		for i, v := range vs {
+			n, ok := InterfaceToInt(v)
+			if !ok {
+				return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack: tint must be an array of 4 integers; got %v", value)
+			}
+			tint[i] = n
+		}
+		c.Value = tint
+	case SetCharacterPropertyTypeBlendType:
+		c.Value = ShowPictureBlendType(value.(string))
+	case SetCharacterPropertyTypeZOffset:
+		v, ok := InterfaceToInt(value)
+		if !ok {
+			return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack: z offset must be an integer; got %v", value)
+		}
+		c.Value = v
+	case SetCharacterPropertyTypeShadow:
+		c.Value = value.(bool)
 	default:
 		return fmt.Errorf("data: CommandArgsSetCharacterProperty.DecodeMsgpack: invalid type: %s", c.Type)
 	}
@@ -1540,6 +1843,14 @@ func (c *CommandArgsChangePictureImage) DecodeMsgpack(dec *msgpack.Decoder) erro
 type CommandArgsChangeBackground struct {
 	Image          interface{}
 	ImageValueType FileValueType
+
+	// LayerID is the ID of the layer to change. 0 means the room's main background.
+	// An empty image removes the layer.
+	LayerID int
+
+	// Layer is the properties of the layer except for the image. nil means the default properties.
+	// Layer is used only when LayerID is not 0.
+	Layer *MapLayer
 }
 
 func (c *CommandArgsChangeBackground) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -1557,6 +1868,12 @@ func (c *CommandArgsChangeBackground) EncodeMsgpack(enc *msgpack.Encoder) error
 	e.EncodeString("image")
 	e.EncodeAny(c.Image)
 
+	e.EncodeString("layerId")
+	e.EncodeInt(c.LayerID)
+
+	e.EncodeString("layer")
+	e.EncodeInterface(c.Layer)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -1576,6 +1893,13 @@ func (c *CommandArgsChangeBackground) DecodeMsgpack(dec *msgpack.Decoder) error
 			d.DecodeAny(&imageValue)
 		case "imageValueType":
 			c.ImageValueType = FileValueType(d.DecodeString())
+		case "layerId":
+			c.LayerID = d.DecodeInt()
+		case "layer":
+			if !d.SkipCodeIfNil() {
+				c.Layer = &MapLayer{}
+				d.DecodeInterface(c.Layer)
+			}
 		default:
 			if err := d.Error(); err != nil {
 				return fmt.Errorf("data: CommandArgsChangeBackground.DecodeMsgpack failed: %v", err)
@@ -1613,6 +1937,14 @@ func (c *CommandArgsChangeBackground) DecodeMsgpack(dec *msgpack.Decoder) error
 type CommandArgsChangeForeground struct {
 	Image          interface{}
 	ImageValueType FileValueType
+
+	// LayerID is the ID of the layer to change. 0 means the room's main foreground.
+	// An empty image removes the layer.
+	LayerID int
+
+	// Layer is the properties of the layer except for the image. nil means the default properties.
+	// Layer is used only when LayerID is not 0.
+	Layer *MapLayer
 }
 
 func (c *CommandArgsChangeForeground) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -1630,6 +1962,12 @@ func (c *CommandArgsChangeForeground) EncodeMsgpack(enc *msgpack.Encoder) error
 	e.EncodeString("image")
 	e.EncodeAny(c.Image)
 
+	e.EncodeString("layerId")
+	e.EncodeInt(c.LayerID)
+
+	e.EncodeString("layer")
+	e.EncodeInterface(c.Layer)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -1649,6 +1987,13 @@ func (c *CommandArgsChangeForeground) DecodeMsgpack(dec *msgpack.Decoder) error
 			d.DecodeAny(&imageValue)
 		case "imageValueType":
 			c.ImageValueType = FileValueType(d.DecodeString())
+		case "layerId":
+			c.LayerID = d.DecodeInt()
+		case "layer":
+			if !d.SkipCodeIfNil() {
+				c.Layer = &MapLayer{}
+				d.DecodeInterface(c.Layer)
+			}
 		default:
 			if err := d.Error(); err != nil {
 				return fmt.Errorf("data: CommandArgsChangeForeground.DecodeMsgpack failed: %v", err)
@@ -1810,6 +2155,22 @@ const (
 	SetCharacterPropertyTypeThrough    SetCharacterPropertyType = "through"
 	SetCharacterPropertyTypeWalking    SetCharacterPropertyType = "walking"
 	SetCharacterPropertyTypeSpeed      SetCharacterPropertyType = "speed"
+
+	// SetCharacterPropertyTypeScale's value is the scale in percent.
+	SetCharacterPropertyTypeScale SetCharacterPropertyType = "scale"
+
+	SetCharacterPropertyTypeFlipX SetCharacterPropertyType = "flip_x"
+	SetCharacterPropertyTypeFlipY SetCharacterPropertyType = "flip_y"
+
+	// SetCharacterPropertyTypeTint's value is red, green, blue and gray in [-255, 255] like tint_screen.
+	SetCharacterPropertyTypeTint SetCharacterPropertyType = "tint"
+
+	SetCharacterPropertyTypeBlendType SetCharacterPropertyType = "blend_type"
+
+	// SetCharacterPropertyTypeZOffset's value is the offset in pixels added to the foot Y position for the draw order.
+	SetCharacterPropertyTypeZOffset SetCharacterPropertyType = "z_offset"
+
+	SetCharacterPropertyTypeShadow SetCharacterPropertyType = "shadow"
 )
 
 type ControlHintType string
@@ -1836,6 +2197,23 @@ const (
 	BalloonTypeShout  BalloonType = "shout"
 )
 
+// EmotionType is the kind of the emotion icon.
+// The order of the constants is the order of the rows in the emotion icon sheet.
+type EmotionType string
+
+const (
+	EmotionTypeExclamation EmotionType = "exclamation"
+	EmotionTypeQuestion    EmotionType = "question"
+	EmotionTypeMusic       EmotionType = "music"
+	EmotionTypeHeart       EmotionType = "heart"
+	EmotionTypeAnger       EmotionType = "anger"
+	EmotionTypeSweat       EmotionType = "sweat"
+	EmotionTypeFrustration EmotionType = "frustration"
+	EmotionTypeSilence     EmotionType = "silence"
+	EmotionTypeLightBulb   EmotionType = "light_bulb"
+	EmotionTypeSleep       EmotionType = "sleep"
+)
+
 type SystemVariableType string
 
 const (
@@ -1849,6 +2227,9 @@ const (
 	SystemVariableTriggeredPictureID    SystemVariableType = "triggered_picture_id"
 	SystemVariablePressedPictureID      SystemVariableType = "pressed_picture_id"
 	SystemVariableReleasedPictureID     SystemVariableType = "released_picture_id"
+	SystemVariablePlayerRegionID        SystemVariableType = "player_region_id"
+	SystemVariablePlayerTerrainTag      SystemVariableType = "player_terrain_tag"
+	SystemVariableCollidedEventID       SystemVariableType = "collided_event_id"
 	SystemVariableSponsorTier           SystemVariableType = "sponsor_tier"
 )
 
@@ -1884,6 +2265,36 @@ const (
 	ShakeDirectionVertical   ShakeDirection = "vertical"
 )
 
+type ControlCameraType string
+
+const (
+	ControlCameraTypeFollow ControlCameraType = "follow"
+	ControlCameraTypePan    ControlCameraType = "pan"
+	ControlCameraTypeOffset ControlCameraType = "offset"
+	ControlCameraTypeZoom   ControlCameraType = "zoom"
+	ControlCameraTypeReset  ControlCameraType = "reset"
+)
+
+type SetTilePassageType string
+
+const (
+	// SetTilePassageTypeNone keeps the current passage type.
+	SetTilePassageTypeNone SetTilePassageType = ""
+	// SetTilePassageTypeTile makes the passage type follow the tiles again.
+	SetTilePassageTypeTile     SetTilePassageType = "tile"
+	SetTilePassageTypePassable SetTilePassageType = "passable"
+	SetTilePassageTypeBlock    SetTilePassageType = "block"
+)
+
+type Easing string
+
+const (
+	EasingLinear    Easing = "linear"
+	EasingEaseIn    Easing = "ease_in"
+	EasingEaseOut   Easing = "ease_out"
+	EasingEaseInOut Easing = "ease_in_out"
+)
+
 type OpenLinkType string
 
 const (
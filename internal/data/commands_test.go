@@ -110,6 +110,26 @@ func TestSetCharacterProperty(t *testing.T) {
 				Value: Speed1,
 			},
 		},
+		{
+			args: &CommandArgsSetCharacterProperty{
+				Type:  SetCharacterPropertyTypeScale,
+				Value: 150,
+				Time:  10,
+			},
+		},
+		{
+			args: &CommandArgsSetCharacterProperty{
+				Type:  SetCharacterPropertyTypeTint,
+				Value: []int{255, 0, -128, 0},
+				Wait:  true,
+			},
+		},
+		{
+			args: &CommandArgsSetCharacterProperty{
+				Type:  SetCharacterPropertyTypeBlendType,
+				Value: ShowPictureBlendTypeAdd,
+			},
+		},
 	}
 	for _, test := range tests {
 		c.Args = test.args
@@ -132,5 +152,48 @@ func TestSetCharacterProperty(t *testing.T) {
 		if !reflect.DeepEqual(args2.Value, args.Value) {
 			t.Errorf("got: %v, want: %v", args2.Value, args.Value)
 		}
+		if args2.Time != args.Time || args2.Wait != args.Wait {
+			t.Errorf("got: (%d, %t), want: (%d, %t)", args2.Time, args2.Wait, args.Time, args.Wait)
+		}
+	}
+}
+
+func TestChangeBackgroundLayer(t *testing.T) {
+	layer := NewMapLayer()
+	layer.ParallaxX = 50
+	layer.ScrollX = -30
+	layer.Opacity = 128
+	layer.WrapX = true
+	c := &Command{
+		Name: CommandNameChangeBackground,
+		Args: &CommandArgsChangeBackground{
+			Image:   "clouds",
+			LayerID: 2,
+			Layer:   layer,
+		},
+	}
+	b, err := msgpack.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c2 *Command
+	if err := msgpack.Unmarshal(b, &c2); err != nil {
+		t.Fatal(err)
+	}
+	args2 := c2.Args.(*CommandArgsChangeBackground)
+	if args2.Image != "clouds" || args2.LayerID != 2 {
+		t.Errorf("got: (%v, %d), want: (%v, %d)", args2.Image, args2.LayerID, "clouds", 2)
+	}
+	if !reflect.DeepEqual(args2.Layer, layer) {
+		t.Errorf("got: %v, want: %v", args2.Layer, layer)
+	}
+
+	// A layer without properties gets the default ones.
+	var layer2 *MapLayer
+	if err := msgpack.Unmarshal([]byte{0x80}, &layer2); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(layer2, NewMapLayer()) {
+		t.Errorf("got: %v, want: %v", layer2, NewMapLayer())
 	}
 }
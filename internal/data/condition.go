@@ -29,6 +29,7 @@ const (
 	ConditionTypeSelfSwitch ConditionType = "self_switch"
 	ConditionTypeVariable   ConditionType = "variable"
 	ConditionTypeItem       ConditionType = "item"
+	ConditionTypeRegion     ConditionType = "region"
 	ConditionTypeSpecial    ConditionType = "special" // This type is intended for inner only.
 )
 
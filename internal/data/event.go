@@ -105,6 +105,17 @@ type Page struct {
 	Opacity    int                  `msgpack:"opacity"`
 	Route      *CommandArgsSetRoute `msgpack:"route"`
 	Commands   []*Command           `msgpack:"commands"`
+
+	// ZOffset is added to the foot Y position of the event to determine the draw order among the characters
+	// at the same priority.
+	ZOffset int `msgpack:"zOffset"`
+
+	// If Shadow is true, a blob shadow is drawn under the event.
+	Shadow bool `msgpack:"shadow"`
+
+	// CollisionCommonEventID is the common event to run when the event tries to move into another event.
+	// The other event's ID is available as SystemVariableCollidedEventID. 0 means nothing happens.
+	CollisionCommonEventID int `msgpack:"collisionCommonEventId"`
 }
 
 type Dir int
@@ -115,8 +126,101 @@ const (
 	DirRight Dir = 1
 	DirDown  Dir = 2
 	DirLeft  Dir = 3
+
+	// Diagonal directions are available only when System.EightDirections is true.
+	DirUpRight   Dir = 4
+	DirDownRight Dir = 5
+	DirDownLeft  Dir = 6
+	DirUpLeft    Dir = 7
 )
 
+// clockwiseDirs is the directions in the clockwise order from DirUp.
+var clockwiseDirs = []Dir{
+	DirUp,
+	DirUpRight,
+	DirRight,
+	DirDownRight,
+	DirDown,
+	DirDownLeft,
+	DirLeft,
+	DirUpLeft,
+}
+
+// IsDiagonal reports whether the direction is one of the diagonal directions.
+func (d Dir) IsDiagonal() bool {
+	return DirUpRight <= d && d <= DirUpLeft
+}
+
+// Delta returns the difference of the position when moving one step in the direction.
+func (d Dir) Delta() (int, int) {
+	switch d {
+	case DirUp:
+		return 0, -1
+	case DirRight:
+		return 1, 0
+	case DirDown:
+		return 0, 1
+	case DirLeft:
+		return -1, 0
+	case DirUpRight:
+		return 1, -1
+	case DirDownRight:
+		return 1, 1
+	case DirDownLeft:
+		return -1, 1
+	case DirUpLeft:
+		return -1, -1
+	default:
+		panic(fmt.Sprintf("data: invalid dir: %d at Delta", d))
+	}
+}
+
+// DirFromDelta returns the direction to move by (dx, dy).
+// Only the signs of dx and dy are used. DirFromDelta returns DirNone when both are 0.
+func DirFromDelta(dx, dy int) Dir {
+	switch {
+	case dx == 0 && dy < 0:
+		return DirUp
+	case dx > 0 && dy == 0:
+		return DirRight
+	case dx == 0 && dy > 0:
+		return DirDown
+	case dx < 0 && dy == 0:
+		return DirLeft
+	case dx > 0 && dy < 0:
+		return DirUpRight
+	case dx > 0 && dy > 0:
+		return DirDownRight
+	case dx < 0 && dy > 0:
+		return DirDownLeft
+	case dx < 0 && dy < 0:
+		return DirUpLeft
+	default:
+		return DirNone
+	}
+}
+
+// Rotate returns the direction rotated clockwise by angle in degrees.
+// angle must be a multiple of 45.
+func (d Dir) Rotate(angle int) Dir {
+	if angle%45 != 0 {
+		panic(fmt.Sprintf("data: invalid angle: %d at Rotate", angle))
+	}
+	for i, dir := range clockwiseDirs {
+		if dir != d {
+			continue
+		}
+		n := len(clockwiseDirs)
+		return clockwiseDirs[((i+angle/45)%n+n)%n]
+	}
+	panic(fmt.Sprintf("data: invalid dir: %d at Rotate", d))
+}
+
+// Opposite returns the opposite direction.
+func (d Dir) Opposite() Dir {
+	return d.Rotate(180)
+}
+
 type Priority string
 
 const (
@@ -140,6 +244,9 @@ const (
 	TriggerParallel Trigger = "parallel"
 	TriggerDirect   Trigger = "direct"
 	TriggerNever    Trigger = "never"
+
+	// TriggerEventTouch fires when the event itself tries to move into the player.
+	TriggerEventTouch Trigger = "event_touch"
 )
 
 type Speed int
@@ -75,6 +75,28 @@ type MessageStyle struct {
 type AssetMetadata struct {
 	PassageTypes []PassageType `msgpack:"passageTypes"`
 	IsAutoTile   bool          `msgpack:"isAutoTile"`
+
+	// MoveCosts is the costs to enter the tiles for path finding. 0 means the default cost 1.
+	MoveCosts []int `msgpack:"moveCosts"`
+
+	// EnterBlocks is the sides from which the tiles cannot be entered.
+	// For example, DirUp means a character cannot enter the tile from the upper tile.
+	EnterBlocks []DirMask `msgpack:"enterBlocks"`
+
+	TileFlags []TileFlag `msgpack:"tileFlags"`
+
+	// TerrainTags is the designer-defined terrain tags of the tiles. 0 means no tag.
+	TerrainTags []int `msgpack:"terrainTags"`
+
+	// AnimationFrames is the numbers of the animation frames of the tiles. 0 or 1 means the tile is static.
+	// The frames are laid out horizontally next to the tile. For an auto tile, index 0 is used.
+	AnimationFrames []int `msgpack:"animationFrames"`
+
+	// AnimationIntervals is the frame intervals in ticks of the animated tiles. 0 means the default interval.
+	AnimationIntervals []int `msgpack:"animationIntervals"`
+
+	// Sprite is the layout and the animations of a character image.
+	Sprite *SpriteMetadata `msgpack:"sprite"`
 }
 
 type FinishTriggerType string
@@ -39,6 +39,8 @@ var (
 	// TODO: This data should be included in project.json
 	creditsPath = flag.String("credits-json-path", filepath.Join(".", "credits.json"), "credits path")
 
+	coveragePath = flag.String("coverage-json-path", "", "command coverage path (coverage is recorded only when specified)")
+
 	forceEagerDecoding = flag.Bool("force-eager-decoding", false, "whether to force decoding maps and events eagerly")
 )
 
@@ -69,6 +71,10 @@ func CreditsPath() string {
 	return *creditsPath
 }
 
+func CoveragePath() string {
+	return *coveragePath
+}
+
 func loadAssets(projectionLocation string) ([]byte, error) {
 	assets := map[string][]byte{}
 	for _, dir := range assetDirs {
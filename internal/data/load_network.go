@@ -60,6 +60,10 @@ func CreditsPath() string {
 	return filepath.Join(os.TempDir(), "credits.json")
 }
 
+func CoveragePath() string {
+	return ""
+}
+
 type manifestBody struct {
 	Manifest map[string][]string `json:"manifest" msgpack:"manifest"`
 }
@@ -16,6 +16,8 @@ package data
 
 import (
 	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
 )
 
 type RoomLayoutMode string
@@ -96,6 +98,46 @@ type Room struct {
 	AutoBGM              bool           `msgpack:"autoBGM"`
 	BGM                  BGM            `msgpack:"bgm"`
 	LayoutMode           RoomLayoutMode `msgpack:"layoutMode"`
+
+	// Width and Height are the size of the room in tiles.
+	// 0 means the default size (consts.TileXNum or consts.TileYNum).
+	Width  int `msgpack:"width"`
+	Height int `msgpack:"height"`
+
+	// Ambient is the darkness overlay of the room. nil means no darkness.
+	Ambient *AmbientLight `msgpack:"ambient"`
+
+	// Regions is the region IDs painted on the room, indexed by TileIndex. 0 means no region.
+	Regions []int `msgpack:"regions"`
+
+	// Layers is the image layers in addition to Background and Foreground, in the order to draw.
+	Layers []*MapLayer `msgpack:"layers"`
+}
+
+// AmbientLight is the color and the opacity of a darkness overlay. The values are in [0, 255].
+type AmbientLight struct {
+	Red      int `msgpack:"red"`
+	Green    int `msgpack:"green"`
+	Blue     int `msgpack:"blue"`
+	Darkness int `msgpack:"darkness"`
+}
+
+// Size returns the size of the room in tiles.
+func (r *Room) Size() (int, int) {
+	w, h := r.Width, r.Height
+	if w <= 0 {
+		w = consts.TileXNum
+	}
+	if h <= 0 {
+		h = consts.TileYNum
+	}
+	return w, h
+}
+
+// TileIndex returns the index of the tile at (x, y) in Tiles and PassageTypeOverrides.
+func (r *Room) TileIndex(x, y int) int {
+	w, _ := r.Size()
+	return y*w + x
 }
 
 type MapSprite struct {
@@ -0,0 +1,141 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+)
+
+// MapLayer is an image layer drawn behind or in front of the tiles of a room.
+type MapLayer struct {
+	ID         int
+	Image      string
+	Foreground bool
+
+	// ParallaxX and ParallaxY are how much the layer follows the map offset in percent.
+	// 100 means the layer moves with the map, and 0 means the layer is fixed on the screen.
+	ParallaxX int
+	ParallaxY int
+
+	// ScrollX and ScrollY are the auto-scroll speeds in pixels per second.
+	ScrollX int
+	ScrollY int
+
+	// Opacity is in [0, 255].
+	Opacity   int
+	BlendType ShowPictureBlendType
+
+	// WrapX and WrapY are whether the image is repeated in the direction.
+	WrapX bool
+	WrapY bool
+}
+
+// NewMapLayer returns a map layer with the default properties.
+func NewMapLayer() *MapLayer {
+	return &MapLayer{
+		ParallaxX: 100,
+		ParallaxY: 100,
+		Opacity:   255,
+		BlendType: ShowPictureBlendTypeNormal,
+	}
+}
+
+func (m *MapLayer) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("id")
+	e.EncodeInt(m.ID)
+
+	e.EncodeString("image")
+	e.EncodeString(m.Image)
+
+	e.EncodeString("foreground")
+	e.EncodeBool(m.Foreground)
+
+	e.EncodeString("parallaxX")
+	e.EncodeInt(m.ParallaxX)
+
+	e.EncodeString("parallaxY")
+	e.EncodeInt(m.ParallaxY)
+
+	e.EncodeString("scrollX")
+	e.EncodeInt(m.ScrollX)
+
+	e.EncodeString("scrollY")
+	e.EncodeInt(m.ScrollY)
+
+	e.EncodeString("opacity")
+	e.EncodeInt(m.Opacity)
+
+	e.EncodeString("blendType")
+	e.EncodeString(string(m.BlendType))
+
+	e.EncodeString("wrapX")
+	e.EncodeBool(m.WrapX)
+
+	e.EncodeString("wrapY")
+	e.EncodeBool(m.WrapY)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (m *MapLayer) DecodeMsgpack(dec *msgpack.Decoder) error {
+	// Default values
+	*m = *NewMapLayer()
+
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "id":
+			m.ID = d.DecodeInt()
+		case "image":
+			m.Image = d.DecodeString()
+		case "foreground":
+			m.Foreground = d.DecodeBool()
+		case "parallaxX":
+			m.ParallaxX = d.DecodeInt()
+		case "parallaxY":
+			m.ParallaxY = d.DecodeInt()
+		case "scrollX":
+			m.ScrollX = d.DecodeInt()
+		case "scrollY":
+			m.ScrollY = d.DecodeInt()
+		case "opacity":
+			m.Opacity = d.DecodeInt()
+		case "blendType":
+			m.BlendType = ShowPictureBlendType(d.DecodeString())
+		case "wrapX":
+			m.WrapX = d.DecodeBool()
+		case "wrapY":
+			m.WrapY = d.DecodeBool()
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("data: MapLayer.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("data: MapLayer.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+// defaultSpriteAnimationFrameDuration is the duration in ticks of a frame whose Duration is 0.
+const defaultSpriteAnimationFrameDuration = 8
+
+// SpriteMetadata is the layout and the animations of a character image.
+//
+// SpriteMetadata is written in the image's metadata JSON file (e.g. images/characters/foo_metadata.json).
+// Without SpriteMetadata, the layout is inferred from the image name and the image size.
+type SpriteMetadata struct {
+	FrameWidth  int `msgpack:"frameWidth"`
+	FrameHeight int `msgpack:"frameHeight"`
+
+	// Dirs is the rows for the directions, indexed by the row index for 8 directions
+	// (up, right, down, left, up-right, down-right, down-left and up-left).
+	// The length is the number of directions of the image.
+	Dirs []*SpriteDir `msgpack:"dirs"`
+
+	// WalkFrames is the number of the columns from the left used for the walking cycle.
+	// The other columns are for the animations. 0 means all the columns are used for walking.
+	WalkFrames int `msgpack:"walkFrames"`
+
+	Animations []*SpriteAnimation `msgpack:"animations"`
+
+	// Idle is the name of the animation to play while the character is not moving.
+	// Empty means the character shows the base frame.
+	Idle string `msgpack:"idle"`
+}
+
+// SpriteDir is the row for a direction.
+type SpriteDir struct {
+	Row int `msgpack:"row"`
+
+	// If Mirror is true, the row is flipped horizontally.
+	Mirror bool `msgpack:"mirror"`
+}
+
+type SpriteAnimation struct {
+	Name   string                  `msgpack:"name"`
+	Frames []*SpriteAnimationFrame `msgpack:"frames"`
+	Loop   bool                    `msgpack:"loop"`
+}
+
+type SpriteAnimationFrame struct {
+	// Frame is the column in the image.
+	Frame int `msgpack:"frame"`
+
+	// Duration is the duration of the frame in ticks. 0 means the default duration.
+	Duration int `msgpack:"duration"`
+}
+
+// Animation returns the animation of the given name, or nil if not found.
+func (s *SpriteMetadata) Animation(name string) *SpriteAnimation {
+	for _, a := range s.Animations {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (f *SpriteAnimationFrame) duration() int {
+	if f.Duration <= 0 {
+		return defaultSpriteAnimationFrameDuration
+	}
+	return f.Duration
+}
+
+// Length returns the total duration of the animation in ticks.
+func (a *SpriteAnimation) Length() int {
+	l := 0
+	for _, f := range a.Frames {
+		l += f.duration()
+	}
+	return l
+}
+
+// FrameAt returns the column to show count ticks after the animation starts.
+// A non-looping animation stays at the last frame after it finishes.
+func (a *SpriteAnimation) FrameAt(count int) int {
+	if len(a.Frames) == 0 {
+		return 0
+	}
+	if a.Loop {
+		count %= a.Length()
+	}
+	for _, f := range a.Frames {
+		if count < f.duration() {
+			return f.Frame
+		}
+		count -= f.duration()
+	}
+	return a.Frames[len(a.Frames)-1].Frame
+}
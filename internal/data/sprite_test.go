@@ -0,0 +1,52 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+func TestSpriteAnimationFrameAt(t *testing.T) {
+	frames := []*SpriteAnimationFrame{
+		{Frame: 2, Duration: 3},
+		{Frame: 5, Duration: 1},
+		{Frame: 4},
+	}
+	cases := []struct {
+		Loop  bool
+		Count int
+		Out   int
+	}{
+		{false, 0, 2},
+		{false, 2, 2},
+		{false, 3, 5},
+		{false, 4, 4},
+		{false, 11, 4},
+		{false, 100, 4},
+		{true, 12, 2},
+		{true, 15, 5},
+	}
+	for _, c := range cases {
+		a := &SpriteAnimation{
+			Frames: frames,
+			Loop:   c.Loop,
+		}
+		if got := a.FrameAt(c.Count); got != c.Out {
+			t.Errorf("FrameAt(%d) (loop: %t): got: %d, want: %d", c.Count, c.Loop, got, c.Out)
+		}
+	}
+}
@@ -30,6 +30,20 @@ type System struct {
 	Switches           []*VariableData     `msgpack:"switches"`
 	Variables          []*VariableData     `msgpack:"variables"`
 	Vibration          bool                `msgpack:"vibration"`
+
+	// DamageFloorCommonEventID is the common event to run when the player steps on a damage floor tile.
+	// 0 means nothing happens.
+	DamageFloorCommonEventID int `msgpack:"damageFloorCommonEventId"`
+
+	// Regions is the settings of the region IDs painted on rooms.
+	Regions []*RegionData `msgpack:"regions"`
+
+	// If EightDirections is true, characters can move diagonally by tap-to-move and move_character with a target.
+	EightDirections bool `msgpack:"eightDirections"`
+
+	// If FixedRandomSeed is true, every new game starts with RandomSeed.
+	FixedRandomSeed bool  `msgpack:"fixedRandomSeed"`
+	RandomSeed      int64 `msgpack:"randomSeed"`
 }
 
 type InitialPlayerState struct {
@@ -41,6 +55,17 @@ type InitialPlayerState struct {
 	Y         int       `msgpack:"y"`
 }
 
+// RegionData is the settings of a region ID.
+type RegionData struct {
+	ID   int    `msgpack:"id"`
+	Name string `msgpack:"name"`
+
+	// EnterCommonEventID and LeaveCommonEventID are the common events to run when the player enters or leaves
+	// the region. 0 means nothing happens.
+	EnterCommonEventID int `msgpack:"enterCommonEventId"`
+	LeaveCommonEventID int `msgpack:"leaveCommonEventId"`
+}
+
 type VariableData struct {
 	ID       int             `msgpack:"id"`
 	Name     string          `msgpack:"name"`
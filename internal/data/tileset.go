@@ -22,6 +22,34 @@ const (
 	PassageTypeBlock
 )
 
+// DirMask is a set of the four directions. The bit (1 << dir) represents the direction dir.
+type DirMask int
+
+func (m DirMask) Has(dir Dir) bool {
+	return m&(1<<uint(dir)) != 0
+}
+
+// TileFlag is a set of the special behaviors of a tile.
+type TileFlag int
+
+const (
+	// TileFlagCounter makes the player interact with an event across the tile.
+	TileFlagCounter TileFlag = 1 << iota
+
+	// TileFlagLadder makes characters on the tile face up when they start a step sideways.
+	TileFlagLadder
+
+	// TileFlagBush makes the lower part of characters on the tile translucent.
+	TileFlagBush
+
+	// TileFlagDamage runs System.DamageFloorCommonEventID when the player steps on the tile.
+	TileFlagDamage
+)
+
+func (f TileFlag) Has(flag TileFlag) bool {
+	return f&flag != 0
+}
+
 type TileSet struct {
 	ID   int    `msgpack:"id"`
 	Name string `msgpack:"name"`
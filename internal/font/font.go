@@ -51,6 +51,31 @@ func MeasureSize(text string) (int, int) {
 	return w.Ceil(), h.Ceil()
 }
 
+// Advance returns the advance width of the single-line str at scale in pixels.
+func Advance(str string, scale int) int {
+	_, a := boundString(face(scale, lang.Get()), str)
+	return a.Ceil()
+}
+
+// Width returns the width of the bounds of the single-line str at scale in pixels.
+func Width(str string, scale int) int {
+	b, _ := boundString(face(scale, lang.Get()), str)
+	return (b.Max.X - b.Min.X).Ceil()
+}
+
+// DrawTextAt draws the single-line str whose upper-left is at (x, y) without alignment.
+// This is useful to draw a line part by part.
+func DrawTextAt(screen *ebiten.Image, str string, x, y int, scale int, color color.Color) {
+	f := face(scale, lang.Get())
+	m := f.Metrics()
+	y += (RenderingLineHeight*scale - m.Height.Round()) / 2
+
+	b, _, _ := f.GlyphBounds('.')
+	x += (-b.Min.X).Floor()
+
+	text.Draw(screen, str, f, x, y+mplusDotY*scale, color)
+}
+
 func DrawText(screen *ebiten.Image, str string, ox, oy int, scale int, textAlign data.TextAlign, color color.Color, displayTextRuneCount int) {
 	DrawTextLang(screen, str, ox, oy, scale, textAlign, color, displayTextRuneCount, lang.Get())
 }
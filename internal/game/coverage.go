@@ -0,0 +1,92 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package game
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/coverage"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+func startCoverageIfNeeded(game *data.Game) error {
+	path := data.CoveragePath()
+	if path == "" {
+		return nil
+	}
+	// Continue the coverage of the previous session, as the save data is continued.
+	prev, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := coverage.Start(game, prev); err != nil {
+		return err
+	}
+	log.Printf("Start Recording Coverage: %s", path)
+	return nil
+}
+
+// coverageSaveInterval is the interval in frames to save the coverage.
+// The coverage is saved periodically so that it survives a session that ends without saving the progress.
+const coverageSaveInterval = 60
+
+var (
+	coverageSaveCount int
+
+	// coverageSaving is filled while the coverage is being written.
+	coverageSaving = make(chan struct{}, 1)
+)
+
+func saveCoverageIfNeeded() {
+	if !coverage.IsRecording() {
+		return
+	}
+	coverageSaveCount++
+	if coverageSaveCount < coverageSaveInterval {
+		return
+	}
+	coverageSaveCount = 0
+	saveCoverage()
+}
+
+// saveCoverage writes the coverage asynchronously.
+// saveCoverage must be called from the game loop since the coverage is updated there.
+// Errors are only logged since recording the coverage is a debugging feature.
+func saveCoverage() {
+	b, err := coverage.Marshal()
+	if err != nil {
+		log.Printf("coverage: marshaling failed: %v", err)
+		return
+	}
+	if b == nil {
+		return
+	}
+	select {
+	case coverageSaving <- struct{}{}:
+	default:
+		// The previous writing is not finished yet. The coverage will be written next time.
+		return
+	}
+	go func() {
+		defer func() {
+			<-coverageSaving
+		}()
+		if err := ioutil.WriteFile(data.CoveragePath(), b, 0666); err != nil {
+			log.Printf("coverage: writing failed: %v", err)
+		}
+	}()
+}
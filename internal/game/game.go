@@ -127,6 +127,11 @@ func (g *Game) Update(screen *ebiten.Image) error {
 	return nil
 }
 
+// Close finishes the debugging features like recording inputs. Close must be called after the game loop ends.
+func (g *Game) Close() error {
+	return stopRecordingIfNeeded()
+}
+
 func (g *Game) update() error {
 	if g.loadProgressCh != nil {
 		select {
@@ -142,6 +147,12 @@ func (g *Game) update() error {
 			g.loadProgressCh = nil
 			da := d.LoadedData
 			assets.Set(da.Assets, da.AssetsMetadata)
+			if err := startRecordingOrReplayingIfNeeded(); err != nil {
+				return err
+			}
+			if err := startCoverageIfNeeded(da.Game); err != nil {
+				return err
+			}
 			g.sceneManager = scene.NewManager(g.width, g.height, g.requester, da.Game, da.Progress, da.Permanent, da.Purchases, sceneimpl.FadingCount)
 			g.sceneManager.SetLanguage(da.Language)
 			s, err := sceneimpl.NewInitialScene(g.sceneManager)
@@ -164,6 +175,7 @@ func (g *Game) update() error {
 		return err
 	}
 	takeCPUProfileIfAvailable()
+	saveCoverageIfNeeded()
 
 	if err := g.sceneManager.Update(); err != nil {
 		return err
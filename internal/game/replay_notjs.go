@@ -0,0 +1,86 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !js
+
+package game
+
+import (
+	"bufio"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/gamestate"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/input"
+)
+
+var (
+	recordOut     = flag.String("record", "", "record inputs to file")
+	recordOutFile *os.File
+
+	replayIn = flag.String("replay", "", "replay inputs from file")
+)
+
+// startRecordingOrReplayingIfNeeded must be called before any game state is created
+// so that the random seed is applied.
+func startRecordingOrReplayingIfNeeded() error {
+	if *replayIn != "" {
+		// The file is kept open while replaying.
+		f, err := os.Open(*replayIn)
+		if err != nil {
+			return err
+		}
+		seed, err := input.StartReplaying(bufio.NewReader(f))
+		if err != nil {
+			return err
+		}
+		gamestate.SetRandomSeed(seed)
+		log.Printf("Start Replaying: %s", *replayIn)
+		return nil
+	}
+	if *recordOut != "" {
+		// The file is kept open while recording.
+		f, err := os.Create(*recordOut)
+		if err != nil {
+			return err
+		}
+		seed := time.Now().UnixNano()
+		if err := input.StartRecording(f, seed); err != nil {
+			f.Close()
+			return err
+		}
+		recordOutFile = f
+		gamestate.SetRandomSeed(seed)
+		log.Printf("Start Recording: %s", *recordOut)
+		return nil
+	}
+	return nil
+}
+
+// stopRecordingIfNeeded stops recording and closes the file so that the last frames are not lost.
+func stopRecordingIfNeeded() error {
+	if recordOutFile == nil {
+		return nil
+	}
+	input.StopRecording()
+	err := recordOutFile.Close()
+	recordOutFile = nil
+	if err != nil {
+		return err
+	}
+	log.Printf("Stop Recording: %s", *recordOut)
+	return nil
+}
@@ -53,6 +53,7 @@ func (m *Requester) RequestUnlockAchievement(requestID int, achievementID int) {
 
 func (m *Requester) RequestSaveProgress(requestID int, data []uint8) {
 	log.Printf("request save progress: requestID: %d", requestID)
+	saveCoverage()
 	go func() {
 		defer m.game.RespondSaveProgress(requestID)
 
@@ -0,0 +1,208 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
+)
+
+type cameraMode string
+
+const (
+	// cameraModeDefault means that the camera is determined by the room's layout mode.
+	cameraModeDefault cameraMode = ""
+	cameraModeFollow  cameraMode = "follow"
+	cameraModeFixed   cameraMode = "fixed"
+)
+
+// Camera is the explicit camera controlled by the control_camera command.
+//
+// The positions are in pixels of the map without scaling.
+type Camera struct {
+	mode    cameraMode
+	eventID int
+	x       *interpolation.I
+	y       *interpolation.I
+	offsetX *interpolation.I
+	offsetY *interpolation.I
+	zoom    *interpolation.I
+}
+
+func NewCamera() *Camera {
+	return &Camera{
+		x:       interpolation.New(0),
+		y:       interpolation.New(0),
+		offsetX: interpolation.New(0),
+		offsetY: interpolation.New(0),
+		zoom:    interpolation.New(1),
+	}
+}
+
+func (c *Camera) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("mode")
+	e.EncodeString(string(c.mode))
+
+	e.EncodeString("eventId")
+	e.EncodeInt(c.eventID)
+
+	e.EncodeString("x")
+	e.EncodeInterface(c.x)
+
+	e.EncodeString("y")
+	e.EncodeInterface(c.y)
+
+	e.EncodeString("offsetX")
+	e.EncodeInterface(c.offsetX)
+
+	e.EncodeString("offsetY")
+	e.EncodeInterface(c.offsetY)
+
+	e.EncodeString("zoom")
+	e.EncodeInterface(c.zoom)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (c *Camera) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "mode":
+			c.mode = cameraMode(d.DecodeString())
+		case "eventId":
+			c.eventID = d.DecodeInt()
+		case "x":
+			d.DecodeInterface(c.x)
+		case "y":
+			d.DecodeInterface(c.y)
+		case "offsetX":
+			d.DecodeInterface(c.offsetX)
+		case "offsetY":
+			d.DecodeInterface(c.offsetY)
+		case "zoom":
+			d.DecodeInterface(c.zoom)
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: Camera.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: Camera.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+func (c *Camera) Update() {
+	c.x.Update()
+	c.y.Update()
+	c.offsetX.Update()
+	c.offsetY.Update()
+	c.zoom.Update()
+}
+
+func characterCenter(ch *character.Character) (int, int) {
+	x, y := ch.DrawFootPosition()
+	_, h := ch.Size()
+	return x, y - h/2
+}
+
+// center returns the position to put at the center of the screen.
+// center returns false when the camera is not controlled explicitly.
+func (c *Camera) center(g *Game) (int, int, bool) {
+	switch c.mode {
+	case cameraModeDefault:
+		return 0, 0, false
+	case cameraModeFollow:
+		ch := g.Character(g.currentMap.mapID, g.currentMap.roomID, c.eventID)
+		if ch == nil {
+			return 0, 0, false
+		}
+		x, y := characterCenter(ch)
+		return x, y, true
+	case cameraModeFixed:
+		return int(c.x.Current()), int(c.y.Current()), true
+	default:
+		panic(fmt.Sprintf("gamestate: invalid camera mode: %s", c.mode))
+	}
+}
+
+func (c *Camera) follow(eventID int) {
+	c.mode = cameraModeFollow
+	c.eventID = eventID
+}
+
+// pan moves the camera to the given position.
+// If the camera is not fixed, the camera starts moving from the followed character.
+func (c *Camera) pan(g *Game, x, y int, count int, easing interpolation.Easing) {
+	if c.mode != cameraModeFixed {
+		if cx, cy, ok := c.center(g); ok {
+			c.x = interpolation.New(float64(cx))
+			c.y = interpolation.New(float64(cy))
+		} else if p := g.currentMap.FocusingCharacter(); p != nil {
+			cx, cy := characterCenter(p)
+			c.x = interpolation.New(float64(cx))
+			c.y = interpolation.New(float64(cy))
+		} else {
+			count = 0
+		}
+		c.mode = cameraModeFixed
+	}
+	c.x.SetWithEasing(float64(x), count, easing)
+	c.y.SetWithEasing(float64(y), count, easing)
+}
+
+func (c *Camera) setOffset(x, y int, count int, easing interpolation.Easing) {
+	c.offsetX.SetWithEasing(float64(x), count, easing)
+	c.offsetY.SetWithEasing(float64(y), count, easing)
+}
+
+func (c *Camera) setZoom(zoom float64, count int, easing interpolation.Easing) {
+	c.zoom.SetWithEasing(zoom, count, easing)
+}
+
+func (c *Camera) reset() {
+	c.mode = cameraModeDefault
+	c.eventID = 0
+	c.x = interpolation.New(0)
+	c.y = interpolation.New(0)
+	c.offsetX = interpolation.New(0)
+	c.offsetY = interpolation.New(0)
+	c.zoom = interpolation.New(1)
+}
+
+func (c *Camera) isChanging() bool {
+	return c.x.IsChanging() || c.y.IsChanging() || c.offsetX.IsChanging() || c.offsetY.IsChanging() || c.zoom.IsChanging()
+}
+
+func (c *Camera) offset() (int, int) {
+	return int(c.offsetX.Current()), int(c.offsetY.Current())
+}
+
+func (c *Camera) scale() float64 {
+	return c.zoom.Current()
+}
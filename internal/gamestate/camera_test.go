@@ -0,0 +1,94 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate_test
+
+import (
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/gamestate"
+)
+
+func marshalAndUnmarshalGame(t *testing.T, g *Game) *Game {
+	b, err := msgpack.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g2 *Game
+	if err := msgpack.Unmarshal(b, &g2); err != nil {
+		t.Fatal(err)
+	}
+	return g2
+}
+
+func TestCameraAfterMarshal(t *testing.T) {
+	g := NewGame(nil)
+	if _, _, ok := g.CameraCenter(); ok {
+		t.Errorf("CameraCenter() for a new game: got: true, want: false")
+	}
+
+	g.PanCamera(3, 4, 0, data.EasingLinear)
+	g.SetCameraOffset(5, -6, 0, data.EasingLinear)
+	g.ZoomCamera(2, 30, data.EasingEaseIn)
+
+	g2 := marshalAndUnmarshalGame(t, g)
+
+	x, y, ok := g2.CameraCenter()
+	if !ok {
+		t.Fatalf("CameraCenter(): got: false, want: true")
+	}
+	if wantX, wantY, _ := g.CameraCenter(); x != wantX || y != wantY {
+		t.Errorf("CameraCenter(): got: (%d, %d), want: (%d, %d)", x, y, wantX, wantY)
+	}
+	if ox, oy := g2.CameraOffset(); ox != 5 || oy != -6 {
+		t.Errorf("CameraOffset(): got: (%d, %d), want: (5, -6)", ox, oy)
+	}
+	if got, want := g2.CameraZoom(), g.CameraZoom(); got != want {
+		t.Errorf("CameraZoom(): got: %f, want: %f", got, want)
+	}
+	if !g2.IsCameraChanging() {
+		t.Errorf("IsCameraChanging(): got: false, want: true")
+	}
+
+	g2.ResetCamera()
+	g3 := marshalAndUnmarshalGame(t, g2)
+	if _, _, ok := g3.CameraCenter(); ok {
+		t.Errorf("CameraCenter() after ResetCamera: got: true, want: false")
+	}
+	if z := g3.CameraZoom(); z != 1 {
+		t.Errorf("CameraZoom() after ResetCamera: got: %f, want: 1", z)
+	}
+}
+
+func TestCameraResetByTransfer(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1), newTestRoom(2))
+	g.PanCamera(3, 4, 0, data.EasingLinear)
+	g.SetCameraOffset(5, -6, 0, data.EasingLinear)
+	g.ZoomCamera(2, 0, data.EasingLinear)
+	updateGame(t, g, sceneManager)
+
+	g.TransferPlayerImmediately(2, 1, 1, nil)
+	if _, _, ok := g.CameraCenter(); ok {
+		t.Errorf("CameraCenter() after the transfer: got: true, want: false")
+	}
+	if ox, oy := g.CameraOffset(); ox != 0 || oy != 0 {
+		t.Errorf("CameraOffset() after the transfer: got: (%d, %d), want: (0, 0)", ox, oy)
+	}
+	if z := g.CameraZoom(); z != 1 {
+		t.Errorf("CameraZoom() after the transfer: got: %f, want: 1", z)
+	}
+}
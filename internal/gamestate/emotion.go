@@ -0,0 +1,136 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+)
+
+const (
+	emotionIconSize      = 16
+	emotionFrameCount    = 8
+	emotionFrameInterval = 6
+
+	// emotionHoldFrames is the duration to keep showing the last frame.
+	emotionHoldFrames = 24
+)
+
+// emotionRows is the emotion types in the order of the rows in the icon sheet.
+var emotionRows = []data.EmotionType{
+	data.EmotionTypeExclamation,
+	data.EmotionTypeQuestion,
+	data.EmotionTypeMusic,
+	data.EmotionTypeHeart,
+	data.EmotionTypeAnger,
+	data.EmotionTypeSweat,
+	data.EmotionTypeFrustration,
+	data.EmotionTypeSilence,
+	data.EmotionTypeLightBulb,
+	data.EmotionTypeSleep,
+}
+
+// emotion is an animated icon shown above a character by the show_emotion command.
+type emotion struct {
+	eventID     int
+	emotionType data.EmotionType
+	count       int
+}
+
+func (e *emotion) EncodeMsgpack(enc *msgpack.Encoder) error {
+	en := easymsgpack.NewEncoder(enc)
+	en.BeginMap()
+
+	en.EncodeString("eventId")
+	en.EncodeInt(e.eventID)
+
+	en.EncodeString("emotionType")
+	en.EncodeString(string(e.emotionType))
+
+	en.EncodeString("count")
+	en.EncodeInt(e.count)
+
+	en.EndMap()
+	return en.Flush()
+}
+
+func (e *emotion) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "eventId":
+			e.eventID = d.DecodeInt()
+		case "emotionType":
+			e.emotionType = data.EmotionType(d.DecodeString())
+		case "count":
+			e.count = d.DecodeInt()
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: emotion.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: emotion.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+func (e *emotion) update() {
+	e.count++
+}
+
+func (e *emotion) isFinished() bool {
+	return e.count >= emotionFrameCount*emotionFrameInterval+emotionHoldFrames
+}
+
+func (e *emotion) draw(screen *ebiten.Image, ch *character.Character, offsetX, offsetY int) {
+	row := -1
+	for i, t := range emotionRows {
+		if t == e.emotionType {
+			row = i
+			break
+		}
+	}
+	if row == -1 {
+		return
+	}
+	// A project whose assets lack the emotion icons doesn't draw emotions.
+	if !assets.ImageExists("system/game/emotions") {
+		return
+	}
+	frame := e.count / emotionFrameInterval
+	if frame >= emotionFrameCount {
+		frame = emotionFrameCount - 1
+	}
+
+	x, y := ch.DrawFootPosition()
+	_, h := ch.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x-emotionIconSize/2+offsetX), float64(y-h-emotionIconSize+offsetY))
+	img := assets.GetImage("system/game/emotions.png")
+	sx, sy := frame*emotionIconSize, row*emotionIconSize
+	screen.DrawImage(img.SubImage(image.Rect(sx, sy, sx+emotionIconSize, sy+emotionIconSize)).(*ebiten.Image), op)
+}
@@ -0,0 +1,163 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+// emotionFrames is the number of frames an emotion is shown (8 frames × 6 + 24).
+const emotionFrames = 72
+
+func TestShowEmotion(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoomWithEvent(1))
+	if g.ShowEmotion(testMapID, 1, 2, data.EmotionTypeHeart) {
+		t.Errorf("ShowEmotion for a missing character: got: true, want: false")
+	}
+	if !g.ShowEmotion(testMapID, 1, 1, data.EmotionTypeHeart) {
+		t.Fatalf("ShowEmotion: got: false, want: true")
+	}
+	if got, want := g.EmotionForTesting(1), data.EmotionTypeHeart; got != want {
+		t.Errorf("emotion: got: %q, want: %q", got, want)
+	}
+
+	for i := 0; i < emotionFrames-1; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if !g.IsShowingEmotion(1) {
+		t.Errorf("IsShowingEmotion after %d frames: got: false, want: true", emotionFrames-1)
+	}
+	updateGame(t, g, sceneManager)
+	if g.IsShowingEmotion(1) {
+		t.Errorf("IsShowingEmotion after %d frames: got: true, want: false", emotionFrames)
+	}
+}
+
+func TestShowEmotionReplace(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoomWithEvent(1))
+	g.ShowEmotion(testMapID, 1, 1, data.EmotionTypeHeart)
+	g.ShowEmotion(testMapID, 1, character.PlayerEventID, data.EmotionTypeMusic)
+	for i := 0; i < emotionFrames/2; i++ {
+		updateGame(t, g, sceneManager)
+	}
+
+	// A new emotion replaces the character's current emotion and restarts the animation.
+	g.ShowEmotion(testMapID, 1, 1, data.EmotionTypeQuestion)
+	if got, want := g.EmotionForTesting(1), data.EmotionTypeQuestion; got != want {
+		t.Errorf("emotion: got: %q, want: %q", got, want)
+	}
+	// The other character's emotion is kept.
+	if got, want := g.EmotionForTesting(character.PlayerEventID), data.EmotionTypeMusic; got != want {
+		t.Errorf("the player's emotion: got: %q, want: %q", got, want)
+	}
+	for i := 0; i < emotionFrames/2; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if !g.IsShowingEmotion(1) {
+		t.Errorf("IsShowingEmotion: got: false, want: true")
+	}
+	if g.IsShowingEmotion(character.PlayerEventID) {
+		t.Errorf("IsShowingEmotion for the player: got: true, want: false")
+	}
+}
+
+func TestShowEmotionWait(t *testing.T) {
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerAuto,
+					Priority: data.PriorityMiddle,
+					Commands: []*data.Command{
+						{
+							Name: data.CommandNameShowEmotion,
+							Args: &data.CommandArgsShowEmotion{
+								Emotion: data.EmotionTypeExclamation,
+								Wait:    true,
+							},
+						},
+						{
+							Name: data.CommandNameSetSwitch,
+							Args: &data.CommandArgsSetSwitch{
+								ID:     1,
+								IDType: data.SetSwitchIDTypeVal,
+								Value:  true,
+							},
+						},
+					},
+				},
+				{
+					Conditions: []*data.Condition{
+						{
+							Type:  data.ConditionTypeSwitch,
+							ID:    1,
+							Value: true,
+						},
+					},
+					Trigger:  data.TriggerNever,
+					Priority: data.PriorityMiddle,
+				},
+			},
+		},
+	}
+	g, sceneManager := newTestGame(t, nil, r)
+	updateGame(t, g, sceneManager)
+	if !g.IsShowingEmotion(1) {
+		t.Fatalf("IsShowingEmotion: got: false, want: true")
+	}
+	for i := 0; i < emotionFrames-2; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if got := g.SwitchValue(1); got != 0 {
+		t.Errorf("switch before the emotion finishes: got: %d, want: 0", got)
+	}
+	for i := 0; i < 4; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if got := g.SwitchValue(1); got != 1 {
+		t.Errorf("switch after the emotion finishes: got: %d, want: 1", got)
+	}
+}
+
+func TestEmotionAfterMarshal(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoomWithEvent(1))
+	g.ShowEmotion(testMapID, 1, 1, data.EmotionTypeSweat)
+	for i := 0; i < 10; i++ {
+		updateGame(t, g, sceneManager)
+	}
+
+	g2 := marshalAndUnmarshalGame(t, g)
+	if got, want := g2.EmotionForTesting(1), data.EmotionTypeSweat; got != want {
+		t.Errorf("emotion after marshaling: got: %q, want: %q", got, want)
+	}
+	// The elapsed frames are kept.
+	for i := 0; i < emotionFrames-10-1; i++ {
+		updateGame(t, g2, sceneManager)
+	}
+	if !g2.IsShowingEmotion(1) {
+		t.Errorf("IsShowingEmotion: got: false, want: true")
+	}
+	updateGame(t, g2, sceneManager)
+	if g2.IsShowingEmotion(1) {
+		t.Errorf("IsShowingEmotion: got: true, want: false")
+	}
+}
@@ -33,10 +33,13 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/hints"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/input"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/items"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lighting"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/picture"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/tileset"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/variables"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/weather"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/window"
@@ -109,8 +112,10 @@ type Game struct {
 	items                *items.Items
 	variables            *variables.Variables
 	screen               *Screen
+	camera               *Camera
 	windows              *window.Windows
 	pictures             *picture.Pictures
+	lighting             *lighting.Lighting
 	currentMap           *Map
 	lastInterpreterID    int
 	autoSaveEnabled      bool
@@ -122,16 +127,18 @@ type Game struct {
 	lastPlayingBGMName   string
 	lastPlayingBGMVolume float64
 
-	backgrounds map[int]map[int]string
-	foregrounds map[int]map[int]string
-	playerSpeed data.Speed
+	backgrounds   map[int]map[int]string
+	foregrounds   map[int]map[int]string
+	tileOverrides map[int]map[int]*tileOverrides
+	mapLayers     map[int]map[int][]*data.MapLayer
+	playerSpeed   data.Speed
+	rand          Rand
 
 	// Fields that are not dumped
 	pressedPictureID             int
 	releasedPictureID            int
 	triggeredPictureID           int
 	isTitle                      bool
-	rand                         Rand
 	waitingRequestIDs            map[int]struct{}
 	prices                       map[string]string // TODO: We want to use https://godoc.org/golang.org/x/text/currency
 	weather                      *weather.Weather
@@ -141,24 +148,41 @@ type Game struct {
 	minigame                     *Minigame
 }
 
+// seedRand generates seeds for the random sources of games.
+var seedRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// SetRandomSeed resets the seed from which the random sources of games are generated.
+// This is useful to reproduce a session, e.g., replaying recorded inputs.
+func SetRandomSeed(seed int64) {
+	seedRand = rand.New(rand.NewSource(seed))
+}
+
 func generateDefaultRand() Rand {
-	return rand.New(rand.NewSource(time.Now().UnixNano()))
+	return newRandom(seedRand.Int63())
 }
 
-func NewGame() *Game {
+// NewGame creates a new game.
+// If system specifies a fixed random seed, the game's random source starts with it.
+func NewGame(system *data.System) *Game {
 	g := &Game{
 		currentMap:           NewMap(),
 		hints:                &hints.Hints{},
 		items:                &items.Items{},
 		variables:            &variables.Variables{},
 		screen:               &Screen{},
+		camera:               NewCamera(),
 		windows:              &window.Windows{},
 		pictures:             &picture.Pictures{},
+		lighting:             &lighting.Lighting{},
 		rand:                 generateDefaultRand(),
 		autoSaveEnabled:      true,
 		playerControlEnabled: true,
 		playerSpeed:          data.Speed5,
 	}
+	g.currentMap.game = g
+	if system != nil && system.FixedRandomSeed {
+		g.rand = newRandom(system.RandomSeed)
+	}
 	return g
 }
 
@@ -169,14 +193,17 @@ func NewTitleGame(savedGame *Game, onShakeStartGameButton func()) *Game {
 		items:                  &items.Items{},
 		variables:              &variables.Variables{},
 		screen:                 &Screen{},
+		camera:                 NewCamera(),
 		windows:                &window.Windows{},
 		pictures:               &picture.Pictures{},
+		lighting:               &lighting.Lighting{},
 		rand:                   generateDefaultRand(),
 		playerControlEnabled:   true,
 		playerSpeed:            data.Speed5,
 		isTitle:                true,
 		onShakeStartGameButton: onShakeStartGameButton,
 	}
+	g.currentMap.game = g
 
 	if savedGame != nil {
 		g.items = savedGame.items
@@ -202,12 +229,18 @@ func (g *Game) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("screen")
 	e.EncodeInterface(g.screen)
 
+	e.EncodeString("camera")
+	e.EncodeInterface(g.camera)
+
 	e.EncodeString("windows")
 	e.EncodeInterface(g.windows)
 
 	e.EncodeString("pictures")
 	e.EncodeInterface(g.pictures)
 
+	e.EncodeString("lighting")
+	e.EncodeInterface(g.lighting)
+
 	e.EncodeString("currentMap")
 	e.EncodeInterface(g.currentMap)
 
@@ -264,6 +297,43 @@ func (g *Game) EncodeMsgpack(enc *msgpack.Encoder) error {
 	}
 	e.EndMap()
 
+	e.EncodeString("tileOverrides")
+	e.BeginMap()
+	for id, m := range g.tileOverrides {
+		e.EncodeInt(id)
+		e.BeginMap()
+		for id, t := range m {
+			e.EncodeInt(id)
+			e.EncodeInterface(t)
+		}
+		e.EndMap()
+	}
+	e.EndMap()
+
+	e.EncodeString("mapLayers")
+	e.BeginMap()
+	for id, m := range g.mapLayers {
+		e.EncodeInt(id)
+		e.BeginMap()
+		for id, ls := range m {
+			e.EncodeInt(id)
+			e.BeginArray()
+			for _, l := range ls {
+				e.EncodeInterface(l)
+			}
+			e.EndArray()
+		}
+		e.EndMap()
+	}
+	e.EndMap()
+
+	e.EncodeString("rand")
+	if r, ok := g.rand.(*random); ok {
+		e.EncodeInterface(r)
+	} else {
+		e.EncodeNil()
+	}
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -294,6 +364,11 @@ func (g *Game) DecodeMsgpack(dec *msgpack.Decoder) error {
 				g.screen = &Screen{}
 				d.DecodeInterface(g.screen)
 			}
+		case "camera":
+			if !d.SkipCodeIfNil() {
+				g.camera = NewCamera()
+				d.DecodeInterface(g.camera)
+			}
 		case "windows":
 			if !d.SkipCodeIfNil() {
 				g.windows = &window.Windows{}
@@ -304,6 +379,11 @@ func (g *Game) DecodeMsgpack(dec *msgpack.Decoder) error {
 				g.pictures = &picture.Pictures{}
 				d.DecodeInterface(g.pictures)
 			}
+		case "lighting":
+			if !d.SkipCodeIfNil() {
+				g.lighting = &lighting.Lighting{}
+				d.DecodeInterface(g.lighting)
+			}
 		case "currentMap":
 			if !d.SkipCodeIfNil() {
 				g.currentMap = &Map{}
@@ -362,6 +442,49 @@ func (g *Game) DecodeMsgpack(dec *msgpack.Decoder) error {
 					}
 				}
 			}
+		case "tileOverrides":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				g.tileOverrides = map[int]map[int]*tileOverrides{}
+				for i := 0; i < n; i++ {
+					id := d.DecodeInt()
+					g.tileOverrides[id] = map[int]*tileOverrides{}
+					n2 := d.DecodeMapLen()
+					for j := 0; j < n2; j++ {
+						id2 := d.DecodeInt()
+						t := &tileOverrides{}
+						d.DecodeInterface(t)
+						g.tileOverrides[id][id2] = t
+					}
+				}
+			}
+		case "mapLayers":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				g.mapLayers = map[int]map[int][]*data.MapLayer{}
+				for i := 0; i < n; i++ {
+					id := d.DecodeInt()
+					g.mapLayers[id] = map[int][]*data.MapLayer{}
+					n2 := d.DecodeMapLen()
+					for j := 0; j < n2; j++ {
+						id2 := d.DecodeInt()
+						ls := []*data.MapLayer{}
+						n3 := d.DecodeArrayLen()
+						for k := 0; k < n3; k++ {
+							l := &data.MapLayer{}
+							d.DecodeInterface(l)
+							ls = append(ls, l)
+						}
+						g.mapLayers[id][id2] = ls
+					}
+				}
+			}
+		case "rand":
+			if !d.SkipCodeIfNil() {
+				r := &random{}
+				d.DecodeInterface(r)
+				g.rand = r
+			}
 		default:
 			if err := d.Error(); err != nil {
 				return err
@@ -369,7 +492,21 @@ func (g *Game) DecodeMsgpack(dec *msgpack.Decoder) error {
 			return fmt.Errorf("gamestate: Game.DecodeMsgpack failed: unknown key: %s", k)
 		}
 	}
-	g.rand = generateDefaultRand()
+	if g.rand == nil {
+		// The save data might be created before rand was introduced.
+		g.rand = generateDefaultRand()
+	}
+	if g.camera == nil {
+		// The save data might be created before camera was introduced.
+		g.camera = NewCamera()
+	}
+	if g.lighting == nil {
+		// The save data might be created before lighting was introduced.
+		g.lighting = &lighting.Lighting{}
+	}
+	if g.currentMap != nil {
+		g.currentMap.game = g
+	}
 	if err := d.Error(); err != nil {
 		return fmt.Errorf("gamestate: Game.DecodeMsgpack failed: %v", err)
 	}
@@ -389,6 +526,49 @@ func (g *Game) MapPassableAt(through bool, x, y int, ignoreCharacters bool) bool
 	return g.currentMap.Passable(through, x, y, ignoreCharacters)
 }
 
+func (g *Game) MapMoveCost(x, y int) int {
+	return g.currentMap.moveCost(x, y)
+}
+
+func (g *Game) MapSize() (int, int) {
+	return g.currentMap.CurrentRoom().Size()
+}
+
+func (g *Game) AddFollower(imageType data.ImageType, imageName string) {
+	g.currentMap.addFollower(imageType, imageName)
+}
+
+func (g *Game) RemoveFollower(imageName string) {
+	g.currentMap.removeFollower(imageName)
+}
+
+func (g *Game) GatherFollowers(gather bool) {
+	g.currentMap.gatherFollowers(gather)
+}
+
+func (g *Game) IsGatheringFollowers() bool {
+	return g.currentMap.isGatheringFollowers()
+}
+
+func (g *Game) FollowersForTesting() []*character.Character {
+	return g.currentMap.followers
+}
+
+func (g *Game) MapPassableDir(through bool, x, y int, dir data.Dir, ignoreCharacters bool) bool {
+	return g.currentMap.PassableDir(through, x, y, dir, ignoreCharacters)
+}
+
+func (g *Game) EightDirections() bool {
+	return g.currentMap.eightDirections()
+}
+
+func (g *Game) BumpCharacter(mapID, roomID, eventID int, x, y int) {
+	if g.currentMap.mapID != mapID || g.currentMap.roomID != roomID {
+		return
+	}
+	g.currentMap.bumpCharacter(eventID, x, y)
+}
+
 type messageSyntaxParser struct {
 	game         *Game
 	sceneManager *scene.Manager
@@ -398,6 +578,14 @@ func (m *messageSyntaxParser) ParseMessageSyntax(content string) string {
 	return m.game.parseMessageSyntax(m.sceneManager, content)
 }
 
+func (m *messageSyntaxParser) MessageSyntaxVariables(content string) map[int]int64 {
+	return m.game.messageSyntaxVariables(content)
+}
+
+func (m *messageSyntaxParser) ParseMessageSyntaxWithVariables(content string, variables map[int]int64) string {
+	return m.game.parseMessageSyntaxWithVariables(m.sceneManager, content, variables)
+}
+
 func (g *Game) Update(sceneManager *scene.Manager) error {
 	g.items.SetDataItems(sceneManager.Game().Items)
 	if g.lastPlayingBGMName != "" {
@@ -412,12 +600,14 @@ func (g *Game) Update(sceneManager *scene.Manager) error {
 	}
 	g.weather.Update()
 	g.screen.Update()
+	g.camera.Update()
 	playerY := 0
 	if g.currentMap.player != nil {
 		_, playerY = g.currentMap.player.DrawPosition()
 	}
 	g.windows.Update(playerY, &messageSyntaxParser{g, sceneManager}, sceneManager, g.createCharacterList())
 	g.pictures.Update()
+	g.lighting.Update()
 
 	if err := g.currentMap.Update(sceneManager, g); err != nil {
 		return err
@@ -495,11 +685,7 @@ func (g *Game) RequestSave(requestID int, sceneManager *scene.Manager) {
 
 	id := requestID
 	if id == 0 {
-		id = sceneManager.GenerateRequestID()
-		if g.waitingRequestIDs == nil {
-			g.waitingRequestIDs = map[int]struct{}{}
-		}
-		g.waitingRequestIDs[id] = struct{}{}
+		id = g.generateWaitingRequestID(sceneManager)
 	}
 
 	m, err := msgpack.Marshal(g)
@@ -508,6 +694,21 @@ func (g *Game) RequestSave(requestID int, sceneManager *scene.Manager) {
 	}
 	sceneManager.Requester().RequestSaveProgress(id, m)
 	sceneManager.SetProgress(m)
+
+	// The texts marked as read are saved with the progress instead of every time a message is shown.
+	if sceneManager.HasUnsavedReadTexts() {
+		sceneManager.RequestSaveReadTexts(g.generateWaitingRequestID(sceneManager))
+	}
+}
+
+// generateWaitingRequestID generates a request ID whose result is received and discarded at Update.
+func (g *Game) generateWaitingRequestID(sceneManager *scene.Manager) int {
+	id := sceneManager.GenerateRequestID()
+	if g.waitingRequestIDs == nil {
+		g.waitingRequestIDs = map[int]struct{}{}
+	}
+	g.waitingRequestIDs[id] = struct{}{}
+	return id
 }
 
 func (g *Game) RequestSavePermanentVariable(requestID int, sceneManager *scene.Manager, permanentVariableID, variableID int) bool {
@@ -534,6 +735,46 @@ var (
 )
 
 func (g *Game) parseMessageSyntax(sceneManager *scene.Manager, str string) string {
+	return g.parseMessageSyntaxWithVariables(sceneManager, str, nil)
+}
+
+// messageSyntaxVariables returns the current values of the variables that the message commands in str refer to.
+func (g *Game) messageSyntaxVariables(str string) map[int]int64 {
+	var vs map[int]int64
+	for _, m := range reMessageCommand.FindAllStringSubmatch(str, -1) {
+		arg := m[2]
+		switch strings.ToLower(m[1]) {
+		case "v":
+		case "i", "t":
+			m2 := reMessageVariable.FindStringSubmatch(arg)
+			if m2 == nil {
+				continue
+			}
+			arg = m2[1]
+		default:
+			continue
+		}
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			continue
+		}
+		if vs == nil {
+			vs = map[int]int64{}
+		}
+		vs[id] = g.VariableValue(id)
+	}
+	return vs
+}
+
+// parseMessageSyntaxWithVariables is like parseMessageSyntax, but the variables in variables are used
+// instead of the current values.
+func (g *Game) parseMessageSyntaxWithVariables(sceneManager *scene.Manager, str string, variables map[int]int64) string {
+	variableValue := func(id int) int64 {
+		if v, ok := variables[id]; ok {
+			return v
+		}
+		return g.VariableValue(id)
+	}
 	return reMessageCommand.ReplaceAllStringFunc(str, func(part string) string {
 		name := strings.ToLower(part[1:2])
 		args := part[3 : len(part)-1]
@@ -550,7 +791,7 @@ func (g *Game) parseMessageSyntax(sceneManager *scene.Manager, str string) strin
 					if err != nil {
 						return fmt.Sprintf("gamestate: strconv.Atoi failed1: %v", m2[1])
 					}
-					itemID = int(g.VariableValue(varID))
+					itemID = int(variableValue(varID))
 				} else {
 					var err error
 					itemID, err = strconv.Atoi(m1[1])
@@ -566,7 +807,7 @@ func (g *Game) parseMessageSyntax(sceneManager *scene.Manager, str string) strin
 			if err != nil {
 				return fmt.Sprintf("(error:%v)", part)
 			}
-			return fmt.Sprintf("%d", g.variables.VariableValue(id))
+			return fmt.Sprintf("%d", variableValue(id))
 		case "t":
 			if m1 := reMessageTable.FindStringSubmatch(args); m1 != nil {
 				tableName := m1[1]
@@ -577,7 +818,7 @@ func (g *Game) parseMessageSyntax(sceneManager *scene.Manager, str string) strin
 					if err != nil {
 						return fmt.Sprintf("(error:subGroup:%v)", m2[1])
 					}
-					recordID = int(g.VariableValue(varID))
+					recordID = int(variableValue(varID))
 				} else {
 					var err error
 					recordID, err = strconv.Atoi(m1[2])
@@ -589,6 +830,9 @@ func (g *Game) parseMessageSyntax(sceneManager *scene.Manager, str string) strin
 
 				return g.GetTableValueString(sceneManager, tableName, recordID, attrName)
 			}
+		default:
+			// Other commands like rich text markup are handled when rendering.
+			return part
 		}
 		return str
 	})
@@ -613,9 +857,13 @@ func (g *Game) MeetsCondition(cond *data.Condition, eventID int) (bool, error) {
 		v := g.variables.SelfSwitchValue(m, r, eventID, cond.ID)
 		rhs := cond.Value.(bool)
 		return v == rhs, nil
-	case data.ConditionTypeVariable:
-		id := cond.ID
-		v := g.variables.VariableValue(id)
+	case data.ConditionTypeVariable, data.ConditionTypeRegion:
+		var v int64
+		if cond.Type == data.ConditionTypeVariable {
+			v = g.variables.VariableValue(cond.ID)
+		} else {
+			v = int64(g.currentMap.PlayerRegionID())
+		}
 		var rhs int64
 		// TODO: This is redundant: can we refactor them?
 		switch value := cond.Value.(type) {
@@ -715,10 +963,46 @@ func (g *Game) SetRandomForTesting(r Rand) {
 	g.rand = r
 }
 
+// RandomSeedForTesting returns the seed of the random source. 0 is returned when the source is set by SetRandomForTesting.
+func (g *Game) RandomSeedForTesting() int64 {
+	if r, ok := g.rand.(*random); ok {
+		return r.seed
+	}
+	return 0
+}
+
 func (g *Game) RandomValue(min, max int) int {
 	return min + g.rand.Intn(max-min)
 }
 
+func (g *Game) DrawLighting(screen *ebiten.Image, offsetX, offsetY int) {
+	g.lighting.Draw(screen, func(eventID int) (int, int, bool) {
+		ch := g.currentMap.character(eventID)
+		if ch == nil {
+			return 0, 0, false
+		}
+		x, y := ch.DrawFootPosition()
+		_, h := ch.Size()
+		return x, y - h/2, true
+	}, offsetX, offsetY)
+}
+
+func (g *Game) SetAmbientLight(red, green, blue, darkness int, count int) {
+	g.lighting.SetAmbient(red, green, blue, darkness, count)
+}
+
+func (g *Game) IsChangingAmbientLight() bool {
+	return g.lighting.IsChangingAmbient()
+}
+
+func (g *Game) AddLight(id, eventID, x, y, radius, red, green, blue, flicker int) {
+	g.lighting.AddLight(id, eventID, x, y, radius, red, green, blue, flicker)
+}
+
+func (g *Game) RemoveLight(id int) {
+	g.lighting.RemoveLight(id)
+}
+
 func (g *Game) DrawWeather(screen *ebiten.Image) {
 	g.weather.Draw(screen)
 }
@@ -735,12 +1019,50 @@ func (g *Game) ApplyShake(geo *ebiten.GeoM) {
 	g.screen.ApplyShake(geo)
 }
 
+// CameraCenter returns the position in the map to put at the center of the screen.
+// CameraCenter returns false when the camera should be determined by the room's layout mode.
+func (g *Game) CameraCenter() (int, int, bool) {
+	return g.camera.center(g)
+}
+
+// CameraOffset returns the offset of the camera in pixels, which is independent from shaking.
+func (g *Game) CameraOffset() (int, int) {
+	return g.camera.offset()
+}
+
+func (g *Game) CameraZoom() float64 {
+	return g.camera.scale()
+}
+
 func (g *Game) DrawScreen(screenImage *ebiten.Image) {
 	g.screen.Draw(screenImage)
 }
 
 func (g *Game) DrawWindows(screen *ebiten.Image, offsetX, offsetY, windowOffsetY int) {
-	g.windows.Draw(screen, g.createCharacterList(), offsetX, offsetY, windowOffsetY)
+	g.windows.Draw(screen, g.createCharacterList(), offsetX, offsetY, windowOffsetY, g.CameraZoom())
+}
+
+// ShowEmotion shows the emotion icon above the character.
+// ShowEmotion returns false when the character is not found.
+func (g *Game) ShowEmotion(mapID, roomID, eventID int, emotionType data.EmotionType) bool {
+	if g.Character(mapID, roomID, eventID) == nil {
+		return false
+	}
+	return g.currentMap.showEmotion(eventID, emotionType)
+}
+
+func (g *Game) IsShowingEmotion(eventID int) bool {
+	return g.currentMap.isShowingEmotion(eventID)
+}
+
+// EmotionForTesting returns the type of the emotion shown above the character, or an empty string when no emotion is shown.
+func (g *Game) EmotionForTesting(eventID int) data.EmotionType {
+	for _, e := range g.currentMap.emotions {
+		if e.eventID == eventID {
+			return e.emotionType
+		}
+	}
+	return ""
 }
 
 func (g *Game) createCharacterList() []*character.Character {
@@ -821,6 +1143,11 @@ func (g *Game) ShowMessage(sceneManager *scene.Manager, interpreterID, eventID i
 	g.windows.ShowMessage(contentID, &messageSyntaxParser{g, sceneManager}, sceneManager.Game(), eventID, background, positionType, textAlign, interpreterID, messageStyle)
 }
 
+// MessageLogTexts returns the shown messages, balloons and chosen choices in the current language from the oldest.
+func (g *Game) MessageLogTexts(sceneManager *scene.Manager) []*window.MessageLogText {
+	return g.windows.MessageLogTexts(&messageSyntaxParser{g, sceneManager}, sceneManager.Game())
+}
+
 func (g *Game) ShowChoices(sceneManager *scene.Manager, interpreterID int, eventID int, choiceIDs []data.UUID, conditions []*data.ChoiceCondition) {
 	choices := []*window.Choice{}
 	for i, id := range choiceIDs {
@@ -932,8 +1259,17 @@ func (g *Game) ExecutableEventAtPlayer() *character.Character {
 	return g.currentMap.executableEventAt(p.Position())
 }
 
-func (g *Game) CurrentEvents() []*data.Event {
-	return g.currentMap.CurrentRoom().Events
+// EventData returns the event data of the event in the current room, including spawned events.
+func (g *Game) EventData(eventID int) *data.Event {
+	return g.currentMap.eventData(eventID)
+}
+
+func (g *Game) SpawnEvent(roomID, eventID int, x, y int) (int, error) {
+	return g.currentMap.spawnEvent(g, roomID, eventID, x, y)
+}
+
+func (g *Game) DestroyEvent(eventID int) bool {
+	return g.currentMap.destroyEvent(g, eventID)
 }
 
 func (g *Game) SetFadeColor(clr color.Color) {
@@ -956,6 +1292,33 @@ func (g *Game) FadeOut(time int) {
 	g.screen.fadeOut(time)
 }
 
+func (g *Game) FollowCamera(eventID int) {
+	g.camera.follow(eventID)
+}
+
+// PanCamera moves the camera so that the tile at (x, y) is at the center of the screen.
+func (g *Game) PanCamera(x, y int, time int, easing data.Easing) {
+	px := x*consts.TileSize + consts.TileSize/2
+	py := y*consts.TileSize + consts.TileSize/2
+	g.camera.pan(g, px, py, time, interpolation.Easing(easing))
+}
+
+func (g *Game) SetCameraOffset(x, y int, time int, easing data.Easing) {
+	g.camera.setOffset(x, y, time, interpolation.Easing(easing))
+}
+
+func (g *Game) ZoomCamera(zoom float64, time int, easing data.Easing) {
+	g.camera.setZoom(zoom, time, interpolation.Easing(easing))
+}
+
+func (g *Game) ResetCamera() {
+	g.camera.reset()
+}
+
+func (g *Game) IsCameraChanging() bool {
+	return g.camera.isChanging()
+}
+
 func (g *Game) StartShaking(power, speed, count int, dir data.ShakeDirection) {
 	g.screen.startShaking(power, speed, count, dir)
 }
@@ -1026,7 +1389,7 @@ func (g *Game) GetTableValueString(sceneManager *scene.Manager, tableName string
 	return r
 }
 
-func (g *Game) calcVariableRhs(sceneManager *scene.Manager, lhs int64, op data.SetVariableOp, valueType data.SetVariableValueType, value interface{}, mapID, roomID, eventID int) (int64, error) {
+func (g *Game) calcVariableRhs(sceneManager *scene.Manager, lhs int64, op data.SetVariableOp, valueType data.SetVariableValueType, value interface{}, mapID, roomID, eventID, collidedEventID int) (int64, error) {
 	var rhs int64
 	switch valueType {
 	case data.SetVariableValueTypeConstant:
@@ -1070,6 +1433,14 @@ func (g *Game) calcVariableRhs(sceneManager *scene.Manager, lhs int64, op data.S
 				rhs = 2
 			case data.DirLeft:
 				rhs = 3
+			case data.DirUpRight:
+				rhs = 4
+			case data.DirDownRight:
+				rhs = 5
+			case data.DirDownLeft:
+				rhs = 6
+			case data.DirUpLeft:
+				rhs = 7
 			default:
 				panic(fmt.Sprintf("gamestate: invalid dir: %d at data.SetVariableValueTypeCharacter", dir))
 			}
@@ -1128,6 +1499,12 @@ func (g *Game) calcVariableRhs(sceneManager *scene.Manager, lhs int64, op data.S
 			}
 		case data.SystemVariableRoomID:
 			rhs = int64(roomID)
+		case data.SystemVariablePlayerRegionID:
+			rhs = int64(g.currentMap.PlayerRegionID())
+		case data.SystemVariablePlayerTerrainTag:
+			rhs = int64(g.currentMap.TerrainTag(g.currentMap.player.Position()))
+		case data.SystemVariableCollidedEventID:
+			rhs = int64(collidedEventID)
 		case data.SystemVariableCurrentTime:
 			rhs = time.Now().Unix()
 		case data.SystemVariableActiveItemID:
@@ -1172,9 +1549,9 @@ func (g *Game) calcVariableRhs(sceneManager *scene.Manager, lhs int64, op data.S
 	return rhs, nil
 }
 
-func (g *Game) SetVariable(sceneManager *scene.Manager, variableID int, op data.SetVariableOp, valueType data.SetVariableValueType, value interface{}, mapID, roomID, eventID int) error {
+func (g *Game) SetVariable(sceneManager *scene.Manager, variableID int, op data.SetVariableOp, valueType data.SetVariableValueType, value interface{}, mapID, roomID, eventID, collidedEventID int) error {
 	lhs := g.VariableValue(variableID)
-	rhs, err := g.calcVariableRhs(sceneManager, lhs, op, valueType, value, mapID, roomID, eventID)
+	rhs, err := g.calcVariableRhs(sceneManager, lhs, op, valueType, value, mapID, roomID, eventID, collidedEventID)
 	if err != nil {
 		return err
 	}
@@ -1182,9 +1559,9 @@ func (g *Game) SetVariable(sceneManager *scene.Manager, variableID int, op data.
 	return nil
 }
 
-func (g *Game) SetVariableRef(sceneManager *scene.Manager, variableID int, op data.SetVariableOp, valueType data.SetVariableValueType, value interface{}, mapID, roomID, eventID int) error {
+func (g *Game) SetVariableRef(sceneManager *scene.Manager, variableID int, op data.SetVariableOp, valueType data.SetVariableValueType, value interface{}, mapID, roomID, eventID, collidedEventID int) error {
 	lhs := g.VariableValue(int(g.VariableValue(variableID)))
-	rhs, err := g.calcVariableRhs(sceneManager, lhs, op, valueType, value, mapID, roomID, eventID)
+	rhs, err := g.calcVariableRhs(sceneManager, lhs, op, valueType, value, mapID, roomID, eventID, collidedEventID)
 	if err != nil {
 		return err
 	}
@@ -1241,6 +1618,127 @@ func (g *Game) SetForeground(mapID, roomID int, image string) {
 	g.foregrounds[mapID][roomID] = image
 }
 
+// SetMapLayer adds or replaces the image layer that has the same ID in the room.
+// If the layer's image is empty, the layer is removed.
+// SetMapLayer returns an error when the room is not in the current map.
+func (g *Game) SetMapLayer(mapID, roomID int, layer *data.MapLayer) error {
+	if mapID != g.currentMap.mapID {
+		return fmt.Errorf("gamestate: invalid map ID: %d at SetMapLayer", mapID)
+	}
+	room := g.currentMap.room(roomID)
+	if room == nil {
+		return fmt.Errorf("gamestate: invalid room ID: %d at SetMapLayer", roomID)
+	}
+
+	layers, ok := g.MapLayers(mapID, roomID)
+	if !ok {
+		layers = room.Layers
+	}
+
+	newLayers := []*data.MapLayer{}
+	replaced := false
+	for _, l := range layers {
+		if l.ID != layer.ID {
+			newLayers = append(newLayers, l)
+			continue
+		}
+		if layer.Image != "" {
+			newLayers = append(newLayers, layer)
+		}
+		replaced = true
+	}
+	if !replaced && layer.Image != "" {
+		newLayers = append(newLayers, layer)
+	}
+
+	if g.mapLayers == nil {
+		g.mapLayers = map[int]map[int][]*data.MapLayer{}
+	}
+	if _, ok := g.mapLayers[mapID]; !ok {
+		g.mapLayers[mapID] = map[int][]*data.MapLayer{}
+	}
+	g.mapLayers[mapID][roomID] = newLayers
+	return nil
+}
+
+// MapLayers returns the image layers of the room changed by SetMapLayer.
+func (g *Game) MapLayers(mapID, roomID int) ([]*data.MapLayer, bool) {
+	if g.mapLayers != nil {
+		if r, ok := g.mapLayers[mapID]; ok {
+			if ls, ok := r[roomID]; ok {
+				return ls, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// SetTile changes the tile at (x, y) on the layer in the room.
+// If passageType is not nil, the passage type at (x, y) is also changed.
+// SetTile returns an error when the room, the layer, the position or the tile is invalid.
+func (g *Game) SetTile(mapID, roomID int, layer, x, y int, tile int, passageType *data.PassageType) error {
+	if mapID != g.currentMap.mapID {
+		return fmt.Errorf("gamestate: invalid map ID: %d at SetTile", mapID)
+	}
+	room := g.currentMap.room(roomID)
+	if room == nil {
+		return fmt.Errorf("gamestate: invalid room ID: %d at SetTile", roomID)
+	}
+	if layer < 0 || len(room.Tiles) <= layer {
+		return fmt.Errorf("gamestate: invalid layer: %d at SetTile", layer)
+	}
+	if w, h := room.Size(); x < 0 || y < 0 || w <= x || h <= y {
+		return fmt.Errorf("gamestate: invalid position: (%d, %d) at SetTile", x, y)
+	}
+	// 0 means no tile.
+	if tile < 0 || tile != 0 && g.currentMap.FindImageName(tileset.ExtractImageID(tile)) == "" {
+		return fmt.Errorf("gamestate: invalid tile: %d at SetTile", tile)
+	}
+
+	if g.tileOverrides == nil {
+		g.tileOverrides = map[int]map[int]*tileOverrides{}
+	}
+	if _, ok := g.tileOverrides[mapID]; !ok {
+		g.tileOverrides[mapID] = map[int]*tileOverrides{}
+	}
+	t, ok := g.tileOverrides[mapID][roomID]
+	if !ok {
+		t = &tileOverrides{}
+		g.tileOverrides[mapID][roomID] = t
+	}
+	index := room.TileIndex(x, y)
+	t.setTile(layer, index, tile)
+	if passageType != nil {
+		t.setPassageType(index, *passageType)
+	}
+	g.currentMap.tilesVersion++
+	return nil
+}
+
+// ResetPassageType removes the passage type at (x, y) set by SetTile.
+func (g *Game) ResetPassageType(mapID, roomID int, x, y int) {
+	t := g.roomTileOverrides(mapID, roomID)
+	if t == nil {
+		return
+	}
+	room := g.currentMap.room(roomID)
+	if room == nil {
+		return
+	}
+	t.resetPassageType(room.TileIndex(x, y))
+}
+
+func (g *Game) roomTileOverrides(mapID, roomID int) *tileOverrides {
+	if g.tileOverrides == nil {
+		return nil
+	}
+	r, ok := g.tileOverrides[mapID]
+	if !ok {
+		return nil
+	}
+	return r[roomID]
+}
+
 func (g *Game) Background(mapID, roomID int) (string, bool) {
 	if g.backgrounds != nil {
 		if r, ok := g.backgrounds[mapID]; ok {
@@ -15,11 +15,16 @@
 package gamestate_test
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/vmihailenco/msgpack"
 
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/coverage"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	. "github.com/hajimehoshi/rpgsnack-runtime/internal/gamestate"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
 )
 
 type pseudoRand struct {
@@ -56,3 +61,188 @@ func TestMarshalGame(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRandomAfterMarshal(t *testing.T) {
+	g := NewGame(nil)
+	for i := 0; i < 10; i++ {
+		g.RandomValue(0, 100)
+	}
+	b, err := msgpack.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g2 *Game
+	if err := msgpack.Unmarshal(b, &g2); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		got := g2.RandomValue(0, 100)
+		want := g.RandomValue(0, 100)
+		if got != want {
+			t.Errorf("RandomValue(0, 100) #%d after marshaling: got: %d, want: %d", i, got, want)
+		}
+	}
+}
+
+func TestFixedRandomSeed(t *testing.T) {
+	s := &data.System{
+		FixedRandomSeed: true,
+		RandomSeed:      12345,
+	}
+	g1 := NewGame(s)
+	g2 := NewGame(s)
+	for i := 0; i < 10; i++ {
+		got := g2.RandomValue(0, 100)
+		want := g1.RandomValue(0, 100)
+		if got != want {
+			t.Errorf("RandomValue(0, 100) #%d with a fixed seed: got: %d, want: %d", i, got, want)
+		}
+	}
+}
+
+func TestRandomSeedAfterMarshal(t *testing.T) {
+	s := &data.System{
+		FixedRandomSeed: true,
+		RandomSeed:      12345,
+	}
+	g := NewGame(s)
+	for i := 0; i < 10; i++ {
+		g.RandomValue(0, 100)
+	}
+	b, err := msgpack.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g2 *Game
+	if err := msgpack.Unmarshal(b, &g2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g2.RandomSeedForTesting(), int64(12345); got != want {
+		t.Errorf("RandomSeedForTesting() after marshaling: got: %d, want: %d", got, want)
+	}
+}
+
+// saveRequester is a scene.Requester that records the save requests.
+type saveRequester struct {
+	scene.Requester
+
+	progressRequestIDs  []int
+	permanentRequestIDs []int
+	permanent           []byte
+}
+
+func (s *saveRequester) RequestSaveProgress(requestID int, bs []byte) {
+	s.progressRequestIDs = append(s.progressRequestIDs, requestID)
+}
+
+func (s *saveRequester) RequestSavePermanent(requestID int, bs []byte) {
+	s.permanentRequestIDs = append(s.permanentRequestIDs, requestID)
+	s.permanent = bs
+}
+
+func TestReadTextsSavedWithProgress(t *testing.T) {
+	r := &saveRequester{}
+	g, sceneManager := startTestGameWithRequester(t, newTestGameData(t, nil, newTestRoom(1)), r)
+
+	id := data.NewUUID()
+	sceneManager.MarkTextRead(id)
+	sceneManager.MarkTextRead(id)
+	if !sceneManager.IsTextRead(id) {
+		t.Errorf("IsTextRead(): got: false, want: true")
+	}
+	// Marking a text as read doesn't save the permanent data by itself.
+	if got, want := len(r.permanentRequestIDs), 0; got != want {
+		t.Fatalf("the number of the permanent saves: got: %d, want: %d", got, want)
+	}
+
+	g.RequestSave(0, sceneManager)
+	if got, want := len(r.progressRequestIDs), 1; got != want {
+		t.Fatalf("the number of the progress saves: got: %d, want: %d", got, want)
+	}
+	if got, want := len(r.permanentRequestIDs), 1; got != want {
+		t.Fatalf("the number of the permanent saves: got: %d, want: %d", got, want)
+	}
+	if r.permanentRequestIDs[0] == 0 {
+		t.Errorf("the request ID of the permanent save must not be 0")
+	}
+	var p scene.Permanent
+	if err := msgpack.Unmarshal(r.permanent, &p); err != nil {
+		t.Fatal(err)
+	}
+	if !p.ReadTexts[id.String()] {
+		t.Errorf("the saved permanent data doesn't include the read text")
+	}
+
+	// The read texts are already saved.
+	g.RequestSave(0, sceneManager)
+	if got, want := len(r.permanentRequestIDs), 1; got != want {
+		t.Errorf("the number of the permanent saves: got: %d, want: %d", got, want)
+	}
+}
+
+func TestCoverageAfterMarshal(t *testing.T) {
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerAuto,
+					Priority: data.PriorityMiddle,
+					Commands: []*data.Command{
+						{
+							Name: data.CommandNameWait,
+							Args: &data.CommandArgsWait{Time: 2},
+						},
+						{
+							Name: data.CommandNameSetSelfSwitch,
+							Args: &data.CommandArgsSetSelfSwitch{ID: 0, Value: true},
+						},
+					},
+				},
+				{
+					Conditions: []*data.Condition{
+						{
+							Type:  data.ConditionTypeSelfSwitch,
+							ID:    0,
+							Value: true,
+						},
+					},
+					Trigger:  data.TriggerNever,
+					Priority: data.PriorityMiddle,
+				},
+			},
+		},
+	}
+	gameData := newTestGameData(t, nil, r)
+	if err := coverage.Start(gameData, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.Stop()
+
+	g, sceneManager := startTestGame(t, gameData)
+	updateGame(t, g, sceneManager)
+
+	// The interpreter restored in the middle of the commands still records the rest of the commands.
+	g2 := marshalAndUnmarshalGame(t, g)
+	for i := 0; i < 30; i++ {
+		updateGame(t, g2, sceneManager)
+	}
+	b, err := coverage.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c *coverage.Coverage
+	if err := json.Unmarshal(b, &c); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"maps/1/rooms/1/events/1/pages/0/commands/0",
+		"maps/1/rooms/1/events/1/pages/0/commands/1",
+	}
+	if !reflect.DeepEqual(c.Commands, want) {
+		t.Errorf("coverage commands: got: %v, want: %v", c.Commands, want)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/commanditerator"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/coverage"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
@@ -54,6 +55,14 @@ type Interpreter struct {
 	parallel           bool
 	isSub              bool
 
+	// collidedEventID is the ID of the event that the interpreter's event collided with.
+	// This is available as SystemVariableCollidedEventID.
+	collidedEventID int
+
+	// commandsPath is the location of the commands in the game data for coverage.
+	// commandsPath is empty when the commands are not in the game data or the coverage is not recorded.
+	commandsPath string
+
 	// Not dumped.
 	waitingRequestID int
 }
@@ -135,6 +144,12 @@ func (i *Interpreter) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("isSub")
 	e.EncodeBool(i.isSub)
 
+	e.EncodeString("collidedEventId")
+	e.EncodeInt(i.collidedEventID)
+
+	e.EncodeString("commandsPath")
+	e.EncodeString(i.commandsPath)
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -185,6 +200,10 @@ func (i *Interpreter) DecodeMsgpack(dec *msgpack.Decoder) error {
 			i.parallel = d.DecodeBool()
 		case "isSub":
 			i.isSub = d.DecodeBool()
+		case "collidedEventId":
+			i.collidedEventID = d.DecodeInt()
+		case "commandsPath":
+			i.commandsPath = d.DecodeString()
 		case "waitingRequestId":
 			d.Skip()
 		default:
@@ -213,9 +232,32 @@ func (i *Interpreter) createSub(gameState InterpreterIDGenerator, eventID int, p
 	sub.route = i.route
 	sub.pageRoute = i.pageRoute
 	sub.isSub = true
+	sub.collidedEventID = i.collidedEventID
 	return sub
 }
 
+// commandsPathForCoverage returns the location of the commands of the tree, or an empty string when the coverage is not recorded.
+func commandsPathForCoverage(tree string) string {
+	if !coverage.IsRecording() {
+		return ""
+	}
+	return coverage.CommandsPath(tree)
+}
+
+func (i *Interpreter) recordCommand() {
+	if !coverage.IsRecording() || i.commandsPath == "" {
+		return
+	}
+	coverage.RecordCommand(i.commandIterator.Path(i.commandsPath))
+}
+
+func (i *Interpreter) recordBranch(branch int) {
+	if !coverage.IsRecording() || i.commandsPath == "" {
+		return
+	}
+	coverage.RecordBranch(i.commandIterator.Path(i.commandsPath), branch)
+}
+
 func (i *Interpreter) findMessageStyle(sceneManager *scene.Manager, messageStyleID int) *data.MessageStyle {
 	messageStyles := sceneManager.Game().MessageStyles
 	if messageStyleID > 0 {
@@ -274,6 +316,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		return true, nil
 	}
 	c := i.commandIterator.Command()
+	i.recordCommand()
 	switch c.Name {
 	case data.CommandNameNop:
 		i.commandIterator.Advance()
@@ -291,8 +334,10 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			}
 		}
 		if matches {
+			i.recordBranch(0)
 			i.commandIterator.Choose(0)
 		} else if len(c.Branches) >= 2 {
+			i.recordBranch(1)
 			i.commandIterator.Choose(1)
 		} else {
 			i.commandIterator.Advance()
@@ -325,13 +370,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			return true, nil
 		}
 
-		var event *data.Event
-		for _, e := range gameState.CurrentEvents() {
-			if e.ID() == eventID {
-				event = e
-				break
-			}
-		}
+		event := gameState.EventData(eventID)
 		if event == nil {
 			// TODO: warning?
 			i.commandIterator.Advance()
@@ -340,6 +379,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		page := event.Pages()[args.PageIndex]
 		commands := page.Commands
 		i.sub = i.createSub(gameState, eventID, args.PageIndex, commands)
+		i.sub.commandsPath = gameState.currentMap.pageCommandsPath(eventID, args.PageIndex)
 
 	case data.CommandNameCallCommonEvent:
 		args := c.Args.(*data.CommandArgsCallCommonEvent)
@@ -356,6 +396,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 		// TODO: Is this correct to the pass event id and the page index here?
 		i.sub = i.createSub(gameState, i.eventID, i.pageIndex, c.Commands)
+		i.sub.commandsPath = commandsPathForCoverage(coverage.CommonEventTree(eventID))
 
 	case data.CommandNameReturn:
 		i.commandIterator.Terminate()
@@ -366,6 +407,47 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			ch.Erase()
 		}
 
+	case data.CommandNameSpawnEvent:
+		args := c.Args.(*data.CommandArgsSpawnEvent)
+		x, y := args.X, args.Y
+		if args.ValueType == data.ValueTypeVariable {
+			x = int(gameState.VariableValue(x))
+			y = int(gameState.VariableValue(y))
+		}
+		id, err := gameState.SpawnEvent(args.RoomID, args.EventID, x, y)
+		if err != nil {
+			// The event might have been removed in the editor. Skip the command instead of stopping the game.
+			log.Print(err)
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		if args.IDVariable != 0 {
+			gameState.SetVariableValue(args.IDVariable, int64(id))
+		}
+		i.commandIterator.Advance()
+
+	case data.CommandNameDestroyEvent:
+		args := c.Args.(*data.CommandArgsDestroyEvent)
+		id := args.EventID
+		if args.ValueType == data.ValueTypeVariable {
+			id = int(gameState.VariableValue(id))
+		}
+		if id == 0 {
+			id = i.eventID
+		}
+		self := id == i.eventID
+		if !gameState.DestroyEvent(id) {
+			// The event is not a spawned event.
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		if self {
+			// Like erase_event, the interpreter of the destroyed event finishes here.
+			i.commandIterator.Terminate()
+			return true, nil
+		}
+		i.commandIterator.Advance()
+
 	case data.CommandNameWait:
 		if i.waitingCount == 0 {
 			time := c.Args.(*data.CommandArgsWait).Time
@@ -411,6 +493,25 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 		i.waitingCommand = false
 
+	case data.CommandNameShowEmotion:
+		args := c.Args.(*data.CommandArgsShowEmotion)
+		id := args.EventID
+		if id == 0 {
+			id = i.eventID
+		}
+		if !i.waitingCommand {
+			if !gameState.ShowEmotion(i.mapID, i.roomID, id, args.Emotion) || !args.Wait {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = true
+		}
+		if gameState.IsShowingEmotion(id) {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+
 	case data.CommandNameShowMessage:
 		args := c.Args.(*data.CommandArgsShowMessage)
 		if !i.waitingCommand {
@@ -448,6 +549,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			if h.ID == hintId {
 				c := h.Commands
 				i.sub = i.createSub(gameState, i.eventID, i.pageIndex, c)
+				i.sub.commandsPath = commandsPathForCoverage(coverage.HintTree(hintId))
 				hasHint = true
 				break
 			}
@@ -477,6 +579,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 
 		idx := gameState.RealChoiceIndex(sceneManager, gameState.ChosenWindowIndex(), i.eventID, c.Args.(*data.CommandArgsShowChoices).Conditions)
 		if idx >= 0 {
+			i.recordBranch(idx)
 			i.commandIterator.Choose(idx)
 		} else {
 			i.commandIterator.Advance()
@@ -508,11 +611,11 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 
 		if args.IDType == data.SetVariableIDTypeRef {
-			if err := gameState.SetVariableRef(sceneManager, args.ID, args.Op, args.ValueType, args.Value, i.mapID, i.roomID, i.eventID); err != nil {
+			if err := gameState.SetVariableRef(sceneManager, args.ID, args.Op, args.ValueType, args.Value, i.mapID, i.roomID, i.eventID, i.collidedEventID); err != nil {
 				return false, err
 			}
 		} else {
-			if err := gameState.SetVariable(sceneManager, args.ID, args.Op, args.ValueType, args.Value, i.mapID, i.roomID, i.eventID); err != nil {
+			if err := gameState.SetVariable(sceneManager, args.ID, args.Op, args.ValueType, args.Value, i.mapID, i.roomID, i.eventID, i.collidedEventID); err != nil {
 				return false, err
 			}
 		}
@@ -528,7 +631,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 	case data.CommandNameLoadPermanent:
 		args := c.Args.(*data.CommandArgsLoadPermanent)
 		v := sceneManager.PermanentVariableValue(args.PermanentVariableID)
-		if err := gameState.SetVariable(sceneManager, args.VariableID, data.SetVariableOpAssign, data.SetVariableValueTypeConstant, v, i.mapID, i.roomID, i.eventID); err != nil {
+		if err := gameState.SetVariable(sceneManager, args.VariableID, data.SetVariableOpAssign, data.SetVariableValueTypeConstant, v, i.mapID, i.roomID, i.eventID, i.collidedEventID); err != nil {
 			return false, err
 		}
 		i.commandIterator.Advance()
@@ -598,6 +701,12 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		sub := i.createSub(gameState, id, i.pageIndex, args.Commands)
 		sub.repeat = args.Repeat
 		sub.routeSkip = args.Skip
+		if i.commandsPath != "" {
+			sub.commandsPath = coverage.RouteCommandsPath(i.commandIterator.Path(i.commandsPath))
+		} else if i.pageRoute && !i.isSub {
+			// The SetRoute command of a page route is generated at runtime.
+			sub.commandsPath = gameState.currentMap.pageRouteCommandsPath(i.eventID, i.pageIndex)
+		}
 
 		if id != character.PlayerEventID && !args.Internal {
 			gameState.Map().removeNonPageRoutes(id)
@@ -636,6 +745,139 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 		i.waitingCommand = false
 		i.commandIterator.Advance()
+	case data.CommandNameControlCamera:
+		if !i.waitingCommand {
+			args := c.Args.(*data.CommandArgsControlCamera)
+			x := args.X
+			y := args.Y
+			if args.ValueType == data.ValueTypeVariable {
+				x = int(gameState.VariableValue(x))
+				y = int(gameState.VariableValue(y))
+			}
+			switch args.Type {
+			case data.ControlCameraTypeFollow:
+				id := args.EventID
+				if id == 0 {
+					id = i.eventID
+				}
+				gameState.FollowCamera(id)
+			case data.ControlCameraTypePan:
+				gameState.PanCamera(x, y, args.Time*6, args.Easing)
+			case data.ControlCameraTypeOffset:
+				gameState.SetCameraOffset(x, y, args.Time*6, args.Easing)
+			case data.ControlCameraTypeZoom:
+				zoom := args.Zoom
+				if zoom <= 0 {
+					zoom = 100
+				}
+				gameState.ZoomCamera(float64(zoom)/100, args.Time*6, args.Easing)
+			case data.ControlCameraTypeReset:
+				gameState.ResetCamera()
+			default:
+				return false, fmt.Errorf("gamestate: invalid control_camera type: %s", args.Type)
+			}
+			if !args.Wait {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = args.Wait
+		}
+		if gameState.IsCameraChanging() {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+	case data.CommandNameAddFollower:
+		args := c.Args.(*data.CommandArgsAddFollower)
+		gameState.AddFollower(args.ImageType, args.Image)
+		i.commandIterator.Advance()
+	case data.CommandNameRemoveFollower:
+		args := c.Args.(*data.CommandArgsRemoveFollower)
+		gameState.RemoveFollower(args.Image)
+		i.commandIterator.Advance()
+	case data.CommandNameGatherFollowers:
+		if !i.waitingCommand {
+			args := c.Args.(*data.CommandArgsGatherFollowers)
+			gameState.GatherFollowers(args.Gather)
+			if !args.Wait || !args.Gather {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = args.Wait
+		}
+		if gameState.IsGatheringFollowers() {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+	case data.CommandNameSetTile:
+		args := c.Args.(*data.CommandArgsSetTile)
+		x, y := args.X, args.Y
+		if args.ValueType == data.ValueTypeVariable {
+			x = int(gameState.VariableValue(x))
+			y = int(gameState.VariableValue(y))
+		}
+		roomID := args.RoomID
+		if roomID == 0 {
+			roomID = i.roomID
+		}
+		var passageType *data.PassageType
+		switch args.PassageType {
+		case data.SetTilePassageTypeNone, data.SetTilePassageTypeTile:
+		case data.SetTilePassageTypePassable:
+			p := data.PassageTypePassable
+			passageType = &p
+		case data.SetTilePassageTypeBlock:
+			p := data.PassageTypeBlock
+			passageType = &p
+		default:
+			log.Printf("gamestate: invalid set_tile passage type: %s", args.PassageType)
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		// An invalid tile or position might come from a variable. Skip the command instead of stopping the game.
+		if err := gameState.SetTile(i.mapID, roomID, args.Layer, x, y, args.Tile, passageType); err != nil {
+			log.Print(err)
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		if args.PassageType == data.SetTilePassageTypeTile {
+			gameState.ResetPassageType(i.mapID, roomID, x, y)
+		}
+		i.commandIterator.Advance()
+	case data.CommandNameSetAmbientLight:
+		args := c.Args.(*data.CommandArgsSetAmbientLight)
+		if !i.waitingCommand {
+			gameState.SetAmbientLight(args.Red, args.Green, args.Blue, args.Darkness, args.Time*6)
+			if !args.Wait {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = true
+		}
+		if gameState.IsChangingAmbientLight() {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+
+	case data.CommandNameAddLight:
+		args := c.Args.(*data.CommandArgsAddLight)
+		eventID := 0
+		if args.Target == data.LightTargetCharacter {
+			eventID = args.EventID
+			if eventID == 0 {
+				eventID = i.eventID
+			}
+		}
+		gameState.AddLight(args.ID, eventID, args.X, args.Y, args.Radius, args.Red, args.Green, args.Blue, args.Flicker)
+		i.commandIterator.Advance()
+
+	case data.CommandNameRemoveLight:
+		args := c.Args.(*data.CommandArgsRemoveLight)
+		gameState.RemoveLight(args.ID)
+		i.commandIterator.Advance()
+
 	case data.CommandNameTintScreen:
 		if !i.waitingCommand {
 			args := c.Args.(*data.CommandArgsTintScreen)
@@ -901,50 +1143,72 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 		if !i.waitingCommand {
 			args := c.Args.(*data.CommandArgsRotateCharacter)
-			dirI := 0
-			switch ch.Dir() {
-			case data.DirUp:
-				dirI = 0
-			case data.DirRight:
-				dirI = 1
-			case data.DirDown:
-				dirI = 2
-			case data.DirLeft:
-				dirI = 3
-			default:
-				panic(fmt.Sprintf("gamestate: invalid character dir: %d at data.CommandNameRouteCharacter", ch.Dir()))
-			}
-			switch args.Angle {
-			case 0:
-			case 90:
-				dirI += 1
-			case 180:
-				dirI += 2
-			case 270:
-				dirI += 3
-			default:
+			if args.Angle < 0 || 360 <= args.Angle || args.Angle%45 != 0 {
 				panic(fmt.Sprintf("gamestate: invalid angle: %d at data.CommandNameRouteCharacter", args.Angle))
 			}
-			dirI %= 4
-			var dir data.Dir
-			switch dirI {
-			case 0:
-				dir = data.DirUp
-			case 1:
-				dir = data.DirRight
-			case 2:
-				dir = data.DirDown
-			case 3:
-				dir = data.DirLeft
-			default:
-				panic(fmt.Sprintf("gamestate: invalid dir: %d at data.CommandNameRouteCharacter", dirI))
+			// A multiple of 45 other than 90 is available only when the eight directions are enabled.
+			// The command might be left after the eight directions are disabled. Skip it instead of stopping the game.
+			if args.Angle%90 != 0 && !gameState.EightDirections() {
+				log.Printf("gamestate: rotate_character by %d degrees requires the eight directions", args.Angle)
+				i.commandIterator.Advance()
+				return true, nil
 			}
+			dir := ch.Dir().Rotate(args.Angle)
 			ch.Turn(dir)
 			i.waitingCommand = true
 			return false, nil
 		}
 		i.waitingCommand = false
 		i.commandIterator.Advance()
+	case data.CommandNameJumpCharacter:
+		ch := gameState.Character(i.mapID, i.roomID, i.eventID)
+		if ch == nil {
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		// Check IsMoving() first since the character might be moving or jumping at this time.
+		if ch.IsMoving() {
+			return false, nil
+		}
+		if !i.waitingCommand {
+			args := c.Args.(*data.CommandArgsJumpCharacter)
+			// Intermediate tiles are ignored, but the landing tile must be passable.
+			x, y := ch.Position()
+			if (args.X != 0 || args.Y != 0) && !gameState.MapPassableAt(ch.Through(), x+args.X, y+args.Y, false) {
+				if i.routeSkip {
+					i.commandIterator.Advance()
+					return true, nil
+				}
+				return false, nil
+			}
+			ch.Jump(args.X, args.Y, args.Frames, args.Height)
+			i.waitingCommand = true
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+	case data.CommandNamePlayCharacterAnimation:
+		ch := gameState.Character(i.mapID, i.roomID, i.eventID)
+		if ch == nil {
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		args := c.Args.(*data.CommandArgsPlayCharacterAnimation)
+		if !i.waitingCommand {
+			ch.PlayAnimation(args.Name)
+			if args.Wait {
+				i.waitingCommand = true
+				return false, nil
+			}
+			i.commandIterator.Advance()
+			return true, nil
+		}
+		if ch.IsAnimating() {
+			return false, nil
+		}
+		i.waitingCommand = false
+		i.commandIterator.Advance()
+
 	case data.CommandNameSetCharacterProperty:
 		args := c.Args.(*data.CommandArgsSetCharacterProperty)
 		ch := gameState.Character(i.mapID, i.roomID, i.eventID)
@@ -952,22 +1216,50 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 			i.commandIterator.Advance()
 			return true, nil
 		}
-		switch args.Type {
-		case data.SetCharacterPropertyTypeVisibility:
-			ch.SetVisibility(args.Value.(bool))
-		case data.SetCharacterPropertyTypeDirFix:
-			ch.SetDirFix(args.Value.(bool))
-		case data.SetCharacterPropertyTypeStepping:
-			ch.SetStepping(args.Value.(bool))
-		case data.SetCharacterPropertyTypeThrough:
-			ch.SetThrough(args.Value.(bool))
-		case data.SetCharacterPropertyTypeWalking:
-			ch.SetWalking(args.Value.(bool))
-		case data.SetCharacterPropertyTypeSpeed:
-			ch.SetSpeed(args.Value.(data.Speed))
-		default:
-			return false, fmt.Errorf("invaid set_character_property type: %s", args.Type)
+		if !i.waitingCommand {
+			switch args.Type {
+			case data.SetCharacterPropertyTypeVisibility:
+				ch.SetVisibility(args.Value.(bool))
+			case data.SetCharacterPropertyTypeDirFix:
+				ch.SetDirFix(args.Value.(bool))
+			case data.SetCharacterPropertyTypeStepping:
+				ch.SetStepping(args.Value.(bool))
+			case data.SetCharacterPropertyTypeThrough:
+				ch.SetThrough(args.Value.(bool))
+			case data.SetCharacterPropertyTypeWalking:
+				ch.SetWalking(args.Value.(bool))
+			case data.SetCharacterPropertyTypeSpeed:
+				ch.SetSpeed(args.Value.(data.Speed))
+			case data.SetCharacterPropertyTypeScale:
+				ch.SetScale(float64(args.Value.(int))/100, args.Time*6)
+			case data.SetCharacterPropertyTypeFlipX:
+				_, flipY := ch.Flip()
+				ch.SetFlip(args.Value.(bool), flipY)
+			case data.SetCharacterPropertyTypeFlipY:
+				flipX, _ := ch.Flip()
+				ch.SetFlip(flipX, args.Value.(bool))
+			case data.SetCharacterPropertyTypeTint:
+				t := args.Value.([]int)
+				ch.SetTint(float64(t[0])/255, float64(t[1])/255, float64(t[2])/255, float64(t[3])/255, args.Time*6)
+			case data.SetCharacterPropertyTypeBlendType:
+				ch.SetBlendType(args.Value.(data.ShowPictureBlendType))
+			case data.SetCharacterPropertyTypeZOffset:
+				ch.SetZOffset(args.Value.(int))
+			case data.SetCharacterPropertyTypeShadow:
+				ch.SetShadow(args.Value.(bool))
+			default:
+				return false, fmt.Errorf("invaid set_character_property type: %s", args.Type)
+			}
+			if !args.Wait {
+				i.commandIterator.Advance()
+				return true, nil
+			}
+			i.waitingCommand = true
+		}
+		if ch.IsChangingScale() || ch.IsChangingTint() {
+			return false, nil
 		}
+		i.waitingCommand = false
 		i.commandIterator.Advance()
 	case data.CommandNameSetCharacterImage:
 		args := c.Args.(*data.CommandArgsSetCharacterImage)
@@ -1287,7 +1579,23 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 
 		image := fileValue(sceneManager, gameState, args.ImageValueType, args.Image)
 
-		gameState.SetBackground(i.mapID, i.roomID, image)
+		if args.LayerID == 0 {
+			gameState.SetBackground(i.mapID, i.roomID, image)
+			i.commandIterator.Advance()
+			return true, nil
+		}
+
+		layer := data.NewMapLayer()
+		if args.Layer != nil {
+			*layer = *args.Layer
+		}
+		layer.ID = args.LayerID
+		layer.Image = image
+		layer.Foreground = false
+		if err := gameState.SetMapLayer(i.mapID, i.roomID, layer); err != nil {
+			// The player might be transferred to another map while the interpreter is running.
+			log.Print(err)
+		}
 		i.commandIterator.Advance()
 
 	case data.CommandNameChangeForeground:
@@ -1295,7 +1603,23 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 
 		image := fileValue(sceneManager, gameState, args.ImageValueType, args.Image)
 
-		gameState.SetForeground(i.mapID, i.roomID, image)
+		if args.LayerID == 0 {
+			gameState.SetForeground(i.mapID, i.roomID, image)
+			i.commandIterator.Advance()
+			return true, nil
+		}
+
+		layer := data.NewMapLayer()
+		if args.Layer != nil {
+			*layer = *args.Layer
+		}
+		layer.ID = args.LayerID
+		layer.Image = image
+		layer.Foreground = true
+		if err := gameState.SetMapLayer(i.mapID, i.roomID, layer); err != nil {
+			// The player might be transferred to another map while the interpreter is running.
+			log.Print(err)
+		}
 		i.commandIterator.Advance()
 
 	case data.CommandNameSpecial:
@@ -1328,6 +1652,7 @@ func (i *Interpreter) doOneCommand(sceneManager *scene.Manager, gameState *Game)
 		}
 		c := page.Commands
 		i.sub = i.createSub(gameState, e.EventID(), pageIndex, c)
+		i.sub.commandsPath = gameState.currentMap.pageCommandsPath(e.EventID(), pageIndex)
 
 	case data.CommandNameMemo:
 		args := c.Args.(*data.CommandArgsMemo)
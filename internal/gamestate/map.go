@@ -23,7 +23,7 @@ import (
 
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
-	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/coverage"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
 	pathpkg "github.com/hajimehoshi/rpgsnack-runtime/internal/path"
@@ -33,6 +33,9 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/variables"
 )
 
+// maxPathNodes is the budget of the path finding for tap-to-move.
+const maxPathNodes = 1024
+
 type passableOnMap struct {
 	through          bool
 	ignoreCharacters bool
@@ -43,6 +46,21 @@ func (p *passableOnMap) At(x, y int) bool {
 	return p.m.Passable(p.through, x, y, p.ignoreCharacters)
 }
 
+func (p *passableOnMap) AtDir(x, y int, dir data.Dir) bool {
+	return p.m.PassableDir(p.through, x, y, dir, p.ignoreCharacters)
+}
+
+func (p *passableOnMap) Size() (int, int) {
+	return p.m.CurrentRoom().Size()
+}
+
+func (p *passableOnMap) Cost(x, y int) int {
+	if p.through {
+		return 1
+	}
+	return p.m.moveCost(x, y)
+}
+
 type Map struct {
 	player                      *character.Character
 	mapID                       int
@@ -53,14 +71,51 @@ type Map struct {
 	interpreters                map[int]*Interpreter
 	playerInterpreterID         int
 	itemInterpreter             *Interpreter
+	spawnedEvents               []*spawnedEvent
+	emotions                    []*emotion
+	followers                   []*character.Character
+	followersGathered           bool
+
+	// followerLeadX and followerLeadY are the player's position when the followers moved last time.
+	followerLeadX int
+	followerLeadY int
+
+	// followerTrail is the positions the player stepped from, the latest first.
+	// The i-th follower walks to followerTrail[i] so that the followers replay the player's steps.
+	followerTrail []trailPoint
+
+	// playerRegionID is the region ID where the player was at the last update.
+	playerRegionID int
+
+	// collisionInterpreterIDs maps event IDs to the IDs of their running collision common event interpreters.
+	collisionInterpreterIDs map[int]int
 
 	// Fields that are not dumped
+	game                      *Game
 	isTitle                   bool
 	gameData                  *data.Game
 	isPlayerMovingByUserInput bool
 	origSpeed                 data.Speed
 	pressedMapX               int
 	pressedMapY               int
+	wasPlayerMoving           bool
+	bumps                     []bump
+
+	// tilesVersion is incremented when the tiles in the current room might change.
+	tilesVersion int
+}
+
+// trailPoint is a position in tiles on the player's trail.
+type trailPoint struct {
+	x int
+	y int
+}
+
+// bump represents a character's failure to move into (x, y).
+type bump struct {
+	eventID int
+	x       int
+	y       int
 }
 
 func NewMap() *Map {
@@ -123,6 +178,57 @@ func (m *Map) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("itemInterpreter")
 	e.EncodeInterface(m.itemInterpreter)
 
+	e.EncodeString("spawnedEvents")
+	e.BeginArray()
+	for _, v := range m.spawnedEvents {
+		e.EncodeInterface(v)
+	}
+	e.EndArray()
+
+	e.EncodeString("emotions")
+	e.BeginArray()
+	for _, v := range m.emotions {
+		e.EncodeInterface(v)
+	}
+	e.EndArray()
+
+	e.EncodeString("followers")
+	e.BeginArray()
+	for _, v := range m.followers {
+		e.EncodeInterface(v)
+	}
+	e.EndArray()
+
+	e.EncodeString("followersGathered")
+	e.EncodeBool(m.followersGathered)
+
+	e.EncodeString("followerLeadX")
+	e.EncodeInt(m.followerLeadX)
+
+	e.EncodeString("followerLeadY")
+	e.EncodeInt(m.followerLeadY)
+
+	e.EncodeString("followerTrail")
+	e.BeginArray()
+	for _, p := range m.followerTrail {
+		e.BeginArray()
+		e.EncodeInt(p.x)
+		e.EncodeInt(p.y)
+		e.EndArray()
+	}
+	e.EndArray()
+
+	e.EncodeString("playerRegionId")
+	e.EncodeInt(m.playerRegionID)
+
+	e.EncodeString("collisionInterpreterIds")
+	e.BeginMap()
+	for k, v := range m.collisionInterpreterIDs {
+		e.EncodeInt(k)
+		e.EncodeInt(v)
+	}
+	e.EndMap()
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -185,6 +291,63 @@ func (m *Map) DecodeMsgpack(dec *msgpack.Decoder) error {
 				m.itemInterpreter = &Interpreter{}
 				d.DecodeInterface(m.itemInterpreter)
 			}
+		case "spawnedEvents":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				m.spawnedEvents = make([]*spawnedEvent, n)
+				for i := 0; i < n; i++ {
+					m.spawnedEvents[i] = &spawnedEvent{}
+					d.DecodeInterface(m.spawnedEvents[i])
+				}
+			}
+		case "emotions":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				m.emotions = make([]*emotion, n)
+				for i := 0; i < n; i++ {
+					m.emotions[i] = &emotion{}
+					d.DecodeInterface(m.emotions[i])
+				}
+			}
+		case "followers":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				m.followers = make([]*character.Character, n)
+				for i := 0; i < n; i++ {
+					m.followers[i] = &character.Character{}
+					d.DecodeInterface(m.followers[i])
+				}
+			}
+		case "followersGathered":
+			m.followersGathered = d.DecodeBool()
+		case "followerLeadX":
+			m.followerLeadX = d.DecodeInt()
+		case "followerLeadY":
+			m.followerLeadY = d.DecodeInt()
+		case "followerTrail":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				m.followerTrail = make([]trailPoint, n)
+				for i := 0; i < n; i++ {
+					if d.DecodeArrayLen() != 2 {
+						return fmt.Errorf("gamestate: Map.DecodeMsgpack failed: invalid follower trail")
+					}
+					m.followerTrail[i].x = d.DecodeInt()
+					m.followerTrail[i].y = d.DecodeInt()
+				}
+			}
+		case "playerRegionId":
+			m.playerRegionID = d.DecodeInt()
+		case "collisionInterpreterIds":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				m.collisionInterpreterIDs = map[int]int{}
+				for i := 0; i < n; i++ {
+					k := d.DecodeInt()
+					v := d.DecodeInt()
+					m.collisionInterpreterIDs[k] = v
+				}
+			}
 		default:
 			if err := d.Error(); err != nil {
 				return err
@@ -203,7 +366,15 @@ func (m *Map) addInterpreter(interpreter *Interpreter) {
 }
 
 func (m *Map) setRoomID(gameState *Game, id int, interpreter *Interpreter) error {
+	// Spawned events don't survive a transfer, even to the same room. See data.CommandArgsSpawnEvent.
+	for _, s := range m.spawnedEvents {
+		gameState.variables.ClearSelfSwitches(m.mapID, m.roomID, s.id)
+	}
+	m.spawnedEvents = nil
+	m.emotions = nil
+
 	m.roomID = id
+	m.tilesVersion++
 	m.executingEventIDByUserInput = 0
 	m.events = nil
 	m.eventPageIndices = map[int]int{}
@@ -217,6 +388,16 @@ func (m *Map) setRoomID(gameState *Game, id int, interpreter *Interpreter) error
 		gameState.SetBGM(room.BGM)
 	}
 
+	// The camera is reset by a transfer, as well as the lights.
+	gameState.camera.reset()
+
+	gameState.lighting.ClearRoomLights()
+	if a := room.Ambient; a != nil {
+		gameState.lighting.SetAmbient(a.Red, a.Green, a.Blue, a.Darkness, 0)
+	} else {
+		gameState.lighting.SetAmbient(0, 0, 0, 0, 0)
+	}
+
 	for _, e := range room.Events {
 		x, y := e.Position()
 		event := character.NewEvent(e.ID(), x, y)
@@ -280,13 +461,7 @@ func (m *Map) calcPageIndex(gameState *Game, ch *character.Character) (int, erro
 	if ch.Erased() {
 		return -1, nil
 	}
-	var event *data.Event
-	for _, e := range m.CurrentRoom().Events {
-		if e.ID() == ch.EventID() {
-			event = e
-			break
-		}
-	}
+	event := m.eventData(ch.EventID())
 	if event == nil {
 		// This can happen when the player resumes the game and
 		// the event was deleted by the game editor.
@@ -294,7 +469,7 @@ func (m *Map) calcPageIndex(gameState *Game, ch *character.Character) (int, erro
 	}
 	for i := len(event.Pages()) - 1; i >= 0; i-- {
 		page := event.Pages()[i]
-		m, err := m.meetsPageCondition(gameState, page, event.ID())
+		m, err := m.meetsPageCondition(gameState, page, ch.EventID())
 		if err != nil {
 			return 0, err
 		}
@@ -310,14 +485,158 @@ func (m *Map) currentPage(event *character.Character) (*data.Page, int) {
 	if i == -1 {
 		return nil, 0
 	}
-	for _, e := range m.CurrentRoom().Events {
-		if e.ID() == event.EventID() {
-			return e.Pages()[i], i
-		}
+	if e := m.eventData(event.EventID()); e != nil {
+		return e.Pages()[i], i
 	}
 	panic(fmt.Sprintf("gamescene: no valid page was found"))
 }
 
+// pageTree returns the location of the event page for coverage, or an empty string when the coverage is not recorded.
+// For a spawned event, pageTree returns the location of the source event page.
+func (m *Map) pageTree(eventID, pageIndex int) string {
+	if !coverage.IsRecording() {
+		return ""
+	}
+	roomID := m.roomID
+	for _, s := range m.spawnedEvents {
+		if s.id != eventID {
+			continue
+		}
+		roomID = s.sourceRoomID
+		eventID = s.sourceEventID
+		break
+	}
+	return coverage.EventPageTree(m.mapID, roomID, eventID, pageIndex)
+}
+
+func (m *Map) pageCommandsPath(eventID, pageIndex int) string {
+	t := m.pageTree(eventID, pageIndex)
+	if t == "" {
+		return ""
+	}
+	return coverage.CommandsPath(t)
+}
+
+func (m *Map) pageRouteCommandsPath(eventID, pageIndex int) string {
+	t := m.pageTree(eventID, pageIndex)
+	if t == "" {
+		return ""
+	}
+	return coverage.RouteCommandsPath(t)
+}
+
+// eventData returns the event data of the event in the current room.
+// For a spawned event, eventData returns the source event data.
+func (m *Map) eventData(eventID int) *data.Event {
+	room := m.CurrentRoom()
+	for _, s := range m.spawnedEvents {
+		if s.id != eventID {
+			continue
+		}
+		room = m.room(s.sourceRoomID)
+		eventID = s.sourceEventID
+		break
+	}
+	if room == nil {
+		return nil
+	}
+	for _, e := range room.Events {
+		if e.ID() == eventID {
+			return e
+		}
+	}
+	return nil
+}
+
+// spawnEvent creates a copy of the event at (x, y) and returns the new event ID.
+// roomID is the room that has the source event. 0 means the current room.
+func (m *Map) spawnEvent(gameState *Game, roomID, eventID int, x, y int) (int, error) {
+	if roomID == 0 {
+		roomID = m.roomID
+	}
+	if roomID == m.roomID {
+		// Spawning a copy of a spawned event means spawning a copy of its source.
+		for _, s := range m.spawnedEvents {
+			if s.id == eventID {
+				roomID = s.sourceRoomID
+				eventID = s.sourceEventID
+				break
+			}
+		}
+	}
+	room := m.room(roomID)
+	if room == nil {
+		return 0, fmt.Errorf("gamestate: invalid room ID: %d at spawnEvent", roomID)
+	}
+	found := false
+	for _, e := range room.Events {
+		if e.ID() == eventID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("gamestate: invalid event ID: %d (room ID: %d) at spawnEvent", eventID, roomID)
+	}
+
+	id := spawnedEventIDBase
+	for _, s := range m.spawnedEvents {
+		if id <= s.id {
+			id = s.id + 1
+		}
+	}
+	gameState.variables.ClearSelfSwitches(m.mapID, m.roomID, id)
+	m.spawnedEvents = append(m.spawnedEvents, &spawnedEvent{
+		id:            id,
+		sourceRoomID:  roomID,
+		sourceEventID: eventID,
+	})
+	m.events = append(m.events, character.NewEvent(id, x, y))
+	// The page is determined at the next refreshEvents.
+	m.eventPageIndices[id] = character.PlayerEventID
+	return id, nil
+}
+
+// destroyEvent removes the spawned event. destroyEvent returns false when the event is not a spawned event.
+func (m *Map) destroyEvent(gameState *Game, eventID int) bool {
+	idx := -1
+	for i, s := range m.spawnedEvents {
+		if s.id == eventID {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return false
+	}
+	m.spawnedEvents = append(m.spawnedEvents[:idx], m.spawnedEvents[idx+1:]...)
+
+	es := []*character.Character{}
+	for _, e := range m.events {
+		if e.EventID() == eventID {
+			continue
+		}
+		es = append(es, e)
+	}
+	m.events = es
+	delete(m.eventPageIndices, eventID)
+
+	ids := []int{}
+	for id, i := range m.interpreters {
+		if i.eventID == eventID {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		delete(m.interpreters, id)
+	}
+	if m.executingEventIDByUserInput == eventID {
+		m.executingEventIDByUserInput = 0
+	}
+	gameState.variables.ClearSelfSwitches(m.mapID, m.roomID, eventID)
+	return true
+}
+
 var GoToTitle = errors.New("go to title")
 
 func (m *Map) removeNonPageRoutes(eventID int) {
@@ -430,13 +749,28 @@ func (m *Map) Update(sceneManager *scene.Manager, gameState *Game) error {
 			m.itemInterpreter = nil
 		}
 	}
+	m.updateFollowers()
 	m.player.Update()
+	for _, f := range m.followers {
+		f.Update()
+	}
 	if err := m.refreshEvents(gameState); err != nil {
 		return err
 	}
 	for _, e := range m.events {
 		e.Update()
 	}
+	m.updateEmotions()
+	m.applyTileFlags(m.player)
+	for _, e := range m.events {
+		m.applyTileFlags(e)
+	}
+	for _, f := range m.followers {
+		m.applyTileFlags(f)
+	}
+	m.tryRunDamageFloorEvent(gameState)
+	m.tryRunRegionEvents(gameState)
+	m.tryRunBumpEvents(gameState)
 	m.tryRunParallelEvent(gameState)
 	if m.IsPlayerMovingByUserInput() {
 		return nil
@@ -490,6 +824,15 @@ func (m *Map) refreshEvents(gameState *Game) error {
 	return nil
 }
 
+func (m *Map) eventByID(id int) *character.Character {
+	for _, e := range m.events {
+		if e.EventID() == id {
+			return e
+		}
+	}
+	return nil
+}
+
 func (m *Map) eventsAt(x, y int) []*character.Character {
 	es := []*character.Character{}
 	for _, e := range m.events {
@@ -501,6 +844,209 @@ func (m *Map) eventsAt(x, y int) []*character.Character {
 	return es
 }
 
+// character returns the player or the event of the given ID in the current room.
+func (m *Map) character(eventID int) *character.Character {
+	if eventID == character.PlayerEventID {
+		return m.player
+	}
+	return m.eventByID(eventID)
+}
+
+func (m *Map) showEmotion(eventID int, emotionType data.EmotionType) bool {
+	if m.character(eventID) == nil {
+		return false
+	}
+	// A new emotion replaces the character's current emotion.
+	es := []*emotion{}
+	for _, e := range m.emotions {
+		if e.eventID != eventID {
+			es = append(es, e)
+		}
+	}
+	m.emotions = append(es, &emotion{
+		eventID:     eventID,
+		emotionType: emotionType,
+	})
+	return true
+}
+
+func (m *Map) isShowingEmotion(eventID int) bool {
+	for _, e := range m.emotions {
+		if e.eventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Map) updateEmotions() {
+	es := []*emotion{}
+	for _, e := range m.emotions {
+		e.update()
+		if e.isFinished() || m.character(e.eventID) == nil {
+			continue
+		}
+		es = append(es, e)
+	}
+	m.emotions = es
+}
+
+func (m *Map) DrawEmotions(screen *ebiten.Image, offsetX, offsetY int) {
+	for _, e := range m.emotions {
+		ch := m.character(e.eventID)
+		if ch == nil {
+			continue
+		}
+		e.draw(screen, ch, offsetX, offsetY)
+	}
+}
+
+// applyTileFlags updates the character's state based on the tile flags at the character's position.
+func (m *Map) applyTileFlags(ch *character.Character) {
+	flags := m.TileFlags(ch.Position())
+	ch.SetBush(flags.Has(data.TileFlagBush))
+	ch.SetLadder(flags.Has(data.TileFlagLadder))
+}
+
+// tryRunDamageFloorEvent runs the damage floor common event when the player finishes a step on a damage floor.
+func (m *Map) tryRunDamageFloorEvent(gameState *Game) {
+	moving := m.player.IsMoving()
+	arrived := m.wasPlayerMoving && !moving
+	m.wasPlayerMoving = moving
+	if !arrived {
+		return
+	}
+	if m.player.Through() {
+		return
+	}
+	id := m.gameData.System.DamageFloorCommonEventID
+	if id == 0 {
+		return
+	}
+	if !m.TileFlags(m.player.Position()).Has(data.TileFlagDamage) {
+		return
+	}
+	m.runCommonEventInParallel(gameState, id)
+}
+
+// tryRunRegionEvents runs the leave and enter common events when the player's region changes.
+func (m *Map) tryRunRegionEvents(gameState *Game) {
+	id := m.RegionID(m.player.Position())
+	if id == m.playerRegionID {
+		return
+	}
+	prev := m.playerRegionID
+	m.playerRegionID = id
+	if r := m.regionData(prev); r != nil && r.LeaveCommonEventID != 0 {
+		m.runCommonEventInParallel(gameState, r.LeaveCommonEventID)
+	}
+	if r := m.regionData(id); r != nil && r.EnterCommonEventID != 0 {
+		m.runCommonEventInParallel(gameState, r.EnterCommonEventID)
+	}
+}
+
+func (m *Map) regionData(id int) *data.RegionData {
+	if id == 0 {
+		return nil
+	}
+	for _, r := range m.gameData.System.Regions {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (m *Map) runCommonEventInParallel(gameState *Game, id int) {
+	commands := []*data.Command{
+		{
+			Name: data.CommandNameCallCommonEvent,
+			Args: &data.CommandArgsCallCommonEvent{
+				EventID: id,
+			},
+		},
+	}
+	// The common event runs in parallel so that the player can keep walking.
+	i := NewInterpreter(gameState, m.mapID, m.roomID, character.PlayerEventID, 0, commands)
+	i.parallel = true
+	m.addInterpreter(i)
+}
+
+func (m *Map) bumpCharacter(eventID int, x, y int) {
+	m.bumps = append(m.bumps, bump{
+		eventID: eventID,
+		x:       x,
+		y:       y,
+	})
+}
+
+// tryRunBumpEvents runs the event-touch events and the collision common events for the bumps in this frame.
+func (m *Map) tryRunBumpEvents(gameState *Game) {
+	bumps := m.bumps
+	m.bumps = nil
+	for _, b := range bumps {
+		if b.eventID == character.PlayerEventID {
+			continue
+		}
+		e := m.eventByID(b.eventID)
+		if e == nil {
+			continue
+		}
+		page, pageIndex := m.currentPage(e)
+		if page == nil {
+			continue
+		}
+		if px, py := m.player.Position(); px == b.x && py == b.y {
+			if page.Trigger != data.TriggerEventTouch || len(page.Commands) == 0 {
+				continue
+			}
+			if m.IsBlockingEventExecuting() {
+				continue
+			}
+			m.abortPlayerInterpreter(gameState)
+			i := NewInterpreter(gameState, m.mapID, m.roomID, e.EventID(), pageIndex, page.Commands)
+			i.commandsPath = m.pageCommandsPath(e.EventID(), pageIndex)
+			m.addInterpreter(i)
+			continue
+		}
+		if page.CollisionCommonEventID == 0 {
+			continue
+		}
+		var other *character.Character
+		for _, o := range m.eventsAt(b.x, b.y) {
+			if o != e && !o.Through() {
+				other = o
+				break
+			}
+		}
+		if other == nil {
+			continue
+		}
+		// Don't run the collision event again while the previous one is running.
+		if id, ok := m.collisionInterpreterIDs[e.EventID()]; ok {
+			if _, ok := m.interpreters[id]; ok {
+				continue
+			}
+		}
+		commands := []*data.Command{
+			{
+				Name: data.CommandNameCallCommonEvent,
+				Args: &data.CommandArgsCallCommonEvent{
+					EventID: page.CollisionCommonEventID,
+				},
+			},
+		}
+		i := NewInterpreter(gameState, m.mapID, m.roomID, e.EventID(), pageIndex, commands)
+		i.parallel = true
+		i.collidedEventID = other.EventID()
+		m.addInterpreter(i)
+		if m.collisionInterpreterIDs == nil {
+			m.collisionInterpreterIDs = map[int]int{}
+		}
+		m.collisionInterpreterIDs[e.EventID()] = i.id
+	}
+}
+
 func (m *Map) tryRunParallelEvent(gameState *Game) {
 	for _, e := range m.events {
 		page, pageIndex := m.currentPage(e)
@@ -544,6 +1090,7 @@ func (m *Map) tryRunParallelEvent(gameState *Game) {
 		}
 
 		i := NewInterpreter(gameState, m.mapID, m.roomID, e.EventID(), pageIndex, page.Commands)
+		i.commandsPath = m.pageCommandsPath(e.EventID(), pageIndex)
 		i.parallel = true
 		m.addInterpreter(i)
 	}
@@ -563,6 +1110,7 @@ func (m *Map) tryRunAutoEvent(gameState *Game) {
 		}
 		// The event is not executed here since IsBlockingEventExecuting returns false.
 		i := NewInterpreter(gameState, m.mapID, m.roomID, e.EventID(), pageIndex, page.Commands)
+		i.commandsPath = m.pageCommandsPath(e.EventID(), pageIndex)
 		m.addInterpreter(i)
 		return
 	}
@@ -570,9 +1118,130 @@ func (m *Map) tryRunAutoEvent(gameState *Game) {
 
 func (m *Map) transferPlayerImmediately(gameState *Game, roomID, x, y int, interpreter *Interpreter) {
 	m.player.TransferImmediately(x, y)
+	for _, f := range m.followers {
+		f.TransferImmediately(x, y)
+		f.SetDir(m.player.Dir())
+	}
+	m.followerLeadX, m.followerLeadY = x, y
+	m.followerTrail = nil
 	m.setRoomID(gameState, roomID, interpreter)
 }
 
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m *Map) addFollower(imageType data.ImageType, imageName string) {
+	x, y := m.player.Position()
+	if n := len(m.followers); n > 0 {
+		x, y = m.followers[n-1].Position()
+	}
+	if imageType == "" {
+		imageType = data.ImageTypeCharacters
+	}
+	f := character.NewFollower(x, y, imageType, imageName)
+	f.SetDir(m.player.Dir())
+	m.followers = append(m.followers, f)
+}
+
+// removeFollower removes the followers with the given image.
+// An empty imageName removes all the followers.
+func (m *Map) removeFollower(imageName string) {
+	fs := []*character.Character{}
+	for _, f := range m.followers {
+		if imageName == "" || f.ImageName() == imageName {
+			continue
+		}
+		fs = append(fs, f)
+	}
+	m.followers = fs
+}
+
+func (m *Map) gatherFollowers(gather bool) {
+	m.followersGathered = gather
+}
+
+// isGatheringFollowers reports whether the followers are still walking to the player to gather.
+func (m *Map) isGatheringFollowers() bool {
+	if !m.followersGathered {
+		return false
+	}
+	px, py := m.player.Position()
+	for _, f := range m.followers {
+		if f.IsMoving() {
+			return true
+		}
+		if x, y := f.Position(); x != px || y != py {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Map) updateFollowers() {
+	px, py := m.player.Position()
+	if m.followersGathered {
+		for _, f := range m.followers {
+			if f.IsMoving() {
+				continue
+			}
+			x, y := f.Position()
+			dx, dy := sign(px-x), sign(py-y)
+			if dx != 0 && dy != 0 && !m.eightDirections() {
+				// Move horizontally first. The vertical move follows at the next step.
+				dy = 0
+			}
+			m.stepFollower(f, x+dx, y+dy)
+		}
+		// The followers are at the player when they are gathered. The trail starts again from there.
+		m.followerTrail = nil
+	} else if px != m.followerLeadX || py != m.followerLeadY {
+		// The player started a new step. Record the position the player stepped from,
+		// and each follower steps to the position the player stepped from i+1 steps ago.
+		m.followerTrail = append([]trailPoint{{x: m.followerLeadX, y: m.followerLeadY}}, m.followerTrail...)
+		if len(m.followerTrail) > len(m.followers) {
+			m.followerTrail = m.followerTrail[:len(m.followers)]
+		}
+		for i, p := range m.followerTrail {
+			m.stepFollower(m.followers[i], p.x, p.y)
+		}
+	}
+	m.followerLeadX, m.followerLeadY = px, py
+}
+
+// stepFollower moves the follower to (x, y) regardless of the passability.
+// If (x, y) is not reachable by one step, e.g. when the player jumped, the follower jumps there.
+func (m *Map) stepFollower(follower *character.Character, x, y int) {
+	fx, fy := follower.Position()
+	dx, dy := x-fx, y-fy
+	if dx == 0 && dy == 0 {
+		return
+	}
+	if follower.IsMoving() {
+		follower.TransferImmediately(fx, fy)
+	}
+	follower.SetSpeed(m.player.Speed())
+	if abs(dx) > 1 || abs(dy) > 1 || (dx != 0 && dy != 0 && !m.eightDirections()) {
+		follower.Jump(dx, dy, 0, 0)
+		return
+	}
+	follower.Move(data.DirFromDelta(dx, dy))
+}
+
 func (m *Map) currentMap() *data.Map {
 	for _, d := range m.gameData.Maps {
 		if d.ID() == m.mapID {
@@ -583,8 +1252,12 @@ func (m *Map) currentMap() *data.Map {
 }
 
 func (m *Map) CurrentRoom() *data.Room {
+	return m.room(m.roomID)
+}
+
+func (m *Map) room(roomID int) *data.Room {
 	for _, r := range m.currentMap().Rooms() {
-		if r.ID == m.roomID {
+		if r.ID == roomID {
 			return r
 		}
 	}
@@ -603,15 +1276,46 @@ func (m *Map) FocusingCharacter() *character.Character {
 	return m.player
 }
 
+// TilesVersion returns a number that changes when the tiles in the current room might change,
+// e.g. by a transfer or the set_tile command.
+func (m *Map) TilesVersion() int {
+	return m.tilesVersion
+}
+
+// tileOverrides returns the tiles changed at runtime in the current room. tileOverrides can return nil.
+func (m *Map) tileOverrides() *tileOverrides {
+	if m.game == nil {
+		return nil
+	}
+	return m.game.roomTileOverrides(m.mapID, m.roomID)
+}
+
+// Tile returns the tile at (x, y) on the layer in the current room.
+// If the tile is changed by the set_tile command, Tile returns the changed tile.
+func (m *Map) Tile(layer, x, y int) int {
+	tileIndex := m.CurrentRoom().TileIndex(x, y)
+	if t := m.tileOverrides(); t != nil {
+		if tile, ok := t.tile(layer, tileIndex); ok {
+			return tile
+		}
+	}
+	return m.CurrentRoom().Tiles[layer][tileIndex]
+}
+
 func (m *Map) passableTile(x, y int) bool {
-	tileIndex := tileset.TileIndex(x, y)
+	tileIndex := m.CurrentRoom().TileIndex(x, y)
+	if t := m.tileOverrides(); t != nil {
+		if p, ok := t.passageType(tileIndex); ok {
+			return p != data.PassageTypeBlock
+		}
+	}
 	passageTypeOverrides := m.CurrentRoom().PassageTypeOverrides
 	if passageTypeOverrides != nil && passageTypeOverrides[tileIndex] == data.PassageTypeBlock {
 		return false
 	}
 
 	for layer := 0; layer < 4; layer++ {
-		tile := m.CurrentRoom().Tiles[layer][tileIndex]
+		tile := m.Tile(layer, x, y)
 		if tile == 0 {
 			continue
 		}
@@ -631,6 +1335,101 @@ func (m *Map) passableTile(x, y int) bool {
 	return true
 }
 
+// moveCost returns the cost to enter the tile for path finding.
+// The cost of the tile is the maximum of the costs of the layers.
+func (m *Map) moveCost(x, y int) int {
+	cost := 1
+	for layer := 0; layer < 4; layer++ {
+		tile := m.Tile(layer, x, y)
+		if tile == 0 {
+			continue
+		}
+		imageName, index := m.tileMetadataIndex(tile)
+		if c := tileset.MoveCost(imageName, index); cost < c {
+			cost = c
+		}
+	}
+	return cost
+}
+
+// tileMetadataIndex returns the tileset image name and the index in the image's metadata for the tile.
+func (m *Map) tileMetadataIndex(tile int) (string, int) {
+	imageID := tileset.ExtractImageID(tile)
+	imageName := m.FindImageName(imageID)
+	index := 0
+	if !tileset.IsAutoTile(imageName) {
+		x, y := tileset.DecodeTile(tile)
+		index = tileset.TileIndex(x, y)
+	}
+	return imageName, index
+}
+
+// enterBlocks returns the sides from which the tile at (x, y) cannot be entered.
+// The sides are the union of the layers'.
+func (m *Map) enterBlocks(x, y int) data.DirMask {
+	var blocks data.DirMask
+	for layer := 0; layer < 4; layer++ {
+		tile := m.Tile(layer, x, y)
+		if tile == 0 {
+			continue
+		}
+		blocks |= tileset.EnterBlocks(m.tileMetadataIndex(tile))
+	}
+	return blocks
+}
+
+// TileFlags returns the flags of the tile at (x, y). The flags are the union of the layers'.
+func (m *Map) TileFlags(x, y int) data.TileFlag {
+	if w, h := m.CurrentRoom().Size(); x < 0 || y < 0 || w <= x || h <= y {
+		return 0
+	}
+	var flags data.TileFlag
+	for layer := 0; layer < 4; layer++ {
+		tile := m.Tile(layer, x, y)
+		if tile == 0 {
+			continue
+		}
+		flags |= tileset.Flags(m.tileMetadataIndex(tile))
+	}
+	return flags
+}
+
+// RegionID returns the region ID painted at (x, y). 0 means no region.
+func (m *Map) RegionID(x, y int) int {
+	room := m.CurrentRoom()
+	if w, h := room.Size(); x < 0 || y < 0 || w <= x || h <= y {
+		return 0
+	}
+	i := room.TileIndex(x, y)
+	if i >= len(room.Regions) {
+		return 0
+	}
+	return room.Regions[i]
+}
+
+// PlayerRegionID returns the region ID where the player is.
+func (m *Map) PlayerRegionID() int {
+	return m.RegionID(m.player.Position())
+}
+
+// TerrainTag returns the terrain tag of the tile at (x, y).
+// The tag of the uppermost layer that has a non-zero tag is used. 0 means no tag.
+func (m *Map) TerrainTag(x, y int) int {
+	if w, h := m.CurrentRoom().Size(); x < 0 || y < 0 || w <= x || h <= y {
+		return 0
+	}
+	for layer := 3; layer >= 0; layer-- {
+		tile := m.Tile(layer, x, y)
+		if tile == 0 {
+			continue
+		}
+		if t := tileset.TerrainTag(m.tileMetadataIndex(tile)); t != 0 {
+			return t
+		}
+	}
+	return 0
+}
+
 func (m *Map) Passable(through bool, x, y int, ignoreCharacters bool) bool {
 	if x < 0 {
 		return false
@@ -638,10 +1437,11 @@ func (m *Map) Passable(through bool, x, y int, ignoreCharacters bool) bool {
 	if y < 0 {
 		return false
 	}
-	if consts.TileXNum <= x {
+	w, h := m.CurrentRoom().Size()
+	if w <= x {
 		return false
 	}
-	if consts.TileYNum <= y {
+	if h <= y {
 		return false
 	}
 	if through {
@@ -669,6 +1469,57 @@ func (m *Map) Passable(through bool, x, y int, ignoreCharacters bool) bool {
 	return true
 }
 
+// PassableDir reports whether a character at (x, y) can move one step in dir.
+//
+// The destination tile must be passable and must not block entering from the side.
+// A diagonal move is allowed only when the both orthogonal routes to the destination are passable.
+func (m *Map) PassableDir(through bool, x, y int, dir data.Dir, ignoreCharacters bool) bool {
+	dx, dy := dir.Delta()
+	if dir.IsDiagonal() {
+		h := data.DirFromDelta(dx, 0)
+		v := data.DirFromDelta(0, dy)
+		return m.PassableDir(through, x, y, h, ignoreCharacters) &&
+			m.PassableDir(through, x+dx, y, v, ignoreCharacters) &&
+			m.PassableDir(through, x, y, v, ignoreCharacters) &&
+			m.PassableDir(through, x, y+dy, h, ignoreCharacters)
+	}
+	if !m.Passable(through, x+dx, y+dy, ignoreCharacters) {
+		return false
+	}
+	if through {
+		return true
+	}
+	return !m.enterBlocks(x+dx, y+dy).Has(dir.Opposite())
+}
+
+func (m *Map) eightDirections() bool {
+	return m.gameData != nil && m.gameData.System.EightDirections
+}
+
+// counterDir returns the direction from the event at (x, y) to a counter tile, across which the player can
+// interact with the event.
+// If there are multiple counters, counterDir returns the one closest to the player.
+func (m *Map) counterDir(x, y int) (data.Dir, bool) {
+	px, py := m.player.Position()
+	dir := data.DirNone
+	dist := 0
+	for _, d := range []data.Dir{data.DirUp, data.DirRight, data.DirDown, data.DirLeft} {
+		dx, dy := d.Delta()
+		if !m.TileFlags(x+dx, y+dy).Has(data.TileFlagCounter) {
+			continue
+		}
+		sx, sy := x+2*dx, y+2*dy
+		if (sx != px || sy != py) && !m.Passable(m.player.Through(), sx, sy, false) {
+			continue
+		}
+		if l := abs(sx-px) + abs(sy-py); dir == data.DirNone || l < dist {
+			dir = d
+			dist = l
+		}
+	}
+	return dir, dir != data.DirNone
+}
+
 func (m *Map) SetPressedPosition(x, y int) {
 	m.pressedMapX = x
 	m.pressedMapY = y
@@ -715,6 +1566,7 @@ func (m *Map) TryRunDirectEvent(gameState *Game, x, y int) bool {
 		}
 		m.abortPlayerInterpreter(gameState)
 		i := NewInterpreter(gameState, m.mapID, m.roomID, e.EventID(), pageIndex, page.Commands)
+		i.commandsPath = m.pageCommandsPath(e.EventID(), pageIndex)
 		m.addInterpreter(i)
 		return true
 	}
@@ -758,10 +1610,30 @@ func (m *Map) TryMovePlayerByUserInput(sceneManager *scene.Manager, gameState *G
 		return false
 	}
 	px, py := m.player.Position()
-	path, lastPlayerX, lastPlayerY := pathpkg.Calc(&passableOnMap{
+	goalX, goalY := x, y
+	counterDir := data.DirNone
+	if event != nil {
+		if d, ok := m.counterDir(x, y); ok {
+			// The event is across a counter. The player walks to the other side of the counter.
+			dx, dy := d.Delta()
+			goalX, goalY = x+2*dx, y+2*dy
+			counterDir = d
+		}
+	}
+	calc := pathpkg.CalcAStar
+	if m.eightDirections() {
+		calc = pathpkg.CalcAStarWithDiagonals
+	}
+	path, lastPlayerX, lastPlayerY := calc(&passableOnMap{
 		through: m.player.Through(),
 		m:       m,
-	}, px, py, x, y, false)
+	}, px, py, goalX, goalY, false, maxPathNodes)
+	if counterDir != data.DirNone {
+		if lastPlayerX != goalX || lastPlayerY != goalY {
+			return false
+		}
+		path = append(path, pathpkg.TurnRouteCommand(counterDir.Opposite()))
+	}
 	if len(path) == 0 {
 		return false
 	}
@@ -1088,20 +1960,12 @@ func (m *Map) TryMovePlayerByUserInput(sceneManager *scene.Manager, gameState *G
 			var dir data.Dir
 			ex, ey := event.Position()
 			px, py := lastPlayerX, lastPlayerY
-			switch {
-			case ex == px && ey == py:
+			if ex == px && ey == py {
 				// The player and the event are at the same position.
 				dir = event.Dir()
-			case ex > px && ey == py:
-				dir = data.DirLeft
-			case ex < px && ey == py:
-				dir = data.DirRight
-			case ex == px && ey > py:
-				dir = data.DirUp
-			case ex == px && ey < py:
-				dir = data.DirDown
-			default:
-				panic(fmt.Sprintf("gamestate: invalid positions: event: (%d, %d), player: (%d, %d)", ex, ey, px, py))
+			} else {
+				// The player can be diagonally adjacent when the eight directions are enabled.
+				dir = data.DirFromDelta(px-ex, py-ey)
 			}
 			if !event.DirFix() {
 				commands = append(commands,
@@ -1166,6 +2030,13 @@ func (m *Map) TryMovePlayerByUserInput(sceneManager *scene.Manager, gameState *G
 }
 
 func (m *Map) DrawCharacters(screen *ebiten.Image, priority data.Priority, offsetX, offsetY int) {
+	for _, c := range m.SortedCharacters(priority) {
+		c.Draw(screen, offsetX, offsetY)
+	}
+}
+
+// SortedCharacters returns the characters at the priority in the order to draw.
+func (m *Map) SortedCharacters(priority data.Priority) []*character.Character {
 	chars := []*character.Character{}
 	for _, e := range m.events {
 		page, _ := m.currentPage(e)
@@ -1179,18 +2050,30 @@ func (m *Map) DrawCharacters(screen *ebiten.Image, priority data.Priority, offse
 	}
 	if priority == data.PriorityMiddle {
 		chars = append(chars, m.player)
+		px, py := m.player.DrawFootPosition()
+		for _, f := range m.followers {
+			// A follower overlapping with the player, e.g. a gathered follower, is hidden.
+			if x, y := f.DrawFootPosition(); x == px && y == py {
+				continue
+			}
+			chars = append(chars, f)
+		}
 	}
 	sort.Slice(chars, func(i, j int) bool {
-		_, yi := chars[i].DrawFootPosition()
-		_, yj := chars[j].DrawFootPosition()
+		yi := chars[i].DrawOrderY()
+		yj := chars[j].DrawOrderY()
 		if yi == yj {
+			// The followers are drawn behind the player and the events.
+			fi := chars[i].EventID() == character.FollowerEventID
+			fj := chars[j].EventID() == character.FollowerEventID
+			if fi != fj {
+				return fi
+			}
 			return chars[j].EventID() < chars[i].EventID()
 		}
 		return yi < yj
 	})
-	for _, c := range chars {
-		c.Draw(screen, offsetX, offsetY)
-	}
+	return chars
 }
 
 func (m *Map) StartItemCommands(gameState *Game, itemID int) {
@@ -1211,6 +2094,7 @@ func (m *Map) StartItemCommands(gameState *Game, itemID int) {
 		return
 	}
 	m.itemInterpreter = NewInterpreter(gameState, m.mapID, m.roomID, 0, 0, item.Commands)
+	m.itemInterpreter.commandsPath = commandsPathForCoverage(coverage.ItemTree(item.ID))
 }
 
 func (m *Map) StartCombineCommands(gameState *Game, combine *data.Combine) {
@@ -1221,6 +2105,7 @@ func (m *Map) StartCombineCommands(gameState *Game, combine *data.Combine) {
 		return
 	}
 	m.itemInterpreter = NewInterpreter(gameState, m.mapID, m.roomID, 0, 0, combine.Commands)
+	m.itemInterpreter.commandsPath = commandsPathForCoverage(coverage.CombineTree(combine.ID))
 }
 
 func (m *Map) Background(gameState *Game) string {
@@ -1236,3 +2121,11 @@ func (m *Map) Foreground(gameState *Game) string {
 	}
 	return m.CurrentRoom().Foreground.Name
 }
+
+// Layers returns the image layers of the current room in the order to draw.
+func (m *Map) Layers(gameState *Game) []*data.MapLayer {
+	if ls, ok := gameState.MapLayers(m.mapID, m.roomID); ok {
+		return ls
+	}
+	return m.CurrentRoom().Layers
+}
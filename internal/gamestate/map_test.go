@@ -0,0 +1,1327 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/gamestate"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
+)
+
+const testMapID = 1
+
+// testTileSetNum is the number of the tile sets in test games.
+// The tile set of ID i is named tiles<i>. A tile of value i is the top-left tile of the tile set of ID i.
+const testTileSetNum = 3
+
+// setTestTileMetadata sets the metadata of the tile sets. metadata is indexed by tile set IDs.
+func setTestTileMetadata(t *testing.T, metadata map[int]*data.AssetMetadata) {
+	m := map[string]*data.AssetMetadata{}
+	for i := 1; i <= testTileSetNum; i++ {
+		md := metadata[i]
+		if md == nil {
+			md = &data.AssetMetadata{}
+		}
+		m[fmt.Sprintf("images/tiles%d_metadata.json", i)] = md
+	}
+	if err := assets.Set(nil, m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testRoom is a room for tests.
+// The events are separated from data.Room since data.Event can be created only by decoding.
+type testRoom struct {
+	*data.Room
+	events []*data.EventImpl
+}
+
+func newTestRoom(id int) *testRoom {
+	const w, h = 10, 10
+	tiles := make([][]int, 4)
+	for i := range tiles {
+		tiles[i] = make([]int, w*h)
+	}
+	return &testRoom{
+		Room: &data.Room{
+			ID:     id,
+			Tiles:  tiles,
+			Width:  w,
+			Height: h,
+		},
+	}
+}
+
+func newTestMap(t *testing.T, rooms []*testRoom) *data.Map {
+	var rs []map[string]interface{}
+	for _, r := range rooms {
+		b, err := msgpack.Marshal(r.Room)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var room map[string]interface{}
+		if err := msgpack.Unmarshal(b, &room); err != nil {
+			t.Fatal(err)
+		}
+		room["events"] = r.events
+		rs = append(rs, room)
+	}
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"id":    testMapID,
+		"rooms": rs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &data.Map{}
+	if err := m.UnmarshalMsgpack(b); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// newTestGameData returns a new game data on the given rooms.
+// The player starts at (5, 5) in the first room.
+func newTestGameData(t *testing.T, system *data.System, rooms ...*testRoom) *data.Game {
+	if system == nil {
+		system = &data.System{}
+	}
+	system.InitialPlayerState = &data.InitialPlayerState{
+		MapID:  testMapID,
+		RoomID: rooms[0].ID,
+		X:      5,
+		Y:      5,
+	}
+	var tileSets []*data.TileSet
+	for i := 1; i <= testTileSetNum; i++ {
+		tileSets = append(tileSets, &data.TileSet{
+			ID:   i,
+			Name: fmt.Sprintf("tiles%d", i),
+		})
+	}
+	setTestTileMetadata(t, nil)
+	return &data.Game{
+		Maps:     []*data.Map{newTestMap(t, rooms)},
+		Texts:    &data.Texts{},
+		TileSets: tileSets,
+		System:   system,
+	}
+}
+
+// startTestGame returns a new game on the given game data after the first update.
+func startTestGame(t *testing.T, gameData *data.Game) (*Game, *scene.Manager) {
+	return startTestGameWithRequester(t, gameData, nil)
+}
+
+// startTestGameWithRequester is like startTestGame, but the scene manager uses the given requester.
+func startTestGameWithRequester(t *testing.T, gameData *data.Game, requester scene.Requester) (*Game, *scene.Manager) {
+	sceneManager := scene.NewManager(480, 720, requester, gameData, nil, nil, nil, 0)
+	g := NewGame(gameData.System)
+	if err := g.Update(sceneManager); err != nil {
+		t.Fatal(err)
+	}
+	return g, sceneManager
+}
+
+// newTestGame returns a new game on the given rooms after the first update.
+// The player starts at (5, 5) in the first room.
+func newTestGame(t *testing.T, system *data.System, rooms ...*testRoom) (*Game, *scene.Manager) {
+	return startTestGame(t, newTestGameData(t, system, rooms...))
+}
+
+func updateGame(t *testing.T, g *Game, sceneManager *scene.Manager) {
+	if err := g.Update(sceneManager); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func player(g *Game) *character.Character {
+	return g.Character(0, 0, character.PlayerEventID)
+}
+
+// movePlayer moves the player by one step and waits until all the characters stop.
+func movePlayer(t *testing.T, g *Game, sceneManager *scene.Manager, dir data.Dir) {
+	player(g).Move(dir)
+	waitForCharacters(t, g, sceneManager)
+}
+
+func waitForCharacters(t *testing.T, g *Game, sceneManager *scene.Manager) {
+	for i := 0; i < 120; i++ {
+		updateGame(t, g, sceneManager)
+		moving := player(g).IsMoving()
+		for _, f := range g.FollowersForTesting() {
+			if f.IsMoving() {
+				moving = true
+			}
+		}
+		if !moving {
+			return
+		}
+	}
+	t.Fatalf("the characters didn't stop")
+}
+
+type position struct {
+	x, y int
+}
+
+func followerPositions(g *Game) []position {
+	var ps []position
+	for _, f := range g.FollowersForTesting() {
+		x, y := f.Position()
+		ps = append(ps, position{x, y})
+	}
+	return ps
+}
+
+func samePositions(a, b []position) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFollowersTrailPlayer(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1))
+	g.AddFollower(data.ImageTypeIcons, "follower1")
+	g.AddFollower(data.ImageTypeIcons, "follower2")
+
+	dirs := []data.Dir{data.DirRight, data.DirDown, data.DirRight, data.DirDown, data.DirLeft}
+	want := [][]position{
+		{{5, 5}, {5, 5}},
+		{{6, 5}, {5, 5}},
+		{{6, 6}, {6, 5}},
+		{{7, 6}, {6, 6}},
+		{{7, 7}, {7, 6}},
+	}
+	for i, d := range dirs {
+		prev := followerPositions(g)
+		movePlayer(t, g, sceneManager, d)
+		got := followerPositions(g)
+		if !samePositions(got, want[i]) {
+			t.Errorf("step %d: follower positions: got: %v, want: %v", i, got, want[i])
+		}
+		// In the four-direction mode, a follower never moves diagonally.
+		for j := range got {
+			if got[j].x != prev[j].x && got[j].y != prev[j].y {
+				t.Errorf("step %d: follower %d moved diagonally from %v to %v", i, j, prev[j], got[j])
+			}
+		}
+	}
+
+	// The trail survives saving.
+	g2 := marshalAndUnmarshalGame(t, g)
+	updateGame(t, g2, sceneManager)
+	movePlayer(t, g2, sceneManager, data.DirUp)
+	if got, want := followerPositions(g2), []position{{6, 7}, {7, 7}}; !samePositions(got, want) {
+		t.Errorf("follower positions after marshaling: got: %v, want: %v", got, want)
+	}
+}
+
+func TestFollowersAfterJump(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1))
+	g.AddFollower(data.ImageTypeIcons, "follower1")
+	g.AddFollower(data.ImageTypeIcons, "follower2")
+	movePlayer(t, g, sceneManager, data.DirRight)
+
+	player(g).Jump(1, 1, 0, 0)
+	waitForCharacters(t, g, sceneManager)
+	if got, want := followerPositions(g), []position{{6, 5}, {5, 5}}; !samePositions(got, want) {
+		t.Errorf("follower positions after the jump: got: %v, want: %v", got, want)
+	}
+
+	// The first follower reaches the position where the player landed even though it is diagonal.
+	movePlayer(t, g, sceneManager, data.DirRight)
+	if got, want := followerPositions(g), []position{{7, 6}, {6, 5}}; !samePositions(got, want) {
+		t.Errorf("follower positions after the step: got: %v, want: %v", got, want)
+	}
+}
+
+func TestGatherFollowers(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1))
+	g.AddFollower(data.ImageTypeIcons, "follower1")
+	g.AddFollower(data.ImageTypeIcons, "follower2")
+	for _, d := range []data.Dir{data.DirRight, data.DirRight, data.DirDown} {
+		movePlayer(t, g, sceneManager, d)
+	}
+
+	g.GatherFollowers(true)
+	for i := 0; g.IsGatheringFollowers(); i++ {
+		if i >= 600 {
+			t.Fatalf("the followers didn't gather")
+		}
+		updateGame(t, g, sceneManager)
+	}
+	if got, want := followerPositions(g), []position{{7, 6}, {7, 6}}; !samePositions(got, want) {
+		t.Errorf("follower positions after gathering: got: %v, want: %v", got, want)
+	}
+
+	// The gathered followers stay with the player.
+	movePlayer(t, g, sceneManager, data.DirLeft)
+	movePlayer(t, g, sceneManager, data.DirLeft)
+	for g.IsGatheringFollowers() {
+		updateGame(t, g, sceneManager)
+	}
+	if got, want := followerPositions(g), []position{{5, 6}, {5, 6}}; !samePositions(got, want) {
+		t.Errorf("follower positions while gathered: got: %v, want: %v", got, want)
+	}
+
+	// The followers trail the player again from the player's position.
+	g.GatherFollowers(false)
+	movePlayer(t, g, sceneManager, data.DirUp)
+	movePlayer(t, g, sceneManager, data.DirUp)
+	if got, want := followerPositions(g), []position{{5, 5}, {5, 6}}; !samePositions(got, want) {
+		t.Errorf("follower positions after dispersing: got: %v, want: %v", got, want)
+	}
+}
+
+func TestFollowersAcrossTransfer(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1), newTestRoom(2))
+	g.AddFollower(data.ImageTypeIcons, "follower1")
+	g.AddFollower(data.ImageTypeIcons, "follower2")
+	for _, d := range []data.Dir{data.DirRight, data.DirRight, data.DirRight} {
+		movePlayer(t, g, sceneManager, d)
+	}
+
+	g.TransferPlayerImmediately(2, 1, 1, nil)
+	if got, want := followerPositions(g), []position{{1, 1}, {1, 1}}; !samePositions(got, want) {
+		t.Errorf("follower positions after the transfer: got: %v, want: %v", got, want)
+	}
+
+	// The trail in the previous room is not followed.
+	movePlayer(t, g, sceneManager, data.DirDown)
+	if got, want := followerPositions(g), []position{{1, 1}, {1, 1}}; !samePositions(got, want) {
+		t.Errorf("follower positions after one step: got: %v, want: %v", got, want)
+	}
+	movePlayer(t, g, sceneManager, data.DirDown)
+	if got, want := followerPositions(g), []position{{1, 2}, {1, 1}}; !samePositions(got, want) {
+		t.Errorf("follower positions after two steps: got: %v, want: %v", got, want)
+	}
+}
+
+func newTestRoomWithEvent(id int) *testRoom {
+	r := newTestRoom(id)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerPlayer,
+					Priority: data.PriorityMiddle,
+				},
+			},
+		},
+	}
+	return r
+}
+
+func TestSpawnAndDestroyEvent(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoomWithEvent(1))
+
+	id1, err := g.SpawnEvent(0, 1, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Spawning a copy of a spawned event spawns a copy of its source.
+	id2, err := g.SpawnEvent(0, id1, 6, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id1 == id2 {
+		t.Errorf("spawned event IDs must be unique: %d", id1)
+	}
+	if _, err := g.SpawnEvent(0, 2, 0, 0); err == nil {
+		t.Errorf("SpawnEvent with an invalid event ID must return an error")
+	}
+	updateGame(t, g, sceneManager)
+
+	for _, c := range []struct {
+		id   int
+		x, y int
+	}{
+		{id1, 3, 4},
+		{id2, 6, 7},
+	} {
+		ch := g.Character(testMapID, 1, c.id)
+		if ch == nil {
+			t.Fatalf("Character(%d): got: nil", c.id)
+		}
+		if x, y := ch.Position(); x != c.x || y != c.y {
+			t.Errorf("Character(%d).Position(): got: (%d, %d), want: (%d, %d)", c.id, x, y, c.x, c.y)
+		}
+		if e := g.EventData(c.id); e == nil || e.ID() != 1 {
+			t.Errorf("EventData(%d) must be the source event", c.id)
+		}
+	}
+
+	if g.DestroyEvent(1) {
+		t.Errorf("DestroyEvent for an event in the editor: got: true, want: false")
+	}
+	if g.Character(testMapID, 1, 1) == nil {
+		t.Errorf("the event in the editor must not be destroyed")
+	}
+	if !g.DestroyEvent(id1) {
+		t.Errorf("DestroyEvent(%d): got: false, want: true", id1)
+	}
+	if g.Character(testMapID, 1, id1) != nil {
+		t.Errorf("Character(%d) after DestroyEvent: got: non-nil, want: nil", id1)
+	}
+	if g.EventData(id1) != nil {
+		t.Errorf("EventData(%d) after DestroyEvent: got: non-nil, want: nil", id1)
+	}
+	if g.DestroyEvent(id1) {
+		t.Errorf("DestroyEvent(%d) twice: got: true, want: false", id1)
+	}
+}
+
+func TestSpawnedEventSelfSwitch(t *testing.T) {
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Dir:      data.DirDown,
+					Trigger:  data.TriggerNever,
+					Priority: data.PriorityMiddle,
+				},
+				{
+					Conditions: []*data.Condition{
+						{
+							Type:  data.ConditionTypeSelfSwitch,
+							ID:    0,
+							Value: true,
+						},
+					},
+					Dir:      data.DirUp,
+					Trigger:  data.TriggerNever,
+					Priority: data.PriorityMiddle,
+				},
+			},
+		},
+	}
+	g, sceneManager := newTestGame(t, nil, r)
+	id, err := g.SpawnEvent(0, 1, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateGame(t, g, sceneManager)
+
+	// The spawned copy switches its page by its own self switch.
+	g.SetSelfSwitchValue(id, 0, true)
+	updateGame(t, g, sceneManager)
+	if got, want := g.Character(testMapID, 1, id).Dir(), data.DirUp; got != want {
+		t.Errorf("the spawned event's dir: got: %d, want: %d", got, want)
+	}
+	if got, want := g.Character(testMapID, 1, 1).Dir(), data.DirDown; got != want {
+		t.Errorf("the source event's dir: got: %d, want: %d", got, want)
+	}
+}
+
+func TestSpawnedEventsAfterMarshal(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoomWithEvent(1))
+	id, err := g.SpawnEvent(0, 1, 3, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateGame(t, g, sceneManager)
+
+	g2 := marshalAndUnmarshalGame(t, g)
+	updateGame(t, g2, sceneManager)
+	ch := g2.Character(testMapID, 1, id)
+	if ch == nil {
+		t.Fatalf("Character(%d) after marshaling: got: nil", id)
+	}
+	if x, y := ch.Position(); x != 3 || y != 4 {
+		t.Errorf("Character(%d).Position() after marshaling: got: (%d, %d), want: (3, 4)", id, x, y)
+	}
+	if e := g2.EventData(id); e == nil || e.ID() != 1 {
+		t.Errorf("EventData(%d) after marshaling must be the source event", id)
+	}
+	// A new spawned event doesn't reuse the ID.
+	id2, err := g2.SpawnEvent(0, 1, 5, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id2 == id {
+		t.Errorf("a spawned event ID after marshaling must be unique: %d", id)
+	}
+
+	// Spawned events are removed by a transfer even to the same room.
+	g2.TransferPlayerImmediately(1, 5, 5, nil)
+	if g2.Character(testMapID, 1, id) != nil {
+		t.Errorf("Character(%d) after the transfer: got: non-nil, want: nil", id)
+	}
+	if g2.Character(testMapID, 1, 1) == nil {
+		t.Errorf("the event in the editor must survive the transfer")
+	}
+}
+
+func TestTileOverridesAfterMarshal(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1), newTestRoom(2))
+
+	block := data.PassageTypeBlock
+	if err := g.SetTile(testMapID, 1, 1, 2, 3, 1, &block); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetTile(testMapID, 1, 2, 4, 3, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	// The tiles in another room are kept separately.
+	if err := g.SetTile(testMapID, 2, 1, 2, 3, 3, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.SetTile(testMapID, 1, 4, 2, 3, 1, nil); err == nil {
+		t.Errorf("SetTile with an invalid layer must return an error")
+	}
+	if err := g.SetTile(testMapID, 1, 1, 10, 3, 1, nil); err == nil {
+		t.Errorf("SetTile with an invalid position must return an error")
+	}
+
+	g2 := marshalAndUnmarshalGame(t, g)
+	updateGame(t, g2, sceneManager)
+
+	for _, c := range []struct {
+		layer, x, y int
+		want        int
+	}{
+		{1, 2, 3, 1},
+		{2, 4, 3, 2},
+		{0, 2, 3, 0},
+		{1, 4, 3, 0},
+	} {
+		if got := g2.Map().Tile(c.layer, c.x, c.y); got != c.want {
+			t.Errorf("Tile(%d, %d, %d) after marshaling: got: %d, want: %d", c.layer, c.x, c.y, got, c.want)
+		}
+	}
+	if g2.Map().Passable(false, 2, 3, true) {
+		t.Errorf("Passable(false, 2, 3, true) after marshaling: got: true, want: false")
+	}
+	g2.ResetPassageType(testMapID, 1, 2, 3)
+	if !g2.Map().Passable(false, 2, 3, true) {
+		t.Errorf("Passable(false, 2, 3, true) after ResetPassageType: got: false, want: true")
+	}
+
+	g2.TransferPlayerImmediately(2, 5, 5, nil)
+	if got := g2.Map().Tile(1, 2, 3); got != 3 {
+		t.Errorf("Tile(1, 2, 3) in room 2: got: %d, want: 3", got)
+	}
+	if got := g2.Map().Tile(2, 4, 3); got != 0 {
+		t.Errorf("Tile(2, 4, 3) in room 2: got: %d, want: 0", got)
+	}
+}
+
+// newAddVariableCommonEvent returns a common event that adds 1 to the variable.
+func newAddVariableCommonEvent(id int, variableID int) *data.CommonEvent {
+	return &data.CommonEvent{
+		ID: id,
+		Commands: []*data.Command{
+			{
+				Name: data.CommandNameSetVariable,
+				Args: &data.CommandArgsSetVariable{
+					ID:        variableID,
+					IDType:    data.SetVariableIDTypeVal,
+					Op:        data.SetVariableOpAdd,
+					ValueType: data.SetVariableValueTypeConstant,
+					Value:     1,
+				},
+			},
+		},
+	}
+}
+
+func TestRegionAfterMarshal(t *testing.T) {
+	room := newTestRoom(1)
+	room.Regions = make([]int, room.Width*room.Height)
+	room.Regions[room.TileIndex(5, 5)] = 2
+	system := &data.System{
+		Regions: []*data.RegionData{
+			{
+				ID:                 2,
+				EnterCommonEventID: 1,
+				LeaveCommonEventID: 2,
+			},
+		},
+	}
+	gameData := newTestGameData(t, system, room)
+	gameData.CommonEvents = []*data.CommonEvent{
+		newAddVariableCommonEvent(1, 1),
+		newAddVariableCommonEvent(2, 2),
+	}
+	g, sceneManager := startTestGame(t, gameData)
+	for i := 0; i < 10; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if got := g.VariableValue(1); got != 1 {
+		t.Fatalf("VariableValue(1) after entering the region: got: %d, want: 1", got)
+	}
+
+	// The player is still in the region after loading: the enter event must not run again.
+	g2 := marshalAndUnmarshalGame(t, g)
+	for i := 0; i < 10; i++ {
+		updateGame(t, g2, sceneManager)
+	}
+	if got := g2.VariableValue(1); got != 1 {
+		t.Errorf("VariableValue(1) after loading: got: %d, want: 1", got)
+	}
+	if got := g2.VariableValue(2); got != 0 {
+		t.Errorf("VariableValue(2) after loading: got: %d, want: 0", got)
+	}
+
+	movePlayer(t, g2, sceneManager, data.DirRight)
+	for i := 0; i < 10; i++ {
+		updateGame(t, g2, sceneManager)
+	}
+	if got := g2.VariableValue(2); got != 1 {
+		t.Errorf("VariableValue(2) after leaving the region: got: %d, want: 1", got)
+	}
+}
+
+func TestMeetsConditionRegion(t *testing.T) {
+	room := newTestRoom(1)
+	room.Regions = make([]int, room.Width*room.Height)
+	room.Regions[room.TileIndex(5, 5)] = 2
+	g, sceneManager := newTestGame(t, nil, room)
+	g.SetVariableValue(1, 2)
+
+	cases := []struct {
+		Comp      data.ConditionComp
+		ValueType data.ConditionValueType
+		Value     interface{}
+		Want      bool
+		WantMoved bool
+	}{
+		{data.ConditionCompEqualTo, data.ConditionValueTypeConstant, 2, true, false},
+		{data.ConditionCompEqualTo, data.ConditionValueTypeConstant, 0, false, true},
+		{data.ConditionCompNotEqualTo, data.ConditionValueTypeConstant, 2, false, true},
+		{data.ConditionCompGreaterThan, data.ConditionValueTypeConstant, 1, true, false},
+		{data.ConditionCompEqualTo, data.ConditionValueTypeVariable, 1, true, false},
+	}
+	check := func(moved bool) {
+		for _, tc := range cases {
+			cond := &data.Condition{
+				Type:      data.ConditionTypeRegion,
+				Comp:      tc.Comp,
+				ValueType: tc.ValueType,
+				Value:     tc.Value,
+			}
+			got, err := g.MeetsCondition(cond, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tc.Want
+			if moved {
+				want = tc.WantMoved
+			}
+			if got != want {
+				t.Errorf("MeetsCondition(%s, %s, %v), moved: %t: got: %t, want: %t", tc.Comp, tc.ValueType, tc.Value, moved, got, want)
+			}
+		}
+	}
+	check(false)
+	movePlayer(t, g, sceneManager, data.DirRight)
+	check(true)
+}
+
+func TestCollidedEventID(t *testing.T) {
+	room := newTestRoom(1)
+	for _, e := range []struct {
+		id, x, y    int
+		collisionID int
+	}{
+		{1, 2, 2, 1},
+		{2, 3, 2, 0},
+		{3, 5, 2, 1},
+		{4, 6, 2, 0},
+	} {
+		room.events = append(room.events, &data.EventImpl{
+			ID: e.id,
+			X:  e.x,
+			Y:  e.y,
+			Pages: []*data.Page{
+				{
+					Trigger:                data.TriggerNever,
+					Priority:               data.PriorityMiddle,
+					Speed:                  data.Speed3,
+					CollisionCommonEventID: e.collisionID,
+				},
+			},
+		})
+	}
+	gameData := newTestGameData(t, nil, room)
+	gameData.CommonEvents = []*data.CommonEvent{
+		{
+			ID: 1,
+			Commands: []*data.Command{
+				{
+					Name: data.CommandNameSetVariable,
+					Args: &data.CommandArgsSetVariable{
+						ID:        1,
+						IDType:    data.SetVariableIDTypeVal,
+						Op:        data.SetVariableOpAdd,
+						ValueType: data.SetVariableValueTypeSystem,
+						Value:     data.SystemVariableCollidedEventID,
+					},
+				},
+			},
+		},
+	}
+	g, sceneManager := startTestGame(t, gameData)
+
+	// The two collisions in the same frame must not share the collided event ID.
+	g.BumpCharacter(testMapID, 1, 1, 3, 2)
+	g.BumpCharacter(testMapID, 1, 3, 6, 2)
+	updateGame(t, g, sceneManager)
+
+	g2 := marshalAndUnmarshalGame(t, g)
+	for i := 0; i < 10; i++ {
+		updateGame(t, g2, sceneManager)
+	}
+	if got, want := g2.VariableValue(1), int64(2+4); got != want {
+		t.Errorf("VariableValue(1): got: %d, want: %d", got, want)
+	}
+}
+
+// addVariable returns a command to add 1 to the variable.
+func addVariable(id int) *data.Command {
+	return &data.Command{
+		Name: data.CommandNameSetVariable,
+		Args: &data.CommandArgsSetVariable{
+			ID:        id,
+			IDType:    data.SetVariableIDTypeVal,
+			Op:        data.SetVariableOpAdd,
+			ValueType: data.SetVariableValueTypeConstant,
+			Value:     1,
+		},
+	}
+}
+
+// moveRightRoute returns a page route to move one step right, which is skipped when the character can't move.
+func moveRightRoute() *data.CommandArgsSetRoute {
+	return &data.CommandArgsSetRoute{
+		Skip: true,
+		Commands: []*data.Command{
+			{
+				Name: data.CommandNameMoveCharacter,
+				Args: &data.CommandArgsMoveCharacter{
+					Type:     data.MoveCharacterTypeDirection,
+					Dir:      data.DirRight,
+					Distance: 1,
+				},
+			},
+		},
+	}
+}
+
+func TestEventTouchByRoute(t *testing.T) {
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  4,
+			Y:  5,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerEventTouch,
+					Priority: data.PriorityMiddle,
+					Speed:    data.Speed3,
+					Route:    moveRightRoute(),
+					Commands: []*data.Command{
+						addVariable(1),
+					},
+				},
+			},
+		},
+	}
+	g, sceneManager := newTestGame(t, nil, r)
+	for i := 0; i < 60; i++ {
+		updateGame(t, g, sceneManager)
+	}
+
+	// The event bumps into the player at (5, 5) and runs the event-touch page exactly once.
+	if got, want := g.VariableValue(1), int64(1); got != want {
+		t.Errorf("VariableValue(1): got: %d, want: %d", got, want)
+	}
+	if x, y := g.Character(testMapID, 1, 1).Position(); x != 4 || y != 5 {
+		t.Errorf("the event's position: got: (%d, %d), want: (4, 5)", x, y)
+	}
+}
+
+func TestCollisionCommonEvent(t *testing.T) {
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:                data.TriggerNever,
+					Priority:               data.PriorityMiddle,
+					Speed:                  data.Speed3,
+					Route:                  moveRightRoute(),
+					CollisionCommonEventID: 1,
+				},
+			},
+		},
+		{
+			ID: 2,
+			X:  3,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerNever,
+					Priority: data.PriorityMiddle,
+				},
+			},
+		},
+	}
+	gameData := newTestGameData(t, nil, r)
+	gameData.CommonEvents = []*data.CommonEvent{
+		{
+			ID: 1,
+			Commands: []*data.Command{
+				addVariable(1),
+			},
+		},
+	}
+	g, sceneManager := startTestGame(t, gameData)
+	for i := 0; i < 60; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if got, want := g.VariableValue(1), int64(1); got != want {
+		t.Errorf("VariableValue(1): got: %d, want: %d", got, want)
+	}
+}
+
+func TestCollisionCommonEventAfterMarshal(t *testing.T) {
+	room := newTestRoom(1)
+	for _, e := range []struct {
+		id, x, y    int
+		collisionID int
+	}{
+		{1, 2, 2, 1},
+		{2, 3, 2, 0},
+	} {
+		room.events = append(room.events, &data.EventImpl{
+			ID: e.id,
+			X:  e.x,
+			Y:  e.y,
+			Pages: []*data.Page{
+				{
+					Trigger:                data.TriggerNever,
+					Priority:               data.PriorityMiddle,
+					CollisionCommonEventID: e.collisionID,
+				},
+			},
+		})
+	}
+	gameData := newTestGameData(t, nil, room)
+	gameData.CommonEvents = []*data.CommonEvent{
+		{
+			ID: 1,
+			Commands: []*data.Command{
+				{
+					Name: data.CommandNameWait,
+					Args: &data.CommandArgsWait{Time: 2},
+				},
+				addVariable(1),
+			},
+		},
+	}
+	g, sceneManager := startTestGame(t, gameData)
+	g.BumpCharacter(testMapID, 1, 1, 3, 2)
+	updateGame(t, g, sceneManager)
+
+	// The collision in the middle of the running collision common event doesn't run it again after loading.
+	g2 := marshalAndUnmarshalGame(t, g)
+	g2.BumpCharacter(testMapID, 1, 1, 3, 2)
+	for i := 0; i < 30; i++ {
+		updateGame(t, g2, sceneManager)
+	}
+	if got, want := g2.VariableValue(1), int64(1); got != want {
+		t.Errorf("VariableValue(1): got: %d, want: %d", got, want)
+	}
+}
+
+// setTestTile sets the tile of the tile set at (x, y) on the layer 0.
+// The tile's metadata is at the index 0 of the tile set's metadata.
+func setTestTile(r *testRoom, tileSetID, x, y int) {
+	r.Tiles[0][r.TileIndex(x, y)] = tileSetID
+}
+
+func TestPassableDirEnterBlocks(t *testing.T) {
+	r := newTestRoom(1)
+	setTestTile(r, 1, 3, 2)
+	g, _ := newTestGame(t, nil, r)
+	// The tile at (3, 2) can't be entered from the left.
+	setTestTileMetadata(t, map[int]*data.AssetMetadata{
+		1: {
+			EnterBlocks: []data.DirMask{1 << uint(data.DirLeft)},
+		},
+	})
+
+	cases := []struct {
+		X       int
+		Y       int
+		Dir     data.Dir
+		Through bool
+		Want    bool
+	}{
+		{X: 2, Y: 2, Dir: data.DirRight, Want: false},
+		{X: 2, Y: 2, Dir: data.DirRight, Through: true, Want: true},
+		{X: 4, Y: 2, Dir: data.DirLeft, Want: true},
+		{X: 3, Y: 1, Dir: data.DirDown, Want: true},
+		{X: 3, Y: 3, Dir: data.DirUp, Want: true},
+		// Leaving the tile is not blocked.
+		{X: 3, Y: 2, Dir: data.DirLeft, Want: true},
+	}
+	for _, c := range cases {
+		if got := g.MapPassableDir(c.Through, c.X, c.Y, c.Dir, true); got != c.Want {
+			t.Errorf("MapPassableDir(%t, %d, %d, %d): got: %t, want: %t", c.Through, c.X, c.Y, c.Dir, got, c.Want)
+		}
+	}
+}
+
+func TestPassableDirDiagonal(t *testing.T) {
+	// The tile set 1 is a wall, and the tile set 2 can't be entered from the left.
+	metadata := map[int]*data.AssetMetadata{
+		1: {
+			PassageTypes: []data.PassageType{data.PassageTypeBlock},
+		},
+		2: {
+			EnterBlocks: []data.DirMask{1 << uint(data.DirLeft)},
+		},
+	}
+	type tile struct {
+		TileSetID int
+		X         int
+		Y         int
+	}
+	cases := []struct {
+		Name  string
+		Tiles []tile
+		Want  bool
+	}{
+		{
+			Name: "open",
+			Want: true,
+		},
+		{
+			Name:  "horizontal route blocked",
+			Tiles: []tile{{1, 3, 2}},
+			Want:  false,
+		},
+		{
+			Name:  "vertical route blocked",
+			Tiles: []tile{{1, 2, 3}},
+			Want:  false,
+		},
+		{
+			Name:  "destination blocked from a side",
+			Tiles: []tile{{2, 3, 3}},
+			Want:  false,
+		},
+	}
+	for _, c := range cases {
+		r := newTestRoom(1)
+		for _, tl := range c.Tiles {
+			setTestTile(r, tl.TileSetID, tl.X, tl.Y)
+		}
+		g, _ := newTestGame(t, nil, r)
+		setTestTileMetadata(t, metadata)
+		if got := g.MapPassableDir(false, 2, 2, data.DirDownRight, true); got != c.Want {
+			t.Errorf("%s: MapPassableDir(false, 2, 2, DirDownRight): got: %t, want: %t", c.Name, got, c.Want)
+		}
+	}
+}
+
+func TestCounter(t *testing.T) {
+	r := newTestRoom(1)
+	// The counter is at (3, 3) between the event at (3, 2) and (3, 4).
+	// The wall at (3, 5) makes the player enter (3, 4) from the right.
+	setTestTile(r, 1, 3, 3)
+	setTestTile(r, 2, 3, 5)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  3,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerPlayer,
+					Priority: data.PriorityMiddle,
+					Commands: []*data.Command{
+						addVariable(1),
+					},
+				},
+			},
+		},
+	}
+	// The player's move by user input saves the progress.
+	g, sceneManager := startTestGameWithRequester(t, newTestGameData(t, nil, r), &saveRequester{})
+	setTestTileMetadata(t, map[int]*data.AssetMetadata{
+		1: {
+			PassageTypes: []data.PassageType{data.PassageTypeBlock},
+			TileFlags:    []data.TileFlag{data.TileFlagCounter},
+		},
+		2: {
+			PassageTypes: []data.PassageType{data.PassageTypeBlock},
+		},
+	})
+
+	if !g.Map().TryMovePlayerByUserInput(sceneManager, g, 3, 2) {
+		t.Fatalf("TryMovePlayerByUserInput: got: false, want: true")
+	}
+	for i := 0; i < 120; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	// The player walks to the other side of the counter, turns to the event and runs it.
+	if x, y := player(g).Position(); x != 3 || y != 4 {
+		t.Errorf("the player's position: got: (%d, %d), want: (3, 4)", x, y)
+	}
+	if got, want := player(g).Dir(), data.DirUp; got != want {
+		t.Errorf("the player's dir: got: %d, want: %d", got, want)
+	}
+	if got, want := g.VariableValue(1), int64(1); got != want {
+		t.Errorf("VariableValue(1): got: %d, want: %d", got, want)
+	}
+}
+
+func TestDamageFloor(t *testing.T) {
+	r := newTestRoom(1)
+	setTestTile(r, 1, 6, 5)
+	gameData := newTestGameData(t, &data.System{DamageFloorCommonEventID: 1}, r)
+	gameData.CommonEvents = []*data.CommonEvent{
+		newAddVariableCommonEvent(1, 1),
+	}
+	setTestTileMetadata(t, map[int]*data.AssetMetadata{
+		1: {
+			TileFlags: []data.TileFlag{data.TileFlagDamage},
+		},
+	})
+	g, sceneManager := startTestGame(t, gameData)
+
+	// The common event runs once when the player stops on the damage floor.
+	movePlayer(t, g, sceneManager, data.DirRight)
+	for i := 0; i < 30; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if got, want := g.VariableValue(1), int64(1); got != want {
+		t.Errorf("VariableValue(1) on the damage floor: got: %d, want: %d", got, want)
+	}
+
+	movePlayer(t, g, sceneManager, data.DirRight)
+	for i := 0; i < 30; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if got, want := g.VariableValue(1), int64(1); got != want {
+		t.Errorf("VariableValue(1) after leaving the damage floor: got: %d, want: %d", got, want)
+	}
+}
+
+func TestSetMapLayer(t *testing.T) {
+	r := newTestRoom(1)
+	r.Layers = []*data.MapLayer{
+		{ID: 1, Image: "a"},
+		{ID: 2, Image: "b"},
+	}
+	g, _ := newTestGame(t, nil, r, newTestRoom(2))
+
+	images := func(g *Game) []string {
+		ls, ok := g.MapLayers(testMapID, 1)
+		if !ok {
+			return nil
+		}
+		var images []string
+		for _, l := range ls {
+			images = append(images, fmt.Sprintf("%d:%s", l.ID, l.Image))
+		}
+		return images
+	}
+
+	if _, ok := g.MapLayers(testMapID, 1); ok {
+		t.Errorf("MapLayers before SetMapLayer: got: true, want: false")
+	}
+
+	cases := []struct {
+		Name  string
+		Layer *data.MapLayer
+		Want  []string
+	}{
+		{
+			// The first change starts from the room's layers.
+			Name:  "replace",
+			Layer: &data.MapLayer{ID: 1, Image: "c"},
+			Want:  []string{"1:c", "2:b"},
+		},
+		{
+			Name:  "append",
+			Layer: &data.MapLayer{ID: 3, Image: "d"},
+			Want:  []string{"1:c", "2:b", "3:d"},
+		},
+		{
+			Name:  "remove",
+			Layer: &data.MapLayer{ID: 2},
+			Want:  []string{"1:c", "3:d"},
+		},
+		{
+			Name:  "remove a missing layer",
+			Layer: &data.MapLayer{ID: 4},
+			Want:  []string{"1:c", "3:d"},
+		},
+	}
+	for _, c := range cases {
+		if err := g.SetMapLayer(testMapID, 1, c.Layer); err != nil {
+			t.Fatal(err)
+		}
+		if got := images(g); !reflect.DeepEqual(got, c.Want) {
+			t.Errorf("%s: MapLayers: got: %v, want: %v", c.Name, got, c.Want)
+		}
+	}
+
+	// The other room is not changed.
+	if _, ok := g.MapLayers(testMapID, 2); ok {
+		t.Errorf("MapLayers for the room 2: got: true, want: false")
+	}
+	if err := g.SetMapLayer(testMapID+1, 1, &data.MapLayer{ID: 1, Image: "e"}); err == nil {
+		t.Errorf("SetMapLayer for another map must return an error")
+	}
+
+	g2 := marshalAndUnmarshalGame(t, g)
+	if got, want := images(g2), []string{"1:c", "3:d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MapLayers after marshaling: got: %v, want: %v", got, want)
+	}
+}
+
+func TestSetTileWithInvalidArgs(t *testing.T) {
+	setTile := func(layer, x, y, tile int, passageType data.SetTilePassageType) *data.Command {
+		return &data.Command{
+			Name: data.CommandNameSetTile,
+			Args: &data.CommandArgsSetTile{
+				Layer:       layer,
+				X:           x,
+				Y:           y,
+				ValueType:   data.ValueTypeConstant,
+				Tile:        tile,
+				PassageType: passageType,
+			},
+		}
+	}
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerAuto,
+					Priority: data.PriorityMiddle,
+					Commands: []*data.Command{
+						setTile(4, 2, 3, 1, data.SetTilePassageTypeNone),
+						setTile(1, 10, 3, 1, data.SetTilePassageTypeNone),
+						setTile(1, 2, 3, 100, data.SetTilePassageTypeNone),
+						setTile(1, 2, 3, 1, data.SetTilePassageType("invalid")),
+						setTile(1, 4, 3, 2, data.SetTilePassageTypeNone),
+						addVariable(1),
+					},
+				},
+			},
+		},
+	}
+	g, sceneManager := newTestGame(t, nil, r)
+
+	if err := g.SetTile(testMapID, 1, 1, 2, 3, 100, nil); err == nil {
+		t.Errorf("SetTile with an invalid tile must return an error")
+	}
+	if err := g.SetTile(testMapID, 1, 1, 2, 3, -1, nil); err == nil {
+		t.Errorf("SetTile with a negative tile must return an error")
+	}
+
+	// The commands with invalid arguments are skipped without stopping the game.
+	for i := 0; i < 10; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if g.VariableValue(1) == 0 {
+		t.Errorf("VariableValue(1): got: 0, want: > 0")
+	}
+	if got := g.Map().Tile(1, 2, 3); got != 0 {
+		t.Errorf("Tile(1, 2, 3): got: %d, want: 0", got)
+	}
+	if got := g.Map().Tile(1, 4, 3); got != 2 {
+		t.Errorf("Tile(1, 4, 3): got: %d, want: 2", got)
+	}
+}
+
+func TestSpawnEventWithInvalidEventID(t *testing.T) {
+	r := newTestRoom(1)
+	r.events = []*data.EventImpl{
+		{
+			ID: 1,
+			X:  2,
+			Y:  2,
+			Pages: []*data.Page{
+				{
+					Trigger:  data.TriggerAuto,
+					Priority: data.PriorityMiddle,
+					Commands: []*data.Command{
+						{
+							Name: data.CommandNameSpawnEvent,
+							Args: &data.CommandArgsSpawnEvent{
+								EventID:    2,
+								X:          3,
+								Y:          4,
+								ValueType:  data.ValueTypeConstant,
+								IDVariable: 2,
+							},
+						},
+						addVariable(1),
+					},
+				},
+			},
+		},
+	}
+	g, sceneManager := newTestGame(t, nil, r)
+
+	// The command with an unknown event ID is skipped without stopping the game.
+	for i := 0; i < 10; i++ {
+		updateGame(t, g, sceneManager)
+	}
+	if g.VariableValue(1) == 0 {
+		t.Errorf("VariableValue(1): got: 0, want: > 0")
+	}
+	if got := g.VariableValue(2); got != 0 {
+		t.Errorf("VariableValue(2): got: %d, want: 0", got)
+	}
+}
+
+func TestRotateCharacter(t *testing.T) {
+	cases := []struct {
+		Name            string
+		EightDirections bool
+		Angle           int
+		Dir             data.Dir
+	}{
+		{
+			Name:  "90",
+			Angle: 90,
+			Dir:   data.DirRight,
+		},
+		{
+			Name:  "270",
+			Angle: 270,
+			Dir:   data.DirLeft,
+		},
+		{
+			Name:  "45 without eight directions",
+			Angle: 45,
+			Dir:   data.DirUp,
+		},
+		{
+			Name:            "45 with eight directions",
+			EightDirections: true,
+			Angle:           45,
+			Dir:             data.DirUpRight,
+		},
+		{
+			Name:            "135 with eight directions",
+			EightDirections: true,
+			Angle:           135,
+			Dir:             data.DirDownRight,
+		},
+	}
+	for _, c := range cases {
+		r := newTestRoom(1)
+		r.events = []*data.EventImpl{
+			{
+				ID: 1,
+				X:  2,
+				Y:  2,
+				Pages: []*data.Page{
+					{
+						Dir:      data.DirUp,
+						Trigger:  data.TriggerAuto,
+						Priority: data.PriorityMiddle,
+						Commands: []*data.Command{
+							{
+								Name: data.CommandNameRotateCharacter,
+								Args: &data.CommandArgsRotateCharacter{
+									Angle: c.Angle,
+								},
+							},
+							addVariable(1),
+							// Wait so that the auto event doesn't rotate the character again during the test.
+							{
+								Name: data.CommandNameWait,
+								Args: &data.CommandArgsWait{Time: 10},
+							},
+						},
+					},
+				},
+			},
+		}
+		g, sceneManager := newTestGame(t, &data.System{EightDirections: c.EightDirections}, r)
+		for i := 0; i < 10; i++ {
+			updateGame(t, g, sceneManager)
+		}
+		if g.VariableValue(1) == 0 {
+			t.Errorf("%s: VariableValue(1): got: 0, want: > 0", c.Name)
+		}
+		if got := g.Character(testMapID, 1, 1).Dir(); got != c.Dir {
+			t.Errorf("%s: Dir(): got: %d, want: %d", c.Name, got, c.Dir)
+		}
+	}
+}
+
+func TestTilesVersion(t *testing.T) {
+	g, sceneManager := newTestGame(t, nil, newTestRoom(1), newTestRoom(2))
+
+	v := g.Map().TilesVersion()
+	updateGame(t, g, sceneManager)
+	if got := g.Map().TilesVersion(); got != v {
+		t.Errorf("TilesVersion() after an update: got: %d, want: %d", got, v)
+	}
+
+	if err := g.SetTile(testMapID, 1, 2, 2, 3, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Map().TilesVersion(); got == v {
+		t.Errorf("TilesVersion() after SetTile: got: %d, want: not %d", got, v)
+	}
+
+	v = g.Map().TilesVersion()
+	g.TransferPlayerImmediately(2, 5, 5, nil)
+	if got := g.Map().TilesVersion(); got == v {
+		t.Errorf("TilesVersion() after a transfer: got: %d, want: not %d", got, v)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+)
+
+// random is a pseudo random generator whose state can be saved.
+// The algorithm is SplitMix64.
+// The seed is saved with the state so that the sequence of a play can be reproduced from its start.
+type random struct {
+	seed  int64
+	state uint64
+}
+
+func newRandom(seed int64) *random {
+	return &random{
+		seed:  seed,
+		state: uint64(seed),
+	}
+}
+
+func (r *random) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	z := r.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (r *random) Intn(n int) int {
+	if n <= 0 {
+		panic(fmt.Sprintf("gamestate: invalid argument to Intn: %d", n))
+	}
+	m := uint64(n)
+	// Reject the values that would make the result biased.
+	threshold := -m % m
+	for {
+		if v := r.next(); v >= threshold {
+			return int(v % m)
+		}
+	}
+}
+
+func (r *random) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("seed")
+	e.EncodeInt64(r.seed)
+
+	e.EncodeString("state")
+	e.EncodeInt64(int64(r.state))
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (r *random) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		k := d.DecodeString()
+		switch k {
+		case "seed":
+			r.seed = d.DecodeInt64()
+		case "state":
+			r.state = uint64(d.DecodeInt64())
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: random.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: random.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
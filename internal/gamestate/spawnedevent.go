@@ -0,0 +1,77 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+)
+
+// spawnedEventIDBase is the minimum ID of spawned events.
+// This is large enough not to conflict with the events defined in the editor.
+const spawnedEventIDBase = 100000
+
+// spawnedEvent is an event created at runtime by the spawn_event command.
+//
+// A spawned event shares the pages with the source event, but has its own ID, self switches and page state.
+type spawnedEvent struct {
+	id            int
+	sourceRoomID  int
+	sourceEventID int
+}
+
+func (s *spawnedEvent) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("id")
+	e.EncodeInt(s.id)
+
+	e.EncodeString("sourceRoomId")
+	e.EncodeInt(s.sourceRoomID)
+
+	e.EncodeString("sourceEventId")
+	e.EncodeInt(s.sourceEventID)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (s *spawnedEvent) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "id":
+			s.id = d.DecodeInt()
+		case "sourceRoomId":
+			s.sourceRoomID = d.DecodeInt()
+		case "sourceEventId":
+			s.sourceEventID = d.DecodeInt()
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: spawnedEvent.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: spawnedEvent.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
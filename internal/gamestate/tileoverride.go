@@ -0,0 +1,136 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gamestate
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+)
+
+// tileOverrides is the tiles and the passage types of a room changed by the set_tile command.
+//
+// The keys of the maps are the tile indices (see data.Room.TileIndex).
+type tileOverrides struct {
+	// tiles is the tiles for each layer.
+	tiles map[int]map[int]int
+
+	// passageTypes is the passage types that take priority over the tiles' passage types.
+	passageTypes map[int]data.PassageType
+}
+
+func (t *tileOverrides) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("tiles")
+	e.BeginMap()
+	for layer, ts := range t.tiles {
+		e.EncodeInt(layer)
+		e.BeginMap()
+		for index, tile := range ts {
+			e.EncodeInt(index)
+			e.EncodeInt(tile)
+		}
+		e.EndMap()
+	}
+	e.EndMap()
+
+	e.EncodeString("passageTypes")
+	e.BeginMap()
+	for index, p := range t.passageTypes {
+		e.EncodeInt(index)
+		e.EncodeInt(int(p))
+	}
+	e.EndMap()
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (t *tileOverrides) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "tiles":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				t.tiles = map[int]map[int]int{}
+				for i := 0; i < n; i++ {
+					layer := d.DecodeInt()
+					t.tiles[layer] = map[int]int{}
+					n2 := d.DecodeMapLen()
+					for j := 0; j < n2; j++ {
+						index := d.DecodeInt()
+						t.tiles[layer][index] = d.DecodeInt()
+					}
+				}
+			}
+		case "passageTypes":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				t.passageTypes = map[int]data.PassageType{}
+				for i := 0; i < n; i++ {
+					index := d.DecodeInt()
+					t.passageTypes[index] = data.PassageType(d.DecodeInt())
+				}
+			}
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("gamestate: tileOverrides.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("gamestate: tileOverrides.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+func (t *tileOverrides) tile(layer, index int) (int, bool) {
+	tile, ok := t.tiles[layer][index]
+	return tile, ok
+}
+
+func (t *tileOverrides) setTile(layer, index, tile int) {
+	if t.tiles == nil {
+		t.tiles = map[int]map[int]int{}
+	}
+	if _, ok := t.tiles[layer]; !ok {
+		t.tiles[layer] = map[int]int{}
+	}
+	t.tiles[layer][index] = tile
+}
+
+func (t *tileOverrides) passageType(index int) (data.PassageType, bool) {
+	p, ok := t.passageTypes[index]
+	return p, ok
+}
+
+func (t *tileOverrides) setPassageType(index int, passageType data.PassageType) {
+	if t.passageTypes == nil {
+		t.passageTypes = map[int]data.PassageType{}
+	}
+	t.passageTypes[index] = passageType
+}
+
+func (t *tileOverrides) resetPassageType(index int) {
+	delete(t.passageTypes, index)
+}
@@ -27,6 +27,16 @@ type input struct {
 	y              int
 	backPressCount int
 	prevPressCount int
+	backKeyPressed bool
+
+	// The keys and the wheel that are read in the scenes' Update. These are recorded with the pointer.
+	switchDebugKeyPressed   bool
+	variableDebugKeyPressed bool
+	wheelX                  float64
+	wheelY                  float64
+
+	recorder *recorder
+	player   *player
 }
 
 func IsMuteButtonTriggered() bool {
@@ -34,11 +44,11 @@ func IsMuteButtonTriggered() bool {
 }
 
 func IsSwitchDebugButtonTriggered() bool {
-	return inpututil.IsKeyJustPressed(ebiten.KeyS)
+	return theInput.switchDebugKeyPressed
 }
 
 func IsVariableDebugButtonTriggered() bool {
-	return inpututil.IsKeyJustPressed(ebiten.KeyV)
+	return theInput.variableDebugKeyPressed
 }
 
 func IsTurboButtonTriggered() bool {
@@ -54,7 +64,7 @@ func Update(scaleX, scaleY float64) {
 }
 
 func Wheel() (xoff, yoff float64) {
-	return ebiten.Wheel()
+	return theInput.wheelX, theInput.wheelY
 }
 
 func Pressed() bool {
@@ -62,7 +72,7 @@ func Pressed() bool {
 }
 
 func BackButtonPressed() bool {
-	return inpututil.IsKeyJustPressed(ebiten.KeyB) || theInput.BackButtonPressed()
+	return theInput.BackButtonPressed()
 }
 
 func TriggerBackButton() {
@@ -102,10 +112,36 @@ func (i *input) updatePointerDevices(scaleX, scaleY float64) {
 
 func (i *input) Update(scaleX, scaleY float64) {
 	i.prevPressCount = i.pressCount
-	i.updatePointerDevices(scaleX, scaleY)
+	if i.player != nil && i.player.next() {
+		i.updateWithFrame(i.player.current)
+	} else {
+		i.updatePointerDevices(scaleX, scaleY)
+		i.backKeyPressed = inpututil.IsKeyJustPressed(ebiten.KeyB)
+		i.switchDebugKeyPressed = inpututil.IsKeyJustPressed(ebiten.KeyS)
+		i.variableDebugKeyPressed = inpututil.IsKeyJustPressed(ebiten.KeyV)
+		i.wheelX, i.wheelY = ebiten.Wheel()
+	}
 	if i.backPressCount > 0 {
 		i.backPressCount--
 	}
+	if i.recorder != nil {
+		i.recorder.record(i)
+	}
+}
+
+func (i *input) updateWithFrame(f *Frame) {
+	if f.Pressed {
+		i.pressCount++
+	} else {
+		i.pressCount = 0
+	}
+	i.x = f.X
+	i.y = f.Y
+	i.backKeyPressed = f.BackKey
+	i.switchDebugKeyPressed = f.SwitchDebugKey
+	i.variableDebugKeyPressed = f.VariableDebugKey
+	i.wheelX = f.WheelX
+	i.wheelY = f.WheelY
 }
 
 func (i *input) Pressed() bool {
@@ -125,7 +161,7 @@ func (i *input) Position() (int, int) {
 }
 
 func (i *input) BackButtonPressed() bool {
-	return i.backPressCount > 0
+	return i.backKeyPressed || i.backPressCount > 0
 }
 
 func (i *input) TriggerBackButton() {
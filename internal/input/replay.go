@@ -0,0 +1,189 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+// A replay stream consists of one ReplayHeader followed by one Frame per Update call.
+// Frames are written as soon as they are recorded so that a replay survives a crash.
+//
+// The mute, turbo and screenshot shortcuts are not recorded since they don't change the game state.
+// Replaying a session where screenshots were taken might diverge.
+
+type ReplayHeader struct {
+	Seed int64 `msgpack:"seed"`
+}
+
+type PlatformData struct {
+	Key   string `msgpack:"key"`
+	Value string `msgpack:"value"`
+}
+
+// Result is the result of a request to the platform, e.g. a purchase or a save.
+type Result struct {
+	ID        int    `msgpack:"id"`
+	Type      int    `msgpack:"type"`
+	Succeeded bool   `msgpack:"succeeded"`
+	Data      []byte `msgpack:"data"`
+}
+
+type Frame struct {
+	Pressed          bool            `msgpack:"pressed"`
+	X                int             `msgpack:"x"`
+	Y                int             `msgpack:"y"`
+	BackKey          bool            `msgpack:"backKey"`
+	SwitchDebugKey   bool            `msgpack:"switchDebugKey"`
+	VariableDebugKey bool            `msgpack:"variableDebugKey"`
+	WheelX           float64         `msgpack:"wheelX"`
+	WheelY           float64         `msgpack:"wheelY"`
+	PlatformData     []*PlatformData `msgpack:"platformData"`
+	Results          []*Result       `msgpack:"results"`
+}
+
+type recorder struct {
+	enc            *msgpack.Encoder
+	pending        []*PlatformData
+	pendingResults []*Result
+}
+
+func (r *recorder) record(i *input) {
+	f := &Frame{
+		Pressed:          i.pressCount > 0,
+		X:                i.x,
+		Y:                i.y,
+		BackKey:          i.backKeyPressed,
+		SwitchDebugKey:   i.switchDebugKeyPressed,
+		VariableDebugKey: i.variableDebugKeyPressed,
+		WheelX:           i.wheelX,
+		WheelY:           i.wheelY,
+		PlatformData:     r.pending,
+		Results:          r.pendingResults,
+	}
+	r.pending = nil
+	r.pendingResults = nil
+	if err := r.enc.Encode(f); err != nil {
+		log.Printf("input: recording failed: %v", err)
+		theInput.recorder = nil
+	}
+}
+
+type player struct {
+	dec     *msgpack.Decoder
+	current *Frame
+}
+
+func (p *player) next() bool {
+	f := &Frame{}
+	if err := p.dec.Decode(f); err != nil {
+		if err != io.EOF {
+			log.Printf("input: replaying failed: %v", err)
+		}
+		log.Print("input: replay finished")
+		theInput.player = nil
+		return false
+	}
+	p.current = f
+	return true
+}
+
+// StartRecording starts writing every input frame to w.
+// seed is the random seed the session is started with.
+func StartRecording(w io.Writer, seed int64) error {
+	if theInput.player != nil {
+		panic("input: StartRecording must not be called while replaying")
+	}
+	enc := msgpack.NewEncoder(w)
+	if err := enc.Encode(&ReplayHeader{Seed: seed}); err != nil {
+		return fmt.Errorf("input: StartRecording failed: %v", err)
+	}
+	theInput.recorder = &recorder{
+		enc: enc,
+	}
+	return nil
+}
+
+// StopRecording stops recording input frames.
+// The writer passed to StartRecording is not closed.
+func StopRecording() {
+	theInput.recorder = nil
+}
+
+// StartReplaying starts reading input frames from r instead of the actual devices.
+// StartReplaying returns the random seed that the recorded session was started with.
+// After all the frames are consumed, the actual devices are used again.
+func StartReplaying(r io.Reader) (int64, error) {
+	if theInput.recorder != nil {
+		panic("input: StartReplaying must not be called while recording")
+	}
+	dec := msgpack.NewDecoder(r)
+	h := &ReplayHeader{}
+	if err := dec.Decode(h); err != nil {
+		return 0, fmt.Errorf("input: StartReplaying failed: %v", err)
+	}
+	theInput.player = &player{
+		dec: dec,
+	}
+	return h.Seed, nil
+}
+
+func IsReplaying() bool {
+	return theInput.player != nil
+}
+
+// RecordPlatformData records the platform data that is consumed at the next Update.
+func RecordPlatformData(key, value string) {
+	if theInput.recorder == nil {
+		return
+	}
+	theInput.recorder.pending = append(theInput.recorder.pending, &PlatformData{
+		Key:   key,
+		Value: value,
+	})
+}
+
+// ReplayedPlatformData returns the platform data recorded at the current frame.
+func ReplayedPlatformData() []*PlatformData {
+	if theInput.player == nil || theInput.player.current == nil {
+		return nil
+	}
+	return theInput.player.current.PlatformData
+}
+
+// RecordResult records the result of a request that is consumed at the next Update.
+func RecordResult(id int, typ int, succeeded bool, data []byte) {
+	if theInput.recorder == nil {
+		return
+	}
+	theInput.recorder.pendingResults = append(theInput.recorder.pendingResults, &Result{
+		ID:        id,
+		Type:      typ,
+		Succeeded: succeeded,
+		Data:      data,
+	})
+}
+
+// ReplayedResults returns the results of requests recorded at the current frame.
+func ReplayedResults() []*Result {
+	if theInput.player == nil || theInput.player.current == nil {
+		return nil
+	}
+	return theInput.player.current.Results
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package input_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/input"
+)
+
+func TestReplay(t *testing.T) {
+	frames := []*Frame{
+		{Pressed: true, X: 10, Y: 20},
+		{Pressed: true, X: 11, Y: 21},
+		{Pressed: false, X: 11, Y: 21, BackKey: true},
+	}
+
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	if err := enc.Encode(&ReplayHeader{Seed: 1234}); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := enc.Encode(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seed, err := StartReplaying(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed != 1234 {
+		t.Errorf("seed: got %d, want %d", seed, 1234)
+	}
+
+	Update(1, 1)
+	if !Triggered() {
+		t.Errorf("Triggered() at frame 0: got false, want true")
+	}
+	if x, y := Position(); x != 10 || y != 20 {
+		t.Errorf("Position() at frame 0: got (%d, %d), want (10, 20)", x, y)
+	}
+	Update(1, 1)
+	if Triggered() || !Pressed() {
+		t.Errorf("Triggered(), Pressed() at frame 1: got %t, %t, want false, true", Triggered(), Pressed())
+	}
+	Update(1, 1)
+	if !Released() || !BackButtonPressed() {
+		t.Errorf("Released(), BackButtonPressed() at frame 2: got %t, %t, want true, true", Released(), BackButtonPressed())
+	}
+	if !IsReplaying() {
+		t.Errorf("IsReplaying() before the end: got false, want true")
+	}
+	Update(1, 1)
+	if IsReplaying() {
+		t.Errorf("IsReplaying() after the end: got true, want false")
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := StartRecording(buf, 5678); err != nil {
+		t.Fatal(err)
+	}
+	RecordPlatformData("interstitial_ads_loaded", "1")
+	Update(1, 1)
+	Update(1, 1)
+	RecordPlatformData("rewarded_ads_loaded", "1")
+	RecordPlatformData("backbutton", "")
+	Update(1, 1)
+	StopRecording()
+
+	seed, err := StartReplaying(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seed != 5678 {
+		t.Errorf("seed: got %d, want %d", seed, 5678)
+	}
+	want := [][]*PlatformData{
+		{{Key: "interstitial_ads_loaded", Value: "1"}},
+		nil,
+		{{Key: "rewarded_ads_loaded", Value: "1"}, {Key: "backbutton", Value: ""}},
+	}
+	for i, w := range want {
+		Update(1, 1)
+		if got := ReplayedPlatformData(); !reflect.DeepEqual(got, w) {
+			t.Errorf("ReplayedPlatformData() at frame %d: got %v, want %v", i, got, w)
+		}
+	}
+	Update(1, 1)
+	if IsReplaying() {
+		t.Errorf("IsReplaying() after the end: got true, want false")
+	}
+}
+
+func TestRecordAndReplayResults(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := StartRecording(buf, 5678); err != nil {
+		t.Fatal(err)
+	}
+	RecordResult(1, 2, true, []byte("data"))
+	Update(1, 1)
+	Update(1, 1)
+	RecordResult(2, 3, false, nil)
+	RecordResult(3, 4, true, nil)
+	Update(1, 1)
+	StopRecording()
+
+	if _, err := StartReplaying(buf); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]*Result{
+		{{ID: 1, Type: 2, Succeeded: true, Data: []byte("data")}},
+		nil,
+		{{ID: 2, Type: 3, Succeeded: false}, {ID: 3, Type: 4, Succeeded: true}},
+	}
+	for i, w := range want {
+		Update(1, 1)
+		if got := ReplayedResults(); !reflect.DeepEqual(got, w) {
+			t.Errorf("ReplayedResults() at frame %d: got %v, want %v", i, got, w)
+		}
+	}
+	Update(1, 1)
+	if IsReplaying() {
+		t.Errorf("IsReplaying() after the end: got true, want false")
+	}
+}
+
+func TestReplayKeysAndWheel(t *testing.T) {
+	frames := []*Frame{
+		{SwitchDebugKey: true},
+		{VariableDebugKey: true, WheelY: -1.5},
+		{},
+	}
+
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	if err := enc.Encode(&ReplayHeader{Seed: 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := enc.Encode(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := StartReplaying(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, f := range frames {
+		Update(1, 1)
+		if got := IsSwitchDebugButtonTriggered(); got != f.SwitchDebugKey {
+			t.Errorf("IsSwitchDebugButtonTriggered() at frame %d: got %t, want %t", i, got, f.SwitchDebugKey)
+		}
+		if got := IsVariableDebugButtonTriggered(); got != f.VariableDebugKey {
+			t.Errorf("IsVariableDebugButtonTriggered() at frame %d: got %t, want %t", i, got, f.VariableDebugKey)
+		}
+		if x, y := Wheel(); x != f.WheelX || y != f.WheelY {
+			t.Errorf("Wheel() at frame %d: got (%f, %f), want (%f, %f)", i, x, y, f.WheelX, f.WheelY)
+		}
+	}
+	Update(1, 1)
+	if IsReplaying() {
+		t.Errorf("IsReplaying() after the end: got true, want false")
+	}
+}
@@ -22,11 +22,38 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
 )
 
+type Easing string
+
+const (
+	EasingLinear    Easing = "linear"
+	EasingEaseIn    Easing = "ease_in"
+	EasingEaseOut   Easing = "ease_out"
+	EasingEaseInOut Easing = "ease_in_out"
+)
+
+// apply converts the linear progress t in [0, 1] to the eased progress.
+func (e Easing) apply(t float64) float64 {
+	switch e {
+	case EasingEaseIn:
+		return t * t
+	case EasingEaseOut:
+		return t * (2 - t)
+	case EasingEaseInOut:
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return -1 + (4-2*t)*t
+	default:
+		return t
+	}
+}
+
 type I struct {
 	src      float64
 	dst      float64
 	count    int
 	maxCount int
+	easing   Easing
 }
 
 func New(val float64) *I {
@@ -52,6 +79,9 @@ func (i *I) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("maxCount")
 	e.EncodeInt(i.maxCount)
 
+	e.EncodeString("easing")
+	e.EncodeString(string(i.easing))
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -70,6 +100,8 @@ func (i *I) DecodeMsgpack(dec *msgpack.Decoder) error {
 			i.count = d.DecodeInt()
 		case "maxCount":
 			i.maxCount = d.DecodeInt()
+		case "easing":
+			i.easing = Easing(d.DecodeString())
 		}
 	}
 
@@ -84,6 +116,10 @@ func (i *I) Current() float64 {
 		return i.dst
 	}
 	rate := float64(i.count) / float64(i.maxCount)
+	if i.easing != "" && i.easing != EasingLinear {
+		t := i.easing.apply(1 - rate)
+		return i.src + t*(i.dst-i.src)
+	}
 	return rate*i.src + (1-rate)*i.dst
 }
 
@@ -92,10 +128,15 @@ func (i *I) Dst() float64 {
 }
 
 func (i *I) Set(value float64, count int) {
+	i.SetWithEasing(value, count, EasingLinear)
+}
+
+func (i *I) SetWithEasing(value float64, count int, easing Easing) {
 	i.src = i.Current()
 	i.dst = value
 	i.count = count
 	i.maxCount = count
+	i.easing = easing
 }
 
 func (i *I) IsChanging() bool {
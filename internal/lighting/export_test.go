@@ -0,0 +1,33 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lighting
+
+func (l *Lighting) LightIDsForTesting() []int {
+	ids := []int{}
+	for _, light := range l.lights {
+		ids = append(ids, light.id)
+	}
+	return ids
+}
+
+// LightIntensityForTesting returns the intensity of the light of the given ID at the current tick.
+func (l *Lighting) LightIntensityForTesting(id int) float64 {
+	for _, light := range l.lights {
+		if light.id == id {
+			return light.intensity(l.count)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,387 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lighting provides the darkness overlay and the light sources of the map.
+package lighting
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/interpolation"
+)
+
+const lightImageSize = 64
+
+var theLightImage *ebiten.Image
+
+// lightImage returns a white circle whose alpha falls off from the center.
+func lightImage() *ebiten.Image {
+	if theLightImage != nil {
+		return theLightImage
+	}
+	pix := make([]byte, 4*lightImageSize*lightImageSize)
+	const r = lightImageSize / 2
+	for j := 0; j < lightImageSize; j++ {
+		for i := 0; i < lightImageSize; i++ {
+			dx := float64(i) + 0.5 - r
+			dy := float64(j) + 0.5 - r
+			d := math.Sqrt(dx*dx+dy*dy) / r
+			a := 0.0
+			if d < 1 {
+				a = 1 - d*d
+			}
+			v := byte(a * 0xff)
+			idx := 4 * (j*lightImageSize + i)
+			pix[idx] = v
+			pix[idx+1] = v
+			pix[idx+2] = v
+			pix[idx+3] = v
+		}
+	}
+	theLightImage, _ = ebiten.NewImage(lightImageSize, lightImageSize, ebiten.FilterDefault)
+	theLightImage.ReplacePixels(pix)
+	return theLightImage
+}
+
+// Light is a light source attached to a character or a tile.
+type Light struct {
+	id int
+
+	// eventID is the character that the light follows. 0 means the light is at the tile (x, y).
+	eventID int
+	x       int
+	y       int
+
+	radius int
+	red    int
+	green  int
+	blue   int
+
+	// flicker is the amplitude of the flicker in percent, in [0, 100].
+	flicker int
+}
+
+func (l *Light) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("id")
+	e.EncodeInt(l.id)
+
+	e.EncodeString("eventId")
+	e.EncodeInt(l.eventID)
+
+	e.EncodeString("x")
+	e.EncodeInt(l.x)
+
+	e.EncodeString("y")
+	e.EncodeInt(l.y)
+
+	e.EncodeString("radius")
+	e.EncodeInt(l.radius)
+
+	e.EncodeString("red")
+	e.EncodeInt(l.red)
+
+	e.EncodeString("green")
+	e.EncodeInt(l.green)
+
+	e.EncodeString("blue")
+	e.EncodeInt(l.blue)
+
+	e.EncodeString("flicker")
+	e.EncodeInt(l.flicker)
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (l *Light) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "id":
+			l.id = d.DecodeInt()
+		case "eventId":
+			l.eventID = d.DecodeInt()
+		case "x":
+			l.x = d.DecodeInt()
+		case "y":
+			l.y = d.DecodeInt()
+		case "radius":
+			l.radius = d.DecodeInt()
+		case "red":
+			l.red = d.DecodeInt()
+		case "green":
+			l.green = d.DecodeInt()
+		case "blue":
+			l.blue = d.DecodeInt()
+		case "flicker":
+			l.flicker = d.DecodeInt()
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("lighting: Light.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("lighting: Light.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// intensity returns the brightness of the light in [0, 1] at the given tick.
+func (l *Light) intensity(count int) float64 {
+	if l.flicker == 0 {
+		return 1
+	}
+	// Mix two waves so that the flicker doesn't look periodic.
+	t := float64(count) + float64(l.id)*17
+	w := (math.Sin(t*0.31) + math.Sin(t*0.113+1)) / 4
+	return 1 - float64(l.flicker)/100*(0.5+w)
+}
+
+// Lighting is the darkness overlay of the room and the light sources.
+type Lighting struct {
+	red      interpolation.I
+	green    interpolation.I
+	blue     interpolation.I
+	darkness interpolation.I
+	lights   []*Light
+
+	// Fields that are not dumped
+	count         int
+	darknessImage *ebiten.Image
+}
+
+func (l *Lighting) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("red")
+	e.EncodeInterface(&l.red)
+
+	e.EncodeString("green")
+	e.EncodeInterface(&l.green)
+
+	e.EncodeString("blue")
+	e.EncodeInterface(&l.blue)
+
+	e.EncodeString("darkness")
+	e.EncodeInterface(&l.darkness)
+
+	e.EncodeString("lights")
+	e.BeginArray()
+	for _, light := range l.lights {
+		e.EncodeInterface(light)
+	}
+	e.EndArray()
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (l *Lighting) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "red":
+			d.DecodeInterface(&l.red)
+		case "green":
+			d.DecodeInterface(&l.green)
+		case "blue":
+			d.DecodeInterface(&l.blue)
+		case "darkness":
+			d.DecodeInterface(&l.darkness)
+		case "lights":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				l.lights = make([]*Light, n)
+				for i := 0; i < n; i++ {
+					l.lights[i] = &Light{}
+					d.DecodeInterface(l.lights[i])
+				}
+			}
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("lighting: Lighting.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("lighting: Lighting.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// SetAmbient changes the darkness overlay in count frames.
+// red, green and blue are the color of the overlay, and darkness is its opacity. All the values are in [0, 255].
+func (l *Lighting) SetAmbient(red, green, blue, darkness int, count int) {
+	l.red.Set(float64(red)/255, count)
+	l.green.Set(float64(green)/255, count)
+	l.blue.Set(float64(blue)/255, count)
+	l.darkness.Set(float64(darkness)/255, count)
+}
+
+func (l *Lighting) IsChangingAmbient() bool {
+	return l.darkness.IsChanging() || l.red.IsChanging() || l.green.IsChanging() || l.blue.IsChanging()
+}
+
+// AddLight adds a light source. A light with the same ID is replaced.
+// If eventID is 0, the light is put at the tile (x, y). Otherwise, the light follows the character.
+// flicker is clamped to [0, 100].
+func (l *Lighting) AddLight(id, eventID, x, y, radius, red, green, blue, flicker int) {
+	if flicker < 0 {
+		flicker = 0
+	}
+	if flicker > 100 {
+		flicker = 100
+	}
+	l.RemoveLight(id)
+	l.lights = append(l.lights, &Light{
+		id:      id,
+		eventID: eventID,
+		x:       x,
+		y:       y,
+		radius:  radius,
+		red:     red,
+		green:   green,
+		blue:    blue,
+		flicker: flicker,
+	})
+}
+
+// RemoveLight removes the light of the given ID. If id is 0, all the lights are removed.
+func (l *Lighting) RemoveLight(id int) {
+	if id == 0 {
+		l.lights = nil
+		return
+	}
+	ls := []*Light{}
+	for _, light := range l.lights {
+		if light.id == id {
+			continue
+		}
+		ls = append(ls, light)
+	}
+	l.lights = ls
+}
+
+// ClearRoomLights removes the lights that belong to the room, i.e., all the lights except for the player's.
+func (l *Lighting) ClearRoomLights() {
+	ls := []*Light{}
+	for _, light := range l.lights {
+		if light.eventID != character.PlayerEventID {
+			continue
+		}
+		ls = append(ls, light)
+	}
+	l.lights = ls
+}
+
+func (l *Lighting) Update() {
+	l.red.Update()
+	l.green.Update()
+	l.blue.Update()
+	l.darkness.Update()
+	l.count++
+}
+
+// Draw draws the lights and the darkness overlay.
+// characterCenter returns the center position of the character in the map, or false if the character doesn't exist.
+func (l *Lighting) Draw(screen *ebiten.Image, characterCenter func(eventID int) (int, int, bool), offsetX, offsetY int) {
+	darkness := l.darkness.Current()
+	if darkness == 0 && len(l.lights) == 0 {
+		return
+	}
+
+	type drawnLight struct {
+		light *Light
+		x     int
+		y     int
+	}
+	ls := []drawnLight{}
+	for _, light := range l.lights {
+		x, y := light.x*consts.TileSize+consts.TileSize/2, light.y*consts.TileSize+consts.TileSize/2
+		if light.eventID != 0 {
+			var ok bool
+			x, y, ok = characterCenter(light.eventID)
+			if !ok {
+				continue
+			}
+		}
+		ls = append(ls, drawnLight{light, x + offsetX, y + offsetY})
+	}
+
+	lightGeoM := func(light *Light, x, y int) ebiten.GeoM {
+		var g ebiten.GeoM
+		s := float64(2*light.radius) / lightImageSize
+		g.Translate(-lightImageSize/2, -lightImageSize/2)
+		g.Scale(s, s)
+		g.Translate(float64(x), float64(y))
+		return g
+	}
+
+	// The colored glows are added to the screen.
+	for _, dl := range ls {
+		light := dl.light
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM = lightGeoM(light, dl.x, dl.y)
+		op.ColorM.Scale(float64(light.red)/255, float64(light.green)/255, float64(light.blue)/255, 0.5*light.intensity(l.count))
+		op.CompositeMode = ebiten.CompositeModeLighter
+		screen.DrawImage(lightImage(), op)
+	}
+
+	if darkness == 0 {
+		return
+	}
+
+	w, h := screen.Size()
+	if l.darknessImage != nil {
+		if dw, dh := l.darknessImage.Size(); dw != w || dh != h {
+			l.darknessImage.Dispose()
+			l.darknessImage = nil
+		}
+	}
+	if l.darknessImage == nil {
+		l.darknessImage, _ = ebiten.NewImage(w, h, ebiten.FilterDefault)
+	}
+	l.darknessImage.Fill(color.NRGBA{
+		R: uint8(l.red.Current() * 0xff),
+		G: uint8(l.green.Current() * 0xff),
+		B: uint8(l.blue.Current() * 0xff),
+		A: uint8(darkness * 0xff),
+	})
+	// The lights cut holes in the darkness.
+	for _, dl := range ls {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM = lightGeoM(dl.light, dl.x, dl.y)
+		op.ColorM.Scale(1, 1, 1, dl.light.intensity(l.count))
+		op.CompositeMode = ebiten.CompositeModeDestinationOut
+		l.darknessImage.DrawImage(lightImage(), op)
+	}
+	screen.DrawImage(l.darknessImage, nil)
+}
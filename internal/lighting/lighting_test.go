@@ -0,0 +1,152 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lighting_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/lighting"
+)
+
+func TestLightingMarshal(t *testing.T) {
+	l := &Lighting{}
+	l.SetAmbient(10, 20, 30, 200, 60)
+	l.AddLight(1, 0, 2, 3, 48, 255, 128, 0, 0)
+	l.AddLight(2, character.PlayerEventID, 0, 0, 32, 255, 255, 255, 20)
+	for i := 0; i < 10; i++ {
+		l.Update()
+	}
+
+	b, err := msgpack.Marshal(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2 := &Lighting{}
+	if err := msgpack.Unmarshal(b, l2); err != nil {
+		t.Fatal(err)
+	}
+	b2, err := msgpack.Marshal(l2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, b2) {
+		t.Errorf("the lighting changed after marshaling and unmarshaling")
+	}
+	if got, want := l2.LightIDsForTesting(), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting(): got: %v, want: %v", got, want)
+	}
+	if !l2.IsChangingAmbient() {
+		t.Errorf("IsChangingAmbient(): got: false, want: true")
+	}
+}
+
+func TestLightingUnknownKey(t *testing.T) {
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"foo": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := msgpack.Unmarshal(b, &Lighting{}); err == nil {
+		t.Errorf("msgpack.Unmarshal with an unknown key: got: nil, want: an error")
+	}
+}
+
+func TestAddLight(t *testing.T) {
+	l := &Lighting{}
+	l.AddLight(1, character.PlayerEventID, 0, 0, 32, 255, 255, 255, 0)
+	l.AddLight(2, 0, 1, 1, 32, 255, 255, 255, 0)
+	if got, want := l.LightIDsForTesting(), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting(): got: %v, want: %v", got, want)
+	}
+
+	// The light of the same ID is replaced.
+	l.AddLight(1, 0, 4, 5, 16, 0, 0, 255, 0)
+	if got, want := l.LightIDsForTesting(), []int{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting() after replacing: got: %v, want: %v", got, want)
+	}
+
+	// The new light of ID 1 is not the player's any more.
+	l.ClearRoomLights()
+	if got, want := l.LightIDsForTesting(), []int{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting() after ClearRoomLights: got: %v, want: %v", got, want)
+	}
+}
+
+func TestRemoveLight(t *testing.T) {
+	l := &Lighting{}
+	for id := 1; id <= 3; id++ {
+		l.AddLight(id, 0, id, id, 32, 255, 255, 255, 0)
+	}
+
+	l.RemoveLight(2)
+	if got, want := l.LightIDsForTesting(), []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting() after RemoveLight(2): got: %v, want: %v", got, want)
+	}
+
+	// Removing a light that doesn't exist does nothing.
+	l.RemoveLight(4)
+	if got, want := l.LightIDsForTesting(), []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting() after RemoveLight(4): got: %v, want: %v", got, want)
+	}
+
+	// 0 removes all the lights.
+	l.RemoveLight(0)
+	if got, want := l.LightIDsForTesting(), []int{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting() after RemoveLight(0): got: %v, want: %v", got, want)
+	}
+}
+
+func TestClearRoomLights(t *testing.T) {
+	l := &Lighting{}
+	l.AddLight(1, 0, 1, 1, 32, 255, 255, 255, 0)
+	l.AddLight(2, character.PlayerEventID, 0, 0, 32, 255, 255, 255, 0)
+	l.AddLight(3, 5, 0, 0, 32, 255, 255, 255, 0)
+	l.AddLight(4, character.PlayerEventID, 0, 0, 16, 255, 0, 0, 10)
+
+	l.ClearRoomLights()
+	if got, want := l.LightIDsForTesting(), []int{2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LightIDsForTesting(): got: %v, want: %v", got, want)
+	}
+}
+
+func TestLightFlicker(t *testing.T) {
+	l := &Lighting{}
+	l.AddLight(1, 0, 1, 1, 32, 255, 255, 255, 0)
+	l.AddLight(2, 0, 2, 2, 32, 255, 255, 255, 50)
+	// The flicker is clamped to [0, 100].
+	l.AddLight(3, 0, 3, 3, 32, 255, 255, 255, 200)
+	l.AddLight(4, 0, 4, 4, 32, 255, 255, 255, -50)
+
+	for i := 0; i < 600; i++ {
+		l.Update()
+		for id := 1; id <= 4; id++ {
+			if got := l.LightIntensityForTesting(id); got < 0 || 1 < got {
+				t.Fatalf("LightIntensityForTesting(%d) at frame %d: got: %f, want: [0, 1]", id, i, got)
+			}
+		}
+		if got := l.LightIntensityForTesting(1); got != 1 {
+			t.Errorf("LightIntensityForTesting(1) at frame %d: got: %f, want: 1", i, got)
+		}
+		if got := l.LightIntensityForTesting(4); got != 1 {
+			t.Errorf("LightIntensityForTesting(4) at frame %d: got: %f, want: 1", i, got)
+		}
+	}
+}
@@ -39,26 +39,58 @@ type State struct {
 	dir           data.Dir
 }
 
-type atFunc func(x, y int) bool
+type passableOnMap struct {
+	gameState        GameState
+	through          bool
+	ignoreCharacters bool
+}
+
+func (p *passableOnMap) At(x, y int) bool {
+	return p.gameState.MapPassableAt(p.through, x, y, p.ignoreCharacters)
+}
 
-func (a atFunc) At(x, y int) bool {
-	return a(x, y)
+func (p *passableOnMap) AtDir(x, y int, dir data.Dir) bool {
+	return p.gameState.MapPassableDir(p.through, x, y, dir, p.ignoreCharacters)
+}
+
+func (p *passableOnMap) Size() (int, int) {
+	return p.gameState.MapSize()
+}
+
+func (p *passableOnMap) Cost(x, y int) int {
+	if p.through {
+		return 1
+	}
+	return p.gameState.MapMoveCost(x, y)
 }
 
 type GameState interface {
 	MapPassableAt(through bool, x, y int, ignoreCharacters bool) bool
+	MapMoveCost(x, y int) int
+	MapSize() (int, int)
+	MapPassableDir(through bool, x, y int, dir data.Dir, ignoreCharacters bool) bool
+	EightDirections() bool
 	VariableValue(id int) int64
 	RandomValue(min, max int) int
 	Character(mapID, roomID, eventID int) *character.Character
+
+	// BumpCharacter notifies that the character failed to move into (x, y).
+	BumpCharacter(mapID, roomID, eventID int, x, y int)
 }
 
 func (s *State) calcNextStepToMoveTarget(gameState GameState, x int, y int, ignoreCharacters bool) bool {
 	ch := s.character(gameState)
 	cx, cy := ch.Position()
-	f := func(x, y int) bool {
-		return gameState.MapPassableAt(ch.Through(), x, y, ignoreCharacters)
+	// The search is not limited so that the character always reaches the target when possible, even in a big room.
+	calc := path.CalcAStar
+	if gameState.EightDirections() {
+		calc = path.CalcAStarWithDiagonals
 	}
-	path, _, _ := path.Calc(atFunc(f), cx, cy, x, y, true)
+	path, _, _ := calc(&passableOnMap{
+		gameState:        gameState,
+		through:          ch.Through(),
+		ignoreCharacters: ignoreCharacters,
+	}, cx, cy, x, y, true, 0)
 	// Adopt the only one step.
 	if len(path) > 0 {
 		s.path = path[:1]
@@ -102,7 +134,7 @@ func New(gameState GameState, mapID, roomID, eventID int, args *data.CommandArgs
 		s.dir = c.Dir()
 	case data.MoveCharacterTypeBackward:
 		s.distanceCount = s.args.Distance
-		s.dir = (c.Dir() + 2) % 4
+		s.dir = c.Dir().Opposite()
 	case data.MoveCharacterTypeToward:
 		log.Printf("not implemented yet (move_character): type %s", s.args.Type)
 		s.distanceCount = s.args.Distance
@@ -246,53 +278,21 @@ func (s *State) Update(gameState GameState) {
 	}
 
 	if s.distanceCount > 0 && !s.waiting {
-		dx, dy := c.Position()
+		x, y := c.Position()
 		turnOnly := false
 		dir := s.dir
-		switch s.args.Type {
-		case data.MoveCharacterTypeTarget:
-			switch c := s.path[len(s.path)-s.distanceCount]; c {
-			case path.RouteCommandMoveUp:
-				dir = data.DirUp
-			case path.RouteCommandMoveRight:
-				dir = data.DirRight
-			case path.RouteCommandMoveDown:
-				dir = data.DirDown
-			case path.RouteCommandMoveLeft:
-				dir = data.DirLeft
-			case path.RouteCommandTurnUp:
-				dir = data.DirUp
-				turnOnly = true
-			case path.RouteCommandTurnRight:
-				dir = data.DirRight
-				turnOnly = true
-			case path.RouteCommandTurnDown:
-				dir = data.DirDown
-				turnOnly = true
-			case path.RouteCommandTurnLeft:
-				dir = data.DirLeft
-				turnOnly = true
-			default:
-				panic(fmt.Sprintf("movecharacterstate: invalid command: %d", c))
-			}
-		}
-		switch dir {
-		case data.DirUp:
-			dy--
-		case data.DirRight:
-			dx++
-		case data.DirDown:
-			dy++
-		case data.DirLeft:
-			dx--
-		default:
-			panic(fmt.Sprintf("movecharacterstate: invalid dir: %d", dir))
+		if s.args.Type == data.MoveCharacterTypeTarget {
+			r := s.path[len(s.path)-s.distanceCount]
+			dir = r.Dir()
+			turnOnly = r.IsTurn()
 		}
 		if turnOnly {
 			c.Turn(dir)
 		} else {
-			if !gameState.MapPassableAt(c.Through(), dx, dy, false) {
+			if !gameState.MapPassableDir(c.Through(), x, y, dir, false) {
 				c.Turn(dir)
+				dx, dy := dir.Delta()
+				gameState.BumpCharacter(s.mapID, s.roomID, s.eventID, x+dx, y+dy)
 				if s.routeSkip {
 					s.terminated = true
 					s.distanceCount = 0
@@ -0,0 +1,342 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package path
+
+import (
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+// Coster is an optional interface for Passable to specify the cost to enter a tile.
+//
+// The cost of a tile is 1 when Passable doesn't implement Coster.
+// A cost less than 1 is treated as 1.
+type Coster interface {
+	Cost(x, y int) int
+}
+
+// Field is a Passable that knows its size.
+//
+// At must return false for the positions outside the rectangle from (0, 0) to (width, height).
+type Field interface {
+	Passable
+	Size() (width, height int)
+}
+
+type node struct {
+	g     int
+	h     int
+	turns int
+	dir   data.Dir
+
+	// parent is the index of the parent node, or -1 if the node is the start.
+	parent int
+
+	opened bool
+	closed bool
+
+	// heapIndex is the index in the heap, or -1 if the node is not in the heap.
+	heapIndex int
+}
+
+// finder holds the buffers for A* search.
+//
+// The buffers are reused across calls so that a search doesn't allocate nodes every time.
+// The nodes are indexed by y*width+x, where the rectangle has the margin of 1 tile around the field
+// so that an unpassable goal next to the edge can be a node.
+type finder struct {
+	nodes  []node
+	heap   []int
+	width  int
+	height int
+}
+
+// theFinder is the finder used by CalcAStar. Path finding always runs on the game loop.
+var theFinder finder
+
+func (f *finder) reset(width, height int) {
+	f.width = width + 2
+	f.height = height + 2
+	n := f.width * f.height
+	if cap(f.nodes) < n {
+		f.nodes = make([]node, n)
+	} else {
+		f.nodes = f.nodes[:n]
+		for i := range f.nodes {
+			f.nodes[i] = node{}
+		}
+	}
+	f.heap = f.heap[:0]
+}
+
+// index returns the node index of (x, y), or -1 if (x, y) is out of the rectangle.
+func (f *finder) index(x, y int) int {
+	x++
+	y++
+	if x < 0 || f.width <= x || y < 0 || f.height <= y {
+		return -1
+	}
+	return y*f.width + x
+}
+
+func (f *finder) pos(index int) (int, int) {
+	return index%f.width - 1, index/f.width - 1
+}
+
+// less reports whether the node at heap index i comes before the node at heap index j.
+// A node with a less score comes first, then a node closer to the goal, then a node with less turns.
+func (f *finder) less(i, j int) bool {
+	a, b := &f.nodes[f.heap[i]], &f.nodes[f.heap[j]]
+	if fa, fb := a.g+a.h, b.g+b.h; fa != fb {
+		return fa < fb
+	}
+	if a.h != b.h {
+		return a.h < b.h
+	}
+	return a.turns < b.turns
+}
+
+func (f *finder) swap(i, j int) {
+	f.heap[i], f.heap[j] = f.heap[j], f.heap[i]
+	f.nodes[f.heap[i]].heapIndex = i
+	f.nodes[f.heap[j]].heapIndex = j
+}
+
+func (f *finder) up(i int) {
+	for i > 0 {
+		p := (i - 1) / 2
+		if !f.less(i, p) {
+			break
+		}
+		f.swap(i, p)
+		i = p
+	}
+}
+
+func (f *finder) down(i int) {
+	n := len(f.heap)
+	for {
+		c := 2*i + 1
+		if c >= n {
+			break
+		}
+		if r := c + 1; r < n && f.less(r, c) {
+			c = r
+		}
+		if !f.less(c, i) {
+			break
+		}
+		f.swap(i, c)
+		i = c
+	}
+}
+
+func (f *finder) push(index int) {
+	f.nodes[index].heapIndex = len(f.heap)
+	f.heap = append(f.heap, index)
+	f.up(len(f.heap) - 1)
+}
+
+func (f *finder) pop() int {
+	index := f.heap[0]
+	last := len(f.heap) - 1
+	f.swap(0, last)
+	f.heap = f.heap[:last]
+	f.down(0)
+	f.nodes[index].heapIndex = -1
+	return index
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func max(a, b int) int {
+	if a < b {
+		return b
+	}
+	return a
+}
+
+var successorDirs = []data.Dir{
+	data.DirUp,
+	data.DirRight,
+	data.DirDown,
+	data.DirLeft,
+}
+
+var successorDirsWithDiagonals = []data.Dir{
+	data.DirUp,
+	data.DirRight,
+	data.DirDown,
+	data.DirLeft,
+	data.DirUpRight,
+	data.DirDownRight,
+	data.DirDownLeft,
+	data.DirUpLeft,
+}
+
+// CalcAStar calculates the route from the start to the goal with A* search.
+//
+// CalcAStar returns the same values as Calc, but the route is the cheapest one in terms of the tile costs
+// (see Coster). Among the cheapest routes, a route with less turns is preferred.
+//
+// maxNodes is the maximum number of the nodes to expand. maxNodes <= 0 means no limit.
+// If the budget is exhausted before reaching the goal, CalcAStar returns the route to the node closest to the goal
+// unless mustReachGoal is true.
+//
+// CalcAStar is not safe for concurrent use.
+func CalcAStar(field Field, startX, startY, goalX, goalY int, mustReachGoal bool, maxNodes int) ([]RouteCommand, int, int) {
+	return theFinder.calc(field, startX, startY, goalX, goalY, mustReachGoal, maxNodes, false)
+}
+
+// CalcAStarWithDiagonals is like CalcAStar but the route can include diagonal moves.
+//
+// A diagonal move costs the same as an orthogonal move. A diagonal move is allowed only when both the
+// orthogonally adjacent tiles are passable, so that the route never cuts a corner.
+func CalcAStarWithDiagonals(field Field, startX, startY, goalX, goalY int, mustReachGoal bool, maxNodes int) ([]RouteCommand, int, int) {
+	return theFinder.calc(field, startX, startY, goalX, goalY, mustReachGoal, maxNodes, true)
+}
+
+func (f *finder) calc(field Field, startX, startY, goalX, goalY int, mustReachGoal bool, maxNodes int, diagonal bool) ([]RouteCommand, int, int) {
+	coster, _ := field.(Coster)
+	dirPassable, _ := field.(DirPassable)
+	heuristic := func(x, y int) int {
+		if diagonal {
+			return max(abs(goalX-x), abs(goalY-y))
+		}
+		return abs(goalX-x) + abs(goalY-y)
+	}
+	dirs := successorDirs
+	if diagonal {
+		dirs = successorDirsWithDiagonals
+	}
+
+	f.reset(field.Size())
+	start := f.index(startX, startY)
+	if start == -1 {
+		return nil, 0, 0
+	}
+	f.nodes[start] = node{
+		h:      heuristic(startX, startY),
+		dir:    -1,
+		parent: -1,
+		opened: true,
+	}
+	f.push(start)
+
+	goal := -1
+	best := start
+	expanded := 0
+	exhausted := false
+	for len(f.heap) > 0 {
+		if maxNodes > 0 && expanded >= maxNodes {
+			exhausted = true
+			break
+		}
+		i := f.pop()
+		n := &f.nodes[i]
+		n.closed = true
+		expanded++
+
+		nx, ny := f.pos(i)
+		if nx == goalX && ny == goalY {
+			goal = i
+			break
+		}
+		if b := &f.nodes[best]; n.h < b.h || (n.h == b.h && n.g < b.g) {
+			best = i
+		}
+
+		for _, dir := range dirs {
+			dx, dy := dir.Delta()
+			x, y := nx+dx, ny+dy
+			if dir.IsDiagonal() && (!field.At(nx+dx, ny) || !field.At(nx, ny+dy)) {
+				continue
+			}
+			cost := 1
+			if !field.At(x, y) {
+				// It's OK even if the final destination is not passable so far.
+				if x != goalX || y != goalY {
+					continue
+				}
+			} else {
+				if dirPassable != nil && !dirPassable.AtDir(nx, ny, dir) {
+					continue
+				}
+				if coster != nil {
+					if c := coster.Cost(x, y); c > 1 {
+						cost = c
+					}
+				}
+			}
+			j := f.index(x, y)
+			if j == -1 {
+				continue
+			}
+			g := n.g + cost
+			turns := n.turns
+			if n.dir != -1 && n.dir != dir {
+				turns++
+			}
+
+			m := &f.nodes[j]
+			if !m.opened {
+				*m = node{
+					g:      g,
+					h:      heuristic(x, y),
+					turns:  turns,
+					dir:    dir,
+					parent: i,
+					opened: true,
+				}
+				f.push(j)
+				continue
+			}
+			if m.closed {
+				continue
+			}
+			if g > m.g || (g == m.g && turns >= m.turns) {
+				continue
+			}
+			m.g = g
+			m.turns = turns
+			m.dir = dir
+			m.parent = i
+			f.up(m.heapIndex)
+		}
+	}
+
+	if goal == -1 {
+		// There is no path, or the budget is exhausted.
+		if mustReachGoal || !exhausted || best == start {
+			return nil, 0, 0
+		}
+		bestX, bestY := f.pos(best)
+		return dirsToRouteCommands(f.route(best)), bestX, bestY
+	}
+	return adjustLastCommand(field, dirsToRouteCommands(f.route(goal)), goalX, goalY, mustReachGoal)
+}
+
+// route returns the directions from the node at index to the start in the reversed order.
+func (f *finder) route(index int) []data.Dir {
+	dirs := []data.Dir{}
+	for n := &f.nodes[index]; n.parent != -1; n = &f.nodes[n.parent] {
+		dirs = append(dirs, n.dir)
+	}
+	return dirs
+}
@@ -31,33 +31,104 @@ const (
 	RouteCommandTurnRight
 	RouteCommandTurnDown
 	RouteCommandTurnLeft
+	RouteCommandMoveUpRight
+	RouteCommandMoveDownRight
+	RouteCommandMoveDownLeft
+	RouteCommandMoveUpLeft
+	RouteCommandTurnUpRight
+	RouteCommandTurnDownRight
+	RouteCommandTurnDownLeft
+	RouteCommandTurnUpLeft
 )
 
+var moveCommands = map[data.Dir]RouteCommand{
+	data.DirUp:        RouteCommandMoveUp,
+	data.DirRight:     RouteCommandMoveRight,
+	data.DirDown:      RouteCommandMoveDown,
+	data.DirLeft:      RouteCommandMoveLeft,
+	data.DirUpRight:   RouteCommandMoveUpRight,
+	data.DirDownRight: RouteCommandMoveDownRight,
+	data.DirDownLeft:  RouteCommandMoveDownLeft,
+	data.DirUpLeft:    RouteCommandMoveUpLeft,
+}
+
+var turnCommands = map[data.Dir]RouteCommand{
+	data.DirUp:        RouteCommandTurnUp,
+	data.DirRight:     RouteCommandTurnRight,
+	data.DirDown:      RouteCommandTurnDown,
+	data.DirLeft:      RouteCommandTurnLeft,
+	data.DirUpRight:   RouteCommandTurnUpRight,
+	data.DirDownRight: RouteCommandTurnDownRight,
+	data.DirDownLeft:  RouteCommandTurnDownLeft,
+	data.DirUpLeft:    RouteCommandTurnUpLeft,
+}
+
+// Dir returns the direction to move or turn.
+func (r RouteCommand) Dir() data.Dir {
+	for d, c := range moveCommands {
+		if c == r {
+			return d
+		}
+	}
+	for d, c := range turnCommands {
+		if c == r {
+			return d
+		}
+	}
+	panic(fmt.Sprintf("path: invalid command: %d at Dir", r))
+}
+
+// TurnRouteCommand returns the route command to turn to dir.
+func TurnRouteCommand(dir data.Dir) RouteCommand {
+	c, ok := turnCommands[dir]
+	if !ok {
+		panic(fmt.Sprintf("path: invalid dir: %d at TurnRouteCommand", dir))
+	}
+	return c
+}
+
+// IsTurn reports whether the command is turning without moving.
+func (r RouteCommand) IsTurn() bool {
+	for _, c := range turnCommands {
+		if c == r {
+			return true
+		}
+	}
+	return false
+}
+
 type Passable interface {
 	At(x, y int) bool
 }
 
+// DirPassable is an optional interface for Passable to restrict moves by directions.
+//
+// AtDir reports whether a character can move from (x, y) to the adjacent tile in dir.
+// AtDir is called only when At returns true for the adjacent tile.
+type DirPassable interface {
+	AtDir(x, y int, dir data.Dir) bool
+}
+
 func Calc(passable Passable, startX, startY, goalX, goalY int, mustReachGoal bool) ([]RouteCommand, int, int) {
 	type pos struct {
 		X, Y int
 	}
+	dirPassable, _ := passable.(DirPassable)
 	current := []pos{{startX, startY}}
 	parents := map[pos]pos{}
 	for 0 < len(current) {
 		next := []pos{}
 		for _, p := range current {
-			successors := []pos{
-				{p.X + 1, p.Y},
-				{p.X - 1, p.Y},
-				{p.X, p.Y + 1},
-				{p.X, p.Y - 1},
-			}
-			for _, s := range successors {
+			for _, dir := range []data.Dir{data.DirRight, data.DirLeft, data.DirDown, data.DirUp} {
+				dx, dy := dir.Delta()
+				s := pos{p.X + dx, p.Y + dy}
 				if !passable.At(s.X, s.Y) {
 					// It's OK even if the final destination is not passable so far.
 					if s.X != goalX || s.Y != goalY {
 						continue
 					}
+				} else if dirPassable != nil && !dirPassable.AtDir(p.X, p.Y, dir) {
+					continue
 				}
 				if _, ok := parents[s]; ok {
 					continue
@@ -93,43 +164,39 @@ func Calc(passable Passable, startX, startY, goalX, goalY int, mustReachGoal boo
 		}
 		p = parent
 	}
+	return adjustLastCommand(passable, dirsToRouteCommands(dirs), goalX, goalY, mustReachGoal)
+}
+
+// dirsToRouteCommands converts the directions in the reversed order to the route commands.
+func dirsToRouteCommands(dirs []data.Dir) []RouteCommand {
 	path := make([]RouteCommand, len(dirs))
 	for i, d := range dirs {
-		switch d {
-		case data.DirUp:
-			path[len(dirs)-i-1] = RouteCommandMoveUp
-		case data.DirRight:
-			path[len(dirs)-i-1] = RouteCommandMoveRight
-		case data.DirDown:
-			path[len(dirs)-i-1] = RouteCommandMoveDown
-		case data.DirLeft:
-			path[len(dirs)-i-1] = RouteCommandMoveLeft
-		default:
+		c, ok := moveCommands[d]
+		if !ok {
 			panic(fmt.Sprintf("path: invalid dir: %d", d))
 		}
+		path[len(dirs)-i-1] = c
 	}
+	return path
+}
+
+// adjustLastCommand replaces the last move with a turn when the goal is not passable.
+func adjustLastCommand(passable Passable, path []RouteCommand, goalX, goalY int, mustReachGoal bool) ([]RouteCommand, int, int) {
 	lastP := passable.At(goalX, goalY)
 	if !lastP && mustReachGoal {
 		return nil, 0, 0
 	}
 	lastX, lastY := goalX, goalY
 	if !lastP && len(path) > 0 {
-		switch path[len(path)-1] {
-		case RouteCommandMoveUp:
-			path[len(path)-1] = RouteCommandTurnUp
-			lastY++
-		case RouteCommandMoveRight:
-			path[len(path)-1] = RouteCommandTurnRight
-			lastX--
-		case RouteCommandMoveDown:
-			path[len(path)-1] = RouteCommandTurnDown
-			lastY--
-		case RouteCommandMoveLeft:
-			path[len(path)-1] = RouteCommandTurnLeft
-			lastX++
-		default:
-			panic(fmt.Sprintf("path: invalid command: %d at Calc", path[len(path)-1]))
+		last := path[len(path)-1]
+		if last.IsTurn() {
+			panic(fmt.Sprintf("path: invalid command: %d at Calc", last))
 		}
+		d := last.Dir()
+		path[len(path)-1] = turnCommands[d]
+		dx, dy := d.Delta()
+		lastX -= dx
+		lastY -= dy
 	}
 	return path, lastX, lastY
 }
@@ -137,74 +204,23 @@ func Calc(passable Passable, startX, startY, goalX, goalY int, mustReachGoal boo
 func RouteCommandsToEventCommands(path []RouteCommand) []*data.Command {
 	commands := []*data.Command{}
 	for _, r := range path {
-		switch r {
-		case RouteCommandMoveUp:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameMoveCharacter,
-				Args: &data.CommandArgsMoveCharacter{
-					Type:     data.MoveCharacterTypeDirection,
-					Dir:      data.DirUp,
-					Distance: 1,
-				},
-			})
-		case RouteCommandMoveRight:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameMoveCharacter,
-				Args: &data.CommandArgsMoveCharacter{
-					Type:     data.MoveCharacterTypeDirection,
-					Dir:      data.DirRight,
-					Distance: 1,
-				},
-			})
-		case RouteCommandMoveDown:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameMoveCharacter,
-				Args: &data.CommandArgsMoveCharacter{
-					Type:     data.MoveCharacterTypeDirection,
-					Dir:      data.DirDown,
-					Distance: 1,
-				},
-			})
-		case RouteCommandMoveLeft:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameMoveCharacter,
-				Args: &data.CommandArgsMoveCharacter{
-					Type:     data.MoveCharacterTypeDirection,
-					Dir:      data.DirLeft,
-					Distance: 1,
-				},
-			})
-		case RouteCommandTurnUp:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameTurnCharacter,
-				Args: &data.CommandArgsTurnCharacter{
-					Dir: data.DirUp,
-				},
-			})
-		case RouteCommandTurnRight:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameTurnCharacter,
-				Args: &data.CommandArgsTurnCharacter{
-					Dir: data.DirRight,
-				},
-			})
-		case RouteCommandTurnDown:
+		if r.IsTurn() {
 			commands = append(commands, &data.Command{
 				Name: data.CommandNameTurnCharacter,
 				Args: &data.CommandArgsTurnCharacter{
-					Dir: data.DirDown,
+					Dir: r.Dir(),
 				},
 			})
-		case RouteCommandTurnLeft:
-			commands = append(commands, &data.Command{
-				Name: data.CommandNameTurnCharacter,
-				Args: &data.CommandArgsTurnCharacter{
-					Dir: data.DirLeft,
-				},
-			})
-		default:
-			panic(fmt.Sprintf("path: invalid command: %d at RouteCommandsToEventCommands", r))
+			continue
 		}
+		commands = append(commands, &data.Command{
+			Name: data.CommandNameMoveCharacter,
+			Args: &data.CommandArgsMoveCharacter{
+				Type:     data.MoveCharacterTypeDirection,
+				Dir:      r.Dir(),
+				Distance: 1,
+			},
+		})
 	}
 	return commands
 }
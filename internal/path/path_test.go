@@ -0,0 +1,467 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package path_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/path"
+)
+
+// grid is a Field and a Coster.
+// '#' is a wall, '~' is a tile whose cost is 5, and other characters are tiles whose cost is 1.
+type grid []string
+
+func (g grid) At(x, y int) bool {
+	if y < 0 || len(g) <= y || x < 0 || len(g[y]) <= x {
+		return false
+	}
+	return g[y][x] != '#'
+}
+
+func (g grid) Size() (int, int) {
+	return len(g[0]), len(g)
+}
+
+func (g grid) Cost(x, y int) int {
+	if g[y][x] == '~' {
+		return 5
+	}
+	return 1
+}
+
+// atOnly hides Cost of a grid.
+type atOnly struct {
+	g grid
+}
+
+func (a atOnly) At(x, y int) bool {
+	return a.g.At(x, y)
+}
+
+func (a atOnly) Size() (int, int) {
+	return a.g.Size()
+}
+
+// oneWay is a Passable and a DirPassable.
+// 'v' is a tile that cannot be entered from the upper tile.
+type oneWay struct {
+	g grid
+}
+
+func (o oneWay) At(x, y int) bool {
+	return o.g.At(x, y)
+}
+
+func (o oneWay) Size() (int, int) {
+	return o.g.Size()
+}
+
+func (o oneWay) AtDir(x, y int, dir data.Dir) bool {
+	dx, dy := dir.Delta()
+	return !(dir == data.DirDown && o.g[y+dy][x+dx] == 'v')
+}
+
+const (
+	u = RouteCommandMoveUp
+	r = RouteCommandMoveRight
+	d = RouteCommandMoveDown
+)
+
+func countTurns(path []RouteCommand) int {
+	n := 0
+	for i := 1; i < len(path); i++ {
+		if path[i] != path[i-1] {
+			n++
+		}
+	}
+	return n
+}
+
+func equalPaths(a, b []RouteCommand) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCalcAStar(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Grid          grid
+		Coster        bool
+		StartX        int
+		StartY        int
+		GoalX         int
+		GoalY         int
+		MustReachGoal bool
+		MaxNodes      int
+		Out           []RouteCommand
+		LastX         int
+		LastY         int
+	}{
+		{
+			Name: "straight",
+			Grid: grid{
+				".....",
+			},
+			StartX: 0,
+			StartY: 0,
+			GoalX:  4,
+			GoalY:  0,
+			Out:    []RouteCommand{r, r, r, r},
+			LastX:  4,
+			LastY:  0,
+		},
+		{
+			Name: "wall",
+			Grid: grid{
+				"..#..",
+				"..#..",
+				".....",
+			},
+			StartX: 0,
+			StartY: 0,
+			GoalX:  4,
+			GoalY:  0,
+			Out:    []RouteCommand{r, d, d, r, r, r, u, u},
+			LastX:  4,
+			LastY:  0,
+		},
+		{
+			Name: "avoid water",
+			Grid: grid{
+				"~~~~~",
+				".....",
+			},
+			Coster: true,
+			StartX: 0,
+			StartY: 0,
+			GoalX:  4,
+			GoalY:  0,
+			Out:    []RouteCommand{d, r, r, r, r, u},
+			LastX:  4,
+			LastY:  0,
+		},
+		{
+			Name: "ignore cost without Coster",
+			Grid: grid{
+				"~~~~~",
+				".....",
+			},
+			StartX: 0,
+			StartY: 0,
+			GoalX:  4,
+			GoalY:  0,
+			Out:    []RouteCommand{r, r, r, r},
+			LastX:  4,
+			LastY:  0,
+		},
+		{
+			Name: "unpassable goal",
+			Grid: grid{
+				"...#",
+			},
+			StartX: 0,
+			StartY: 0,
+			GoalX:  3,
+			GoalY:  0,
+			Out:    []RouteCommand{r, r, RouteCommandTurnRight},
+			LastX:  2,
+			LastY:  0,
+		},
+		{
+			Name: "goal outside the field",
+			Grid: grid{
+				"...",
+			},
+			StartX: 0,
+			StartY: 0,
+			GoalX:  3,
+			GoalY:  0,
+			Out:    []RouteCommand{r, r, RouteCommandTurnRight},
+			LastX:  2,
+			LastY:  0,
+		},
+		{
+			Name: "unpassable goal must be reached",
+			Grid: grid{
+				"...#",
+			},
+			StartX:        0,
+			StartY:        0,
+			GoalX:         3,
+			GoalY:         0,
+			MustReachGoal: true,
+			Out:           nil,
+		},
+		{
+			Name: "no path",
+			Grid: grid{
+				"..#..",
+			},
+			StartX: 0,
+			StartY: 0,
+			GoalX:  4,
+			GoalY:  0,
+			Out:    nil,
+		},
+		{
+			Name: "budget",
+			Grid: grid{
+				"..........",
+			},
+			StartX:   0,
+			StartY:   0,
+			GoalX:    9,
+			GoalY:    0,
+			MaxNodes: 4,
+			Out:      []RouteCommand{r, r, r},
+			LastX:    3,
+			LastY:    0,
+		},
+		{
+			Name: "budget must be reached",
+			Grid: grid{
+				"..........",
+			},
+			StartX:        0,
+			StartY:        0,
+			GoalX:         9,
+			GoalY:         0,
+			MustReachGoal: true,
+			MaxNodes:      4,
+			Out:           nil,
+		},
+	}
+	for _, c := range cases {
+		var p Field = atOnly{c.Grid}
+		if c.Coster {
+			p = c.Grid
+		}
+		got, lastX, lastY := CalcAStar(p, c.StartX, c.StartY, c.GoalX, c.GoalY, c.MustReachGoal, c.MaxNodes)
+		if !equalPaths(got, c.Out) {
+			t.Errorf("%s: got: %v, want: %v", c.Name, got, c.Out)
+			continue
+		}
+		if c.Out == nil {
+			continue
+		}
+		if lastX != c.LastX || lastY != c.LastY {
+			t.Errorf("%s: got: (%d, %d), want: (%d, %d)", c.Name, lastX, lastY, c.LastX, c.LastY)
+		}
+	}
+}
+
+func TestCalcAStarWithDiagonals(t *testing.T) {
+	const (
+		dr = RouteCommandMoveDownRight
+		ur = RouteCommandMoveUpRight
+	)
+	cases := []struct {
+		Name  string
+		Grid  grid
+		GoalX int
+		GoalY int
+		Out   []RouteCommand
+	}{
+		{
+			Name: "diagonal",
+			Grid: grid{
+				"....",
+				"....",
+				"....",
+				"....",
+			},
+			GoalX: 3,
+			GoalY: 3,
+			Out:   []RouteCommand{dr, dr, dr},
+		},
+		{
+			Name: "diagonal and straight",
+			Grid: grid{
+				".....",
+				".....",
+			},
+			GoalX: 4,
+			GoalY: 1,
+			Out:   []RouteCommand{dr, r, r, r},
+		},
+		{
+			Name: "no corner cutting",
+			Grid: grid{
+				".#",
+				"..",
+			},
+			GoalX: 1,
+			GoalY: 1,
+			Out:   []RouteCommand{d, r},
+		},
+		{
+			Name: "around a wall",
+			Grid: grid{
+				"..#..",
+				"..#..",
+				".....",
+			},
+			GoalX: 4,
+			GoalY: 0,
+			Out:   []RouteCommand{d, dr, r, r, ur, u},
+		},
+	}
+	for _, c := range cases {
+		got, lastX, lastY := CalcAStarWithDiagonals(atOnly{c.Grid}, 0, 0, c.GoalX, c.GoalY, true, 0)
+		if len(got) != len(c.Out) {
+			t.Errorf("%s: got: %v, want: %v", c.Name, got, c.Out)
+			continue
+		}
+		if lastX != c.GoalX || lastY != c.GoalY {
+			t.Errorf("%s: got: (%d, %d), want: (%d, %d)", c.Name, lastX, lastY, c.GoalX, c.GoalY)
+		}
+		// Check the route is valid without cutting corners.
+		x, y := 0, 0
+		for _, cmd := range got {
+			dx, dy := cmd.Dir().Delta()
+			if !c.Grid.At(x+dx, y+dy) || !c.Grid.At(x+dx, y) || !c.Grid.At(x, y+dy) {
+				t.Errorf("%s: invalid route: %v", c.Name, got)
+				break
+			}
+			x += dx
+			y += dy
+		}
+	}
+}
+
+func TestDirPassable(t *testing.T) {
+	g := oneWay{grid{
+		"..",
+		"v.",
+	}}
+	want := []RouteCommand{r, d, RouteCommandMoveLeft}
+	if got, _, _ := Calc(g, 0, 0, 0, 1, true); !equalPaths(got, want) {
+		t.Errorf("Calc: got: %v, want: %v", got, want)
+	}
+	if got, _, _ := CalcAStar(g, 0, 0, 0, 1, true, 0); !equalPaths(got, want) {
+		t.Errorf("CalcAStar: got: %v, want: %v", got, want)
+	}
+}
+
+func TestCalcAStarPreferStraightLines(t *testing.T) {
+	g := grid{
+		"......",
+		"......",
+		"......",
+		"......",
+	}
+	got, _, _ := CalcAStar(atOnly{g}, 0, 0, 5, 3, true, 0)
+	if len(got) != 8 {
+		t.Fatalf("len(path): got: %d, want: %d", len(got), 8)
+	}
+	if n := countTurns(got); n != 1 {
+		t.Errorf("turns: got: %d (%v), want: %d", n, got, 1)
+	}
+}
+
+func TestCalcAStarSameLengthAsCalc(t *testing.T) {
+	for _, g := range []grid{benchGrid, benchMaze} {
+		for _, goal := range [][2]int{{9, 21}, {9, 0}, {0, 21}, {5, 10}} {
+			want, _, _ := Calc(atOnly{g}, 0, 0, goal[0], goal[1], false)
+			got, _, _ := CalcAStar(atOnly{g}, 0, 0, goal[0], goal[1], false, 0)
+			if len(got) != len(want) {
+				t.Errorf("goal: %v: len(path): got: %d, want: %d", goal, len(got), len(want))
+			}
+		}
+	}
+}
+
+var benchGrid = grid{
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+	"..........",
+}
+
+var benchMaze = grid{
+	"..........",
+	"########..",
+	"..........",
+	"..########",
+	"..........",
+	"########..",
+	"..........",
+	"..########",
+	"..........",
+	"########..",
+	"..........",
+	"..########",
+	"..........",
+	"########..",
+	"..........",
+	"..########",
+	"..........",
+	"########..",
+	"..........",
+	"..########",
+	"..........",
+	"..........",
+}
+
+func BenchmarkCalcOpen(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Calc(atOnly{benchGrid}, 0, 0, 9, 21, true)
+	}
+}
+
+func BenchmarkCalcAStarOpen(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		CalcAStar(atOnly{benchGrid}, 0, 0, 9, 21, true, 0)
+	}
+}
+
+func BenchmarkCalcMaze(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Calc(atOnly{benchMaze}, 0, 0, 9, 21, true)
+	}
+}
+
+func BenchmarkCalcAStarMaze(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		CalcAStar(atOnly{benchMaze}, 0, 0, 9, 21, true, 0)
+	}
+}
@@ -66,6 +66,7 @@ type Manager struct {
 	game                  *data.Game
 	progress              []byte
 	permanent             *Permanent
+	unsavedReadTexts      bool
 	purchases             []string
 	interstitialAdsLoaded bool
 	rewardedAdsLoaded     bool
@@ -218,39 +219,22 @@ func (m *Manager) Update() error {
 	triggerBack := false
 	select {
 	case r := <-m.resultCh:
-		m.results[r.ID] = &r
-		switch r.Type {
-		case RequestTypeInterstitialAds:
-			m.interstitialAdsLoaded = false
-		case RequestTypeRewardedAds:
-			m.rewardedAdsLoaded = false
-		case RequestTypePurchase, RequestTypeRestorePurchases, RequestTypeShowShop:
-			if r.Succeeded {
-				var purchases []string
-				if err := json.Unmarshal(r.Data, &purchases); err != nil {
-					return err
-				}
-				m.purchases = purchases
+		// While replaying, the recorded results are used instead.
+		if !input.IsReplaying() {
+			input.RecordResult(r.ID, int(r.Type), r.Succeeded, r.Data)
+			if err := m.applyResult(&r); err != nil {
+				return err
 			}
-		default:
-			// There is no action here. It's ok to ignore.
 		}
 	case a := <-m.setPlatformDataCh:
-		switch a.key {
-		case PlatformDataKeyInterstitialAdsLoaded:
-			m.interstitialAdsLoaded = true
-		case PlatformDataKeyRewardedAdsLoaded:
-			m.rewardedAdsLoaded = true
-		case PlatformDataKeyBackButton:
-			triggerBack = true
-		case PlatformDataKeyCredits:
-			var credits *data.Credits
-			if err := json.Unmarshal([]byte(a.value), &credits); err != nil {
+		// While replaying, the recorded platform data is used instead.
+		if !input.IsReplaying() {
+			input.RecordPlatformData(string(a.key), a.value)
+			t, err := m.applyPlatformData(a.key, a.value)
+			if err != nil {
 				return err
 			}
-			m.credits = credits
-		default:
-			log.Printf("platform data key not implemented: %s", a.key)
+			triggerBack = t
 		}
 	default:
 	}
@@ -302,6 +286,25 @@ func (m *Manager) Update() error {
 	}
 	for i := 0; i < n; i++ {
 		input.Update(m.widthScale(), 1)
+		for _, d := range input.ReplayedPlatformData() {
+			t, err := m.applyPlatformData(PlatformDataKey(d.Key), d.Value)
+			if err != nil {
+				return err
+			}
+			if t {
+				triggerBack = true
+			}
+		}
+		for _, r := range input.ReplayedResults() {
+			if err := m.applyResult(&RequestResult{
+				ID:        r.ID,
+				Type:      RequestType(r.Type),
+				Succeeded: r.Succeeded,
+				Data:      r.Data,
+			}); err != nil {
+				return err
+			}
+		}
 		if triggerBack {
 			input.TriggerBackButton()
 			triggerBack = false
@@ -325,6 +328,48 @@ func (m *Manager) Update() error {
 	return nil
 }
 
+func (m *Manager) applyResult(r *RequestResult) error {
+	m.results[r.ID] = r
+	switch r.Type {
+	case RequestTypeInterstitialAds:
+		m.interstitialAdsLoaded = false
+	case RequestTypeRewardedAds:
+		m.rewardedAdsLoaded = false
+	case RequestTypePurchase, RequestTypeRestorePurchases, RequestTypeShowShop:
+		if r.Succeeded {
+			var purchases []string
+			if err := json.Unmarshal(r.Data, &purchases); err != nil {
+				return err
+			}
+			m.purchases = purchases
+		}
+	default:
+		// There is no action here. It's ok to ignore.
+	}
+	return nil
+}
+
+// applyPlatformData applies the platform data and reports whether the back button should be triggered.
+func (m *Manager) applyPlatformData(key PlatformDataKey, value string) (bool, error) {
+	switch key {
+	case PlatformDataKeyInterstitialAdsLoaded:
+		m.interstitialAdsLoaded = true
+	case PlatformDataKeyRewardedAdsLoaded:
+		m.rewardedAdsLoaded = true
+	case PlatformDataKeyBackButton:
+		return true, nil
+	case PlatformDataKeyCredits:
+		var credits *data.Credits
+		if err := json.Unmarshal([]byte(value), &credits); err != nil {
+			return false, err
+		}
+		m.credits = credits
+	default:
+		log.Printf("platform data key not implemented: %s", key)
+	}
+	return false, nil
+}
+
 func (m *Manager) ShareScreenshot() {
 	m.needsSharingScreenshot = true
 }
@@ -610,11 +655,7 @@ func (m *Manager) RequestRewardedAds(requestID int, forceAds bool) {
 func (m *Manager) RequestSaveVolume(requestID int, seVolume int, bgmVolume int) {
 	m.permanent.SEMute = 100 - seVolume
 	m.permanent.BGMMute = 100 - bgmVolume
-	bytes, err := msgpack.Marshal(m.permanent)
-	if err != nil {
-		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
-	}
-	m.Requester().RequestSavePermanent(requestID, bytes)
+	m.requestSavePermanent(requestID)
 }
 
 func (m *Manager) RequestSavePermanentVariable(requestID int, permanentVariableID int, value int64) {
@@ -624,20 +665,61 @@ func (m *Manager) RequestSavePermanentVariable(requestID int, permanentVariableI
 	}
 	m.permanent.Variables[permanentVariableID] = value
 
-	bytes, err := msgpack.Marshal(m.permanent)
-	if err != nil {
-		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
-	}
-	m.Requester().RequestSavePermanent(requestID, bytes)
+	m.requestSavePermanent(requestID)
 }
 
 func (m *Manager) RequestSaveVibrationEnabled(requestID int, vibrationEnabled bool) {
 	m.permanent.VibrationDisabled = !vibrationEnabled
+	m.requestSavePermanent(requestID)
+}
+
+func (m *Manager) RequestSaveAutoAdvance(requestID int, autoAdvance bool) {
+	m.permanent.AutoAdvance = autoAdvance
+	m.requestSavePermanent(requestID)
+}
+
+func (m *Manager) RequestSaveSkipRead(requestID int, skipRead bool) {
+	m.permanent.SkipRead = skipRead
+	m.requestSavePermanent(requestID)
+}
+
+// IsTextRead reports whether the text has been shown in a message before.
+func (m *Manager) IsTextRead(id data.UUID) bool {
+	return m.permanent.ReadTexts[id.String()]
+}
+
+// MarkTextRead marks the text as read.
+// The read texts are kept in memory until the permanent data is saved next time:
+// when the progress is saved, when the game goes back to the title, or when a setting is saved in the settings scenes.
+func (m *Manager) MarkTextRead(id data.UUID) {
+	if m.IsTextRead(id) {
+		return
+	}
+	if m.permanent.ReadTexts == nil {
+		m.permanent.ReadTexts = map[string]bool{}
+	}
+	m.permanent.ReadTexts[id.String()] = true
+	m.unsavedReadTexts = true
+}
+
+// HasUnsavedReadTexts reports whether there are texts marked as read that have not been saved yet.
+func (m *Manager) HasUnsavedReadTexts() bool {
+	return m.unsavedReadTexts
+}
+
+// RequestSaveReadTexts requests to save the permanent data with the texts marked as read.
+func (m *Manager) RequestSaveReadTexts(requestID int) {
+	m.requestSavePermanent(requestID)
+}
+
+// requestSavePermanent saves the whole permanent data, including the texts marked as read.
+func (m *Manager) requestSavePermanent(requestID int) {
 	bytes, err := msgpack.Marshal(m.permanent)
 	if err != nil {
 		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
 	}
 	m.Requester().RequestSavePermanent(requestID, bytes)
+	m.unsavedReadTexts = false
 }
 
 func (m *Manager) PermanentVariableValue(id int) int64 {
@@ -652,6 +734,14 @@ func (m *Manager) VibrationEnabled() bool {
 	return !m.permanent.VibrationDisabled
 }
 
+func (m *Manager) AutoAdvance() bool {
+	return m.permanent.AutoAdvance
+}
+
+func (m *Manager) SkipRead() bool {
+	return m.permanent.SkipRead
+}
+
 func (m *Manager) BGMVolume() int {
 	return 100 - m.permanent.BGMMute
 }
@@ -667,11 +757,7 @@ func (m *Manager) RequestSavePermanentMinigame(requestID int, minigameID, score
 	}
 	m.permanent.Minigames[minigameID] = &MinigameData{Score: score, LastActiveAt: lastActiveAt}
 
-	bytes, err := msgpack.Marshal(m.permanent)
-	if err != nil {
-		panic(fmt.Sprintf("scene: msgpack encoding error: %v", err))
-	}
-	m.Requester().RequestSavePermanent(requestID, bytes)
+	m.requestSavePermanent(requestID)
 }
 
 func (m *Manager) PermanentMinigame(id int) *MinigameData {
@@ -25,4 +25,9 @@ type Permanent struct {
 	BGMMute           int             `msgpack:"bgm_mute"`
 	SEMute            int             `msgpack:"se_mute"`
 	VibrationDisabled bool            `msgpack:"vibrationDisabled"`
+	AutoAdvance       bool            `msgpack:"autoAdvance"`
+	SkipRead          bool            `msgpack:"skipRead"`
+
+	// ReadTexts is the IDs of the texts that have been shown in messages, across games.
+	ReadTexts map[string]bool `msgpack:"readTexts"`
 }
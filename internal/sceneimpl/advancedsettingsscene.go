@@ -36,6 +36,10 @@ type AdvancedSettingsScene struct {
 	languageButton   *ui.Button
 	vibrationLabel   *ui.Label
 	vibrationButton  *ui.SwitchButton
+	autoLabel        *ui.Label
+	autoButton       *ui.SwitchButton
+	skipReadLabel    *ui.Label
+	skipReadButton   *ui.SwitchButton
 	resetGameButton  *ui.Button
 	bgmLabel         *ui.Label
 	bgmSlider        *ui.Slider
@@ -72,7 +76,16 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 	s.seSlider = ui.NewSlider(s.baseX+48, s.calcButtonY(3), 50, 0, 100, sceneManager.SEVolume())
 	s.vibrationLabel = ui.NewLabel(s.baseX, s.calcButtonY(4)+4)
 	s.vibrationButton = ui.NewSwitchButton(s.baseX+72, s.calcButtonY(4), sceneManager.VibrationEnabled())
-	s.resetGameButton = ui.NewButton(s.baseX, s.calcButtonY(5), 120, 20, "system/click")
+	// The rows below the vibration are moved up when the vibration is not available.
+	row := 5
+	if !sceneManager.Game().System.Vibration {
+		row = 4
+	}
+	s.autoLabel = ui.NewLabel(s.baseX, s.calcButtonY(row)+4)
+	s.autoButton = ui.NewSwitchButton(s.baseX+72, s.calcButtonY(row), sceneManager.AutoAdvance())
+	s.skipReadLabel = ui.NewLabel(s.baseX, s.calcButtonY(row+1)+4)
+	s.skipReadButton = ui.NewSwitchButton(s.baseX+72, s.calcButtonY(row+1), sceneManager.SkipRead())
+	s.resetGameButton = ui.NewButton(s.baseX, s.calcButtonY(row+2), 120, 20, "system/click")
 	s.closeButton = ui.NewButton(s.baseX, s.calcButtonY(8), 120, 20, "system/cancel")
 
 	s.languageDialog = ui.NewDialog((w/consts.TileScale-160)/2+4, h/(2*consts.TileScale)-80, 152, 160)
@@ -104,6 +117,16 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 		sceneManager.RequestSaveVibrationEnabled(s.waitingRequestID, value)
 	})
 
+	s.autoButton.SetOnToggled(func(_ *ui.SwitchButton, value bool) {
+		s.waitingRequestID = sceneManager.GenerateRequestID()
+		sceneManager.RequestSaveAutoAdvance(s.waitingRequestID, value)
+	})
+
+	s.skipReadButton.SetOnToggled(func(_ *ui.SwitchButton, value bool) {
+		s.waitingRequestID = sceneManager.GenerateRequestID()
+		sceneManager.RequestSaveSkipRead(s.waitingRequestID, value)
+	})
+
 	s.bgmSlider.SetOnValueChanged(func(slider *ui.Slider, value int) {
 		audio.SetBGMVolume(float64(value) / 100.0)
 	})
@@ -152,14 +175,13 @@ func (s *AdvancedSettingsScene) initUI(sceneManager *scene.Manager) {
 		s.vibrationButton.Hide()
 	}
 
-	if !sceneManager.Game().System.Vibration {
-		s.resetGameButton.SetY(s.calcButtonY(4))
-	}
 }
 
 func (s *AdvancedSettingsScene) updateButtonTexts() {
 	s.settingsLabel.Text = texts.Text(lang.Get(), texts.TextIDAdvancedSettings)
 	s.vibrationLabel.Text = texts.Text(lang.Get(), texts.TextIDVibration)
+	s.autoLabel.Text = texts.Text(lang.Get(), texts.TextIDAutoAdvance)
+	s.skipReadLabel.Text = texts.Text(lang.Get(), texts.TextIDSkipRead)
 	s.languageButton.SetText(texts.Text(lang.Get(), texts.TextIDLanguage))
 	s.bgmLabel.Text = texts.Text(lang.Get(), texts.TextIDBGMVolume)
 	s.seLabel.Text = texts.Text(lang.Get(), texts.TextIDSEVolume)
@@ -203,6 +225,10 @@ func (s *AdvancedSettingsScene) Update(sceneManager *scene.Manager) error {
 		s.resetGameButton.Update()
 		s.vibrationLabel.Update()
 		s.vibrationButton.Update()
+		s.autoLabel.Update()
+		s.autoButton.Update()
+		s.skipReadLabel.Update()
+		s.skipReadButton.Update()
 		s.bgmLabel.Update()
 		s.bgmSlider.Update()
 		s.seLabel.Update()
@@ -247,6 +273,10 @@ func (s *AdvancedSettingsScene) Draw(screen *ebiten.Image) {
 	s.closeButton.Draw(screen)
 	s.vibrationLabel.Draw(screen)
 	s.vibrationButton.Draw(screen)
+	s.autoLabel.Draw(screen)
+	s.autoButton.Draw(screen)
+	s.skipReadLabel.Draw(screen)
+	s.skipReadButton.Draw(screen)
 	s.languageDialog.Draw(screen)
 	s.warningDialog.Draw(screen)
 }
@@ -30,6 +30,12 @@ func (a *animation) Update() {
 	a.counter++
 }
 
+// Count returns the number of the updates. This is the clock of the animations in the map scene,
+// e.g. the background, the animated tiles and the scrolling layers.
+func (a *animation) Count() int {
+	return a.counter
+}
+
 func (a *animation) Draw(screen *ebiten.Image, texture *ebiten.Image, frameWidth, offsetX, offsetY int) {
 	op := &ebiten.DrawImageOptions{}
 	w, h := texture.Size()
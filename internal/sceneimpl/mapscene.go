@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"sort"
 
 	"github.com/hajimehoshi/ebiten"
 
@@ -34,6 +35,7 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/texts"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/tileset"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/ui"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/window"
 )
 
 const (
@@ -65,13 +67,18 @@ type MapScene struct {
 	minigamePopup        *ui.MinigamePopup
 	titleView            *ui.TitleView
 	credits              *ui.Credits
+	backlog              *ui.Backlog
 	markerAnimationFrame int
 	waitingRequestID     int
 	initialized          bool
+	offsetX              int
 	offsetY              int
+	layoutOffsetX        int
+	layoutOffsetY        int
 	windowOffsetY        int
 	inventoryHeight      int
 	animation            animation
+	overTiles            overTiles
 
 	activeDebugPanel *debug.DebugPanel
 	debugPanels      map[debug.DebugPanelType]*debug.DebugPanel
@@ -79,9 +86,9 @@ type MapScene struct {
 	err error
 }
 
-func NewMapScene() *MapScene {
+func NewMapScene(sceneManager *scene.Manager) *MapScene {
 	m := &MapScene{
-		gameState:    gamestate.NewGame(),
+		gameState:    gamestate.NewGame(sceneManager.Game().System),
 		initialState: true,
 	}
 	return m
@@ -96,8 +103,8 @@ func NewMapSceneWithGame(game *gamestate.Game) *MapScene {
 
 type sceneMaker struct{}
 
-func (s *sceneMaker) NewMapScene() scene.Scene {
-	return NewMapScene()
+func (s *sceneMaker) NewMapScene(sceneManager *scene.Manager) scene.Scene {
+	return NewMapScene(sceneManager)
 }
 
 func (s *sceneMaker) NewMapSceneWithGame(game *gamestate.Game) scene.Scene {
@@ -116,14 +123,90 @@ func NewTitleMapScene(savedGame *gamestate.Game) *MapScene {
 	return m
 }
 
+func (m *MapScene) updateOffset(sceneManager *scene.Manager) {
+	if x, y, ok := m.gameState.CameraCenter(); ok {
+		m.updateOffsetByCamera(sceneManager, x, y)
+	} else {
+		m.updateOffsetX()
+		m.updateOffsetY(sceneManager)
+	}
+	// The camera offset can show the outside of the room.
+	ox, oy := m.gameState.CameraOffset()
+	m.offsetX = m.layoutOffsetX + ox*consts.TileScale
+	m.offsetY = m.layoutOffsetY + oy*consts.TileScale
+}
+
+// updateOffsetByCamera updates the offsets so that (x, y) in the map is at the center of the screen.
+func (m *MapScene) updateOffsetByCamera(sceneManager *scene.Manager, x, y int) {
+	_, sh := sceneManager.Size()
+	w, h := m.gameState.Map().CurrentRoom().Size()
+	roomWidth := w * consts.TileSize * consts.TileScale
+	roomHeight := h * consts.TileSize * consts.TileScale
+
+	if roomWidth <= consts.MapScaledWidth {
+		m.layoutOffsetX = (consts.MapScaledWidth - roomWidth) / 2
+	} else {
+		tx := -x*consts.TileScale + consts.MapScaledWidth/2
+		if tx > 0 {
+			tx = 0
+		}
+		if tx < consts.MapScaledWidth-roomWidth {
+			tx = consts.MapScaledWidth - roomWidth
+		}
+		m.layoutOffsetX = tx
+	}
+
+	bottomOffset := consts.TileSize * consts.TileScale
+	ty := -y*consts.TileScale + sh/2
+	if ty > 0 {
+		ty = 0
+	}
+	if ty < sh-roomHeight+bottomOffset {
+		ty = sh - roomHeight + bottomOffset
+	}
+	m.layoutOffsetY = ty
+	m.windowOffsetY = 0
+}
+
+func (m *MapScene) updateOffsetX() {
+	w, _ := m.gameState.Map().CurrentRoom().Size()
+	roomWidth := w * consts.TileSize * consts.TileScale
+
+	// A room narrower than the screen is put at the center.
+	if roomWidth <= consts.MapScaledWidth {
+		m.layoutOffsetX = (consts.MapScaledWidth - roomWidth) / 2
+		return
+	}
+
+	// A room wider than the screen always scrolls horizontally regardless of the layout mode.
+	character := m.gameState.Map().FocusingCharacter()
+	// character can be nil for the very first Update() loop
+	if character == nil {
+		return
+	}
+	x, _ := character.DrawFootPosition()
+	t := -x*consts.TileScale + consts.MapScaledWidth/2
+
+	if t > 0 {
+		t = 0
+	}
+
+	if t < consts.MapScaledWidth-roomWidth {
+		t = consts.MapScaledWidth - roomWidth
+	}
+	m.layoutOffsetX = t
+}
+
 func (m *MapScene) updateOffsetY(sceneManager *scene.Manager) {
 	_, sh := sceneManager.Size()
+	_, h := m.gameState.Map().CurrentRoom().Size()
+	roomHeight := h * consts.TileSize * consts.TileScale
 
 	// In case the device is super large (iPhoneX),
 	// we do not do any of the layout work here
 	// as we are always going to show the fullscreen
-	if sh >= consts.SuperLargeScreenHeight {
-		m.offsetY = 0
+	if sh >= consts.SuperLargeScreenHeight && h <= consts.TileYNum {
+		m.layoutOffsetY = 0
 		m.windowOffsetY = sceneManager.BottomOffset()
 		return
 	}
@@ -131,12 +214,12 @@ func (m *MapScene) updateOffsetY(sceneManager *scene.Manager) {
 
 	switch m.gameState.Map().CurrentRoom().LayoutMode {
 	case data.RoomLayoutModeFixBottom:
-		m.offsetY = sh - consts.MapScaledHeight + bottomOffset
+		m.layoutOffsetY = sh - roomHeight + bottomOffset
 
 	case data.RoomLayoutModeFixCenter:
-		m.offsetY = (sh - consts.MapScaledHeight) / 2
+		m.layoutOffsetY = (sh - roomHeight) / 2
 		// Adjust the screen so that the bottom snaps to the grid
-		m.offsetY -= m.offsetY % (consts.TileSize * consts.TileScale)
+		m.layoutOffsetY -= m.layoutOffsetY % (consts.TileSize * consts.TileScale)
 
 	case data.RoomLayoutModeScroll:
 		character := m.gameState.Map().FocusingCharacter()
@@ -151,10 +234,10 @@ func (m *MapScene) updateOffsetY(sceneManager *scene.Manager) {
 			t = 0
 		}
 
-		if t < sh-consts.MapScaledHeight+bottomOffset {
-			t = sh - consts.MapScaledHeight + bottomOffset
+		if t < sh-roomHeight+bottomOffset {
+			t = sh - roomHeight + bottomOffset
 		}
-		m.offsetY = t
+		m.layoutOffsetY = t
 
 	default:
 		panic(fmt.Sprintf("invalid layout mode: %s", m.gameState.Map().CurrentRoom().LayoutMode))
@@ -215,11 +298,13 @@ func (m *MapScene) initUI(sceneManager *scene.Manager) {
 	m.quitDialog.AddChild(m.quitLabel)
 
 	m.credits = ui.NewCredits()
+	m.backlog = ui.NewBacklog(screenH)
 
 	m.quitYesButton.SetOnPressed(func(_ *ui.Button) {
 		if m.gameState.IsAutoSaveEnabled() && !m.gameState.Map().IsBlockingEventExecuting() && !m.gameState.Map().IsPlayerMovingByUserInput() {
 			m.gameState.RequestSave(0, sceneManager)
 		}
+		saveReadTexts(sceneManager)
 		audio.Stop()
 		g, err := savedGame(sceneManager)
 		if err != nil {
@@ -235,6 +320,9 @@ func (m *MapScene) initUI(sceneManager *scene.Manager) {
 		m.gameHeader.SetOnTitleButtonPressed(func() {
 			m.quitDialog.Show()
 		})
+		m.gameHeader.SetOnBacklogButtonPressed(func() {
+			m.showBacklog(sceneManager)
+		})
 		m.gameHeader.SetOnCameraButtonPressed(func() {
 			// TODO: Hide the game header
 			sceneManager.ShareScreenshot()
@@ -392,6 +480,8 @@ func (m *MapScene) runEventIfNeeded(sceneManager *scene.Manager) {
 		return
 	}
 
+	x, y = m.unzoom(x, y)
+	x -= m.offsetX
 	y -= m.offsetY
 	if x < 0 || y < 0 {
 		return
@@ -415,7 +505,8 @@ func (m *MapScene) runEventIfNeeded(sceneManager *scene.Manager) {
 	m.markerAnimationFrame = 0
 	// The bottom line of the map should not be tappable as that space is
 	// reserved to avoid conflict with iPhoneX's HomeIndicator
-	if tx < 0 || consts.TileXNum <= tx || ty < 0 || consts.TileYNum-1 <= ty {
+	w, h := m.gameState.Map().CurrentRoom().Size()
+	if tx < 0 || w <= tx || ty < 0 || h-1 <= ty {
 		return
 	}
 	m.moveDstX = tx
@@ -465,9 +556,25 @@ func (m *MapScene) isUIBusy() bool {
 	if m.credits.Visible() {
 		return true
 	}
+	if m.backlog.Visible() {
+		return true
+	}
 	return false
 }
 
+func (m *MapScene) showBacklog(sceneManager *scene.Manager) {
+	entries := []*ui.BacklogEntry{}
+	for _, t := range m.gameState.MessageLogTexts(sceneManager) {
+		entries = append(entries, &ui.BacklogEntry{
+			Text:    t.Text,
+			Choice:  t.Type == window.MessageLogTypeChoice,
+			EventID: t.EventID,
+		})
+	}
+	m.backlog.SetEntries(entries)
+	m.backlog.Show()
+}
+
 func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 	l := lang.Get()
 	m.quitLabel.Text = texts.Text(l, texts.TextIDBackToTitle)
@@ -481,7 +588,7 @@ func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 	m.storeErrorDialog.Update()
 
 	if m.gameHeader != nil {
-		m.gameHeader.Update(m.quitDialog.Visible() || m.credits.Visible())
+		m.gameHeader.Update(m.quitDialog.Visible() || m.credits.Visible() || m.backlog.Visible())
 	}
 
 	m.itemPreviewPopup.Update(l)
@@ -502,6 +609,8 @@ func (m *MapScene) updateUI(sceneManager *scene.Manager) {
 		m.titleView.Update(sceneManager)
 	}
 
+	m.backlog.Update()
+
 	m.credits.Update()
 	m.credits.SetCloseButtonVisible(m.gameState.ShouldShowCreditsCloseButton())
 }
@@ -602,11 +711,20 @@ func (m *MapScene) Update(sceneManager *scene.Manager) error {
 		m.runEventIfNeeded(sceneManager)
 	}
 
-	m.updateOffsetY(sceneManager)
+	m.updateOffset(sceneManager)
 	return nil
 }
 
+// saveReadTexts saves the texts marked as read after the last save before going back to the title.
+// The progress is not always saved there, and the texts would be lost if the app were killed at the title.
+func saveReadTexts(sceneManager *scene.Manager) {
+	if sceneManager.HasUnsavedReadTexts() {
+		sceneManager.RequestSaveReadTexts(0)
+	}
+}
+
 func (m *MapScene) goToTitle(sceneManager *scene.Manager) {
+	saveReadTexts(sceneManager)
 	audio.Stop()
 	g, err := savedGame(sceneManager)
 	if err != nil {
@@ -627,6 +745,12 @@ func (m *MapScene) handleBackButton(sceneManager *scene.Manager) {
 		return
 	}
 
+	if m.backlog.Visible() {
+		audio.PlaySE("system/cancel", 1.0)
+		m.backlog.Hide()
+		return
+	}
+
 	if m.quitDialog.Visible() {
 		audio.PlaySE("system/cancel", 1.0)
 		m.quitDialog.Hide()
@@ -651,40 +775,155 @@ func (m *MapScene) handleBackButton(sceneManager *scene.Manager) {
 	m.quitDialog.Show()
 }
 
-func (m *MapScene) drawTileLayer(layer int, priority data.Priority) {
+// isOverTile reports whether the tile is drawn over the characters behind it.
+func (m *MapScene) isOverTile(tile int) bool {
+	imageID := tileset.ExtractImageID(tile)
+	imageName := m.gameState.Map().FindImageName(imageID)
+	index := 0
+	if !tileset.IsAutoTile(imageName) {
+		x, y := tileset.DecodeTile(tile)
+		index = tileset.TileIndex(x, y)
+	}
+	return tileset.PassageType(imageName, index) == data.PassageTypeOver
+}
+
+func (m *MapScene) drawTileLayer(layer int) {
 	op := &ebiten.DrawImageOptions{}
 	room := m.gameState.Map().CurrentRoom()
+	m.updateOverTiles()
 
-	for j := 0; j < consts.TileYNum; j++ {
-		for i := 0; i < consts.TileXNum; i++ {
-			tileIndex := tileset.TileIndex(i, j)
-			tile := room.Tiles[layer][tileIndex]
+	w, h := room.Size()
+	for j := 0; j < h; j++ {
+		for i := 0; i < w; i++ {
+			tile := m.gameState.Map().Tile(layer, i, j)
 			if tile == 0 {
 				continue
 			}
-			imageID := tileset.ExtractImageID(tile)
-			imageName := m.gameState.Map().FindImageName(imageID)
-			index := 0
-			if !tileset.IsAutoTile(imageName) {
-				x, y := tileset.DecodeTile(tile)
-				index = tileset.TileIndex(x, y)
+			// Over tiles and the tiles above them are drawn with the characters.
+			// See drawCharactersAndOverTiles.
+			if l := m.overTiles.layers[room.TileIndex(i, j)]; l != 0 && l <= layer {
+				continue
 			}
-			passageType := tileset.PassageType(imageName, index)
-			if layer == 2 || layer == 3 {
-				if passageType == data.PassageTypeOver && priority != data.PriorityTop {
+			m.drawTileAt(tile, op, i, j)
+		}
+	}
+}
+
+func (m *MapScene) drawTileAt(tile int, op *ebiten.DrawImageOptions, i int, j int) {
+	imageName := m.gameState.Map().FindImageName(tileset.ExtractImageID(tile))
+	if tileset.IsAutoTile(imageName) {
+		m.drawAutoTile(tile, op, i, j)
+		return
+	}
+	m.drawTile(tile, op, i, j)
+}
+
+// overTile is an over tile to be drawn with the characters.
+type overTile struct {
+	tile int
+	x    int
+	y    int
+
+	// bottom is the bottom Y position of the vertical run of over tiles including this tile.
+	// A character whose foot is above the bottom is covered by the tile.
+	bottom int
+}
+
+// overTiles is the tiles in the upper layers to be drawn with the characters.
+type overTiles struct {
+	gameMap *gamestate.Map
+	version int
+
+	// tiles is the tiles in the order to draw.
+	tiles []overTile
+
+	// layers is the lowest layer of an over tile in each cell, indexed by the tile index.
+	// The tiles in the layer and the layers above it are in tiles. 0 means no over tiles.
+	layers []int
+}
+
+// updateOverTiles updates the over tiles when the room or its tiles change.
+func (m *MapScene) updateOverTiles() {
+	gameMap := m.gameState.Map()
+	if m.overTiles.gameMap == gameMap && m.overTiles.version == gameMap.TilesVersion() && m.overTiles.layers != nil {
+		return
+	}
+
+	room := gameMap.CurrentRoom()
+	w, h := room.Size()
+	ts := []overTile{}
+	layers := make([]int, w*h)
+	for i := 0; i < w; i++ {
+		bottom := 0
+		// Scan from the bottom so that the bottom of the run is known.
+		for j := h - 1; j >= 0; j-- {
+			found := false
+			for layer := 2; layer < 4; layer++ {
+				tile := gameMap.Tile(layer, i, j)
+				if tile == 0 {
 					continue
 				}
-				if passageType != data.PassageTypeOver && priority == data.PriorityTop {
-					continue
+				// Once an over tile is found, the tiles above it in the same cell are drawn after it
+				// so that the order of the layers is kept.
+				if !found {
+					if !m.isOverTile(tile) {
+						continue
+					}
+					layers[room.TileIndex(i, j)] = layer
+					if bottom == 0 {
+						bottom = (j + 1) * consts.TileSize
+					}
 				}
+				found = true
+				ts = append(ts, overTile{
+					tile:   tile,
+					x:      i,
+					y:      j,
+					bottom: bottom,
+				})
 			}
-			if tileset.IsAutoTile(imageName) {
-				m.drawAutoTile(tile, op, i, j)
-			} else {
-				m.drawTile(tile, op, i, j)
+			if !found {
+				bottom = 0
 			}
 		}
 	}
+	// The sort is stable so that the tiles in the same cell are in the order of the layers.
+	sort.SliceStable(ts, func(i, j int) bool {
+		if ts[i].bottom != ts[j].bottom {
+			return ts[i].bottom < ts[j].bottom
+		}
+		if ts[i].y != ts[j].y {
+			return ts[i].y < ts[j].y
+		}
+		return ts[i].x < ts[j].x
+	})
+
+	m.overTiles = overTiles{
+		gameMap: gameMap,
+		version: gameMap.TilesVersion(),
+		tiles:   ts,
+		layers:  layers,
+	}
+}
+
+// drawCharactersAndOverTiles draws the characters at the middle priority and the over tiles sorted by Y,
+// so that a character in front of an over tile is drawn over it.
+func (m *MapScene) drawCharactersAndOverTiles() {
+	op := &ebiten.DrawImageOptions{}
+	m.updateOverTiles()
+	tiles := m.overTiles.tiles
+	ti := 0
+	for _, c := range m.gameState.Map().SortedCharacters(data.PriorityMiddle) {
+		for ; ti < len(tiles) && tiles[ti].bottom < c.DrawOrderY(); ti++ {
+			t := tiles[ti]
+			m.drawTileAt(t.tile, op, t.x, t.y)
+		}
+		c.Draw(m.screenImage, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale)
+	}
+	for ; ti < len(tiles); ti++ {
+		t := tiles[ti]
+		m.drawTileAt(t.tile, op, t.x, t.y)
+	}
 }
 
 func (m *MapScene) drawTile(tile int, op *ebiten.DrawImageOptions, i int, j int) {
@@ -694,9 +933,11 @@ func (m *MapScene) drawTile(tile int, op *ebiten.DrawImageOptions, i int, j int)
 		return
 	}
 	x, y := tileset.DecodeTile(tile)
+	imageName := m.gameState.Map().FindImageName(imageID)
+	x += tileset.AnimationFrame(imageName, tileset.TileIndex(x, y), m.animation.Count())
 	sx := x * consts.TileSize
 	sy := y * consts.TileSize
-	dx := i * consts.TileSize
+	dx := i*consts.TileSize + m.offsetX/consts.TileScale
 	dy := j*consts.TileSize + m.offsetY/consts.TileScale
 	// op is created outside of this function and other parameters than GeoM is not modified so far.
 	op.GeoM.Reset()
@@ -710,12 +951,15 @@ func (m *MapScene) drawAutoTile(tile int, op *ebiten.DrawImageOptions, i int, j
 	if tileSetImg == nil {
 		return
 	}
+	// The frames of an animated auto tile are laid out horizontally, each of which is 2 tiles wide.
+	imageName := m.gameState.Map().FindImageName(imageID)
+	frameX := tileset.AnimationFrame(imageName, 0, m.animation.Count()) * 2 * consts.TileSize
 	autoTileSlice := tileset.DecodeAutoTile(tile)
 	for index, value := range autoTileSlice {
 		x, y := tileset.GetAutoTilePos(index, value)
-		sx := x * consts.MiniTileSize
+		sx := x*consts.MiniTileSize + frameX
 		sy := y * consts.MiniTileSize
-		dx := i*consts.TileSize + index%2*consts.MiniTileSize
+		dx := i*consts.TileSize + index%2*consts.MiniTileSize + m.offsetX/consts.TileScale
 		dy := j*consts.TileSize + index/2*consts.MiniTileSize + m.offsetY/consts.TileScale
 		// op is created outside of this function and other parameters is not modified so far.
 		op.GeoM.Reset()
@@ -725,14 +969,93 @@ func (m *MapScene) drawAutoTile(tile int, op *ebiten.DrawImageOptions, i int, j
 
 }
 
+// drawMapLayers draws the room's image layers behind or in front of the tiles.
+// Like the main background, a layer's bottom is aligned with the map's bottom.
+func (m *MapScene) drawMapLayers(foreground bool, mapHeight int) {
+	dir := "backgrounds/"
+	if foreground {
+		dir = "foregrounds/"
+	}
+	sw, sh := m.screenImage.Size()
+	for _, l := range m.gameState.Map().Layers(m.gameState) {
+		if l.Foreground != foreground || l.Image == "" || l.Opacity <= 0 {
+			continue
+		}
+		img := assets.GetImage(dir + l.Image + ".png")
+		w, h := img.Size()
+
+		x := m.offsetX/consts.TileScale*l.ParallaxX/100 + m.animation.Count()*l.ScrollX/60
+		y := m.offsetY/consts.TileScale*l.ParallaxY/100 + m.animation.Count()*l.ScrollY/60 - (h - mapHeight)
+		if l.WrapX {
+			x %= w
+			if x > 0 {
+				x -= w
+			}
+		}
+		if l.WrapY {
+			y %= h
+			if y > 0 {
+				y -= h
+			}
+		}
+
+		op := &ebiten.DrawImageOptions{}
+		op.ColorM.Scale(1, 1, 1, float64(l.Opacity)/255)
+		if l.BlendType == data.ShowPictureBlendTypeAdd {
+			op.CompositeMode = ebiten.CompositeModeLighter
+		}
+		for dy := y; dy < sh; dy += h {
+			for dx := x; dx < sw; dx += w {
+				op.GeoM.Reset()
+				op.GeoM.Translate(float64(dx), float64(dy))
+				m.screenImage.DrawImage(img, op)
+				if !l.WrapX {
+					break
+				}
+			}
+			if !l.WrapY {
+				break
+			}
+		}
+	}
+}
+
 func (m *MapScene) drawTiles(priority data.Priority) {
-	if priority == data.PriorityBottom {
-		m.drawTileLayer(0, priority)
-		m.drawTileLayer(1, priority)
-	} else {
-		m.drawTileLayer(2, priority)
-		m.drawTileLayer(3, priority)
+	switch priority {
+	case data.PriorityBottom:
+		m.drawTileLayer(0)
+		m.drawTileLayer(1)
+	case data.PriorityMiddle:
+		m.drawTileLayer(2)
+		m.drawTileLayer(3)
+	}
+}
+
+// zoomCenter returns the center of the camera zoom in the screen.
+func (m *MapScene) zoomCenter() (float64, float64) {
+	w, h := m.screenImage.Size()
+	return float64(w*consts.TileScale) / 2, float64(h*consts.TileScale) / 2
+}
+
+func (m *MapScene) applyCameraZoom(geo *ebiten.GeoM) {
+	z := m.gameState.CameraZoom()
+	if z == 1 {
+		return
 	}
+	cx, cy := m.zoomCenter()
+	geo.Translate(-cx, -cy)
+	geo.Scale(z, z)
+	geo.Translate(cx, cy)
+}
+
+// unzoom converts the position in the screen to the position before the camera zoom.
+func (m *MapScene) unzoom(x, y int) (int, int) {
+	z := m.gameState.CameraZoom()
+	if z == 1 || z == 0 {
+		return x, y
+	}
+	cx, cy := m.zoomCenter()
+	return int((float64(x)-cx)/z + cx), int((float64(y)-cy)/z + cy)
 }
 
 func (m *MapScene) Draw(screen *ebiten.Image) {
@@ -741,12 +1064,14 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 		return
 	}
 
-	const mapWidth = consts.MapWidth
-
 	if !m.initialized {
 		return
 	}
 
+	roomW, roomH := m.gameState.Map().CurrentRoom().Size()
+	mapWidth := roomW * consts.TileSize
+	mapHeight := roomH * consts.TileSize
+
 	if m.credits.Visible() {
 		m.credits.Draw(screen)
 		return
@@ -759,9 +1084,10 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 	if background := m.gameState.Map().Background(m.gameState); background != "" {
 		img := assets.GetImage("backgrounds/" + background + ".png")
 		_, h := img.Size()
-		diff := h - consts.MapHeight
-		m.animation.Draw(m.screenImage, img, mapWidth, 0, m.offsetY/consts.TileScale-diff)
+		diff := h - mapHeight
+		m.animation.Draw(m.screenImage, img, mapWidth, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale-diff)
 	}
+	m.drawMapLayers(false, mapHeight)
 
 	m.gameState.DrawPictures(m.screenImage, 0, m.offsetY/consts.TileScale, data.PicturePriorityBottom)
 	for k := 0; k < 3; k++ {
@@ -778,19 +1104,26 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 		}
 
 		m.drawTiles(p)
+		if p == data.PriorityMiddle {
+			m.drawCharactersAndOverTiles()
+			continue
+		}
 		// Characters can be rendered in the upper black area.
 		// That's why offset needs to be specified here.
-		m.gameState.Map().DrawCharacters(m.screenImage, p, 0, m.offsetY/consts.TileScale)
+		m.gameState.Map().DrawCharacters(m.screenImage, p, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale)
 	}
+	m.gameState.DrawLighting(m.screenImage, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale)
+	m.gameState.Map().DrawEmotions(m.screenImage, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale)
 
 	m.gameState.DrawPictures(m.screenImage, 0, m.offsetY/consts.TileScale, data.PicturePriorityTop)
 
 	if foreground := m.gameState.Map().Foreground(m.gameState); foreground != "" {
 		img := assets.GetImage("foregrounds/" + foreground + ".png")
 		_, h := img.Size()
-		diff := h - consts.MapHeight
-		m.animation.Draw(m.screenImage, img, mapWidth, 0, m.offsetY/consts.TileScale-diff)
+		diff := h - mapHeight
+		m.animation.Draw(m.screenImage, img, mapWidth, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale-diff)
 	}
+	m.drawMapLayers(true, mapHeight)
 
 	m.gameState.DrawWeather(m.screenImage)
 	m.gameState.DrawScreen(m.screenImage)
@@ -809,7 +1142,8 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 	op := &ebiten.DrawImageOptions{}
 	m.gameState.ApplyShake(&op.GeoM)
 	op.GeoM.Scale(consts.TileScale, consts.TileScale)
-	// If the screen is shaking, there is a region in the screen that is not rendered. Clear first.
+	m.applyCameraZoom(&op.GeoM)
+	// If the screen is shaking or zoomed out, there is a region in the screen that is not rendered. Clear first.
 	if op.GeoM.Element(0, 2) != 0 || op.GeoM.Element(1, 2) != 0 {
 		screen.Clear()
 	}
@@ -820,7 +1154,8 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 		op := &ebiten.DrawImageOptions{}
 		op.GeoM.Translate(float64(x*consts.TileSize), float64(y*consts.TileSize))
 		op.GeoM.Scale(consts.TileScale, consts.TileScale)
-		op.GeoM.Translate(0, float64(m.offsetY))
+		op.GeoM.Translate(float64(m.offsetX), float64(m.offsetY))
+		m.applyCameraZoom(&op.GeoM)
 
 		numFrames := m.markerAnimationFrame / markerAnimationInterval
 		markerImage := assets.GetImage("system/game/marker.png")
@@ -839,10 +1174,11 @@ func (m *MapScene) Draw(screen *ebiten.Image) {
 	m.minigamePopup.Draw(screen)
 	m.inventory.Draw(screen)
 
-	m.gameState.DrawWindows(screen, 0, m.offsetY/consts.TileScale, m.windowOffsetY/consts.TileScale)
+	m.gameState.DrawWindows(screen, m.offsetX/consts.TileScale, m.offsetY/consts.TileScale, m.windowOffsetY/consts.TileScale)
 	if m.gameHeader != nil {
 		m.gameHeader.Draw(screen)
 	}
+	m.backlog.Draw(screen)
 
 	m.quitDialog.Draw(screen)
 	m.storeErrorDialog.Draw(screen)
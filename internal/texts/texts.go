@@ -52,6 +52,9 @@ const (
 	TextIDMinigameWatchAds
 	TextIDMinigameProgress
 	TextIDVibration
+	TextIDBacklog
+	TextIDAutoAdvance
+	TextIDSkipRead
 )
 
 func Text(lang language.Tag, id TextID) string {
@@ -89,6 +92,9 @@ from the game for %s?`,
 		TextIDBGMVolume:        "BGM",
 		TextIDSEVolume:         "SE",
 		TextIDVibration:        "Vibration",
+		TextIDBacklog:          "Log",
+		TextIDAutoAdvance:      "Auto Advance",
+		TextIDSkipRead:         "Skip Read",
 		TextIDNewGameWarning: `You have on-going game data.
 Do you want to reset your
 progress and start a new game?`,
@@ -129,6 +135,9 @@ enfernen?`,
 		TextIDBGMVolume:        "BGM",
 		TextIDSEVolume:         "SE",
 		TextIDVibration:        "Vibration",
+		TextIDBacklog:          "Log",
+		TextIDAutoAdvance:      "Auto-Weiter",
+		TextIDSkipRead:         "Gelesenes",
 		TextIDNewGameWarning: `Willst du wirklich deinen
 Spielfortschritt löschen und 
 nochmal von Vorne anfangen?`,
@@ -169,6 +178,9 @@ para quitar los anuncios del juego?`,
 		TextIDBGMVolume:        "BGM",
 		TextIDSEVolume:         "SE",
 		TextIDVibration:        "Vibration",
+		TextIDBacklog:          "Log",
+		TextIDAutoAdvance:      "Avance auto",
+		TextIDSkipRead:         "Saltar leído",
 		TextIDNewGameWarning: `Tienes datos del juego en curso.
 ¿Quieres eliminar el progreso 
 e iniciar un nuevo juego?`,
@@ -207,6 +219,9 @@ remover anúncios do jogo?`,
 		TextIDBGMVolume:        "BGM",
 		TextIDSEVolume:         "SE",
 		TextIDVibration:        "Vibration",
+		TextIDBacklog:          "Log",
+		TextIDAutoAdvance:      "Avanço auto",
+		TextIDSkipRead:         "Pular lido",
 		TextIDNewGameWarning: `Você tem dados do jogo em 
 andamento. 
 Você deseja excluir o progresso 
@@ -247,6 +262,9 @@ o login e se conectar à internet`,
 		TextIDBGMVolume:        "BGM音量",
 		TextIDSEVolume:         "SE音量",
 		TextIDVibration:        "振動",
+		TextIDBacklog:          "履歴",
+		TextIDAutoAdvance:      "オート送り",
+		TextIDSkipRead:         "既読スキップ",
 		TextIDNewGameWarning: `進行中のゲームデータがあります。
 進行中のゲームデータを消して、
 新しいゲームを開始しますか?`,
@@ -285,6 +303,9 @@ o login e se conectar à internet`,
 		TextIDBGMVolume:        "背景音乐音量",
 		TextIDSEVolume:         "音效音量",
 		TextIDVibration:        "振动",
+		TextIDBacklog:          "记录",
+		TextIDAutoAdvance:      "自动播放",
+		TextIDSkipRead:         "跳过已读",
 		TextIDNewGameWarning: `系统已经存在一个中断存档。
 开始新游戏会导致中断存档被清除。
 你确定要重新开始新游戏吗?`,
@@ -322,6 +343,9 @@ o login e se conectar à internet`,
 		TextIDBGMVolume:        "背景音樂音量",
 		TextIDSEVolume:         "音效音量",
 		TextIDVibration:        "振動",
+		TextIDBacklog:          "記錄",
+		TextIDAutoAdvance:      "自動播放",
+		TextIDSkipRead:         "跳過已讀",
 		TextIDNewGameWarning: `系統已經存在一個中斷存檔。
 開始新遊戲會導致中斷存檔被清除。
 你確定要重新開始新遊戲嗎？`,
@@ -359,6 +383,9 @@ o login e se conectar à internet`,
 		TextIDBGMVolume:        "배경 음악 볼륨",
 		TextIDSEVolume:         "사운드 볼륨",
 		TextIDVibration:        "진동",
+		TextIDBacklog:          "기록",
+		TextIDAutoAdvance:      "자동 진행",
+		TextIDSkipRead:         "읽은 글 건너뛰기",
 		TextIDNewGameWarning: `진행중인 게임 데이터가 있습니다.
 진행중인 게임 데이터를 지우고,
 새로 게임을 시작하시겠습니까?`,
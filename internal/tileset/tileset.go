@@ -62,6 +62,9 @@ var miniTilePosMap = map[int]map[int][]int{
 	},
 }
 
+// TileIndex returns the index of the tile at (x, y) in a tileset image.
+//
+// To get the index of a tile in a room, use data.Room's TileIndex instead.
 func TileIndex(x, y int) int {
 	return y*consts.TileXNum + x
 }
@@ -119,6 +122,92 @@ func PassageType(imageName string, index int) data.PassageType {
 	return p[index]
 }
 
+// MoveCost gets the cost to enter the tile from the metadata attached to image.
+// Returns 1 when metadata doesn't exist or no cost is set at the required position.
+func MoveCost(imageName string, index int) int {
+	metadata := assets.GetMetadata(imageName)
+	if metadata == nil {
+		return 1
+	}
+	c := metadata.MoveCosts
+	if index >= len(c) || c[index] <= 0 {
+		return 1
+	}
+	return c[index]
+}
+
+// EnterBlocks gets the sides from which the tile cannot be entered from the metadata attached to image.
+// Returns no sides when metadata doesn't exist or nothing is set at the required position.
+func EnterBlocks(imageName string, index int) data.DirMask {
+	metadata := assets.GetMetadata(imageName)
+	if metadata == nil {
+		return 0
+	}
+	b := metadata.EnterBlocks
+	if index >= len(b) {
+		return 0
+	}
+	return b[index]
+}
+
+// Flags gets the tile flags from the metadata attached to image.
+// Returns no flags when metadata doesn't exist or nothing is set at the required position.
+func Flags(imageName string, index int) data.TileFlag {
+	metadata := assets.GetMetadata(imageName)
+	if metadata == nil {
+		return 0
+	}
+	f := metadata.TileFlags
+	if index >= len(f) {
+		return 0
+	}
+	return f[index]
+}
+
+// TerrainTag gets the terrain tag from the metadata attached to image.
+// Returns 0 when metadata doesn't exist or nothing is set at the required position.
+func TerrainTag(imageName string, index int) int {
+	metadata := assets.GetMetadata(imageName)
+	if metadata == nil {
+		return 0
+	}
+	t := metadata.TerrainTags
+	if index >= len(t) {
+		return 0
+	}
+	return t[index]
+}
+
+// DefaultAnimationInterval is the default frame interval in ticks of animated tiles.
+const DefaultAnimationInterval = 15
+
+// Animation gets the number of the animation frames and the frame interval from the metadata attached to image.
+// Returns 1 frame when metadata doesn't exist or nothing is set at the required position.
+func Animation(imageName string, index int) (int, int) {
+	metadata := assets.GetMetadata(imageName)
+	if metadata == nil {
+		return 1, DefaultAnimationInterval
+	}
+	frames := 1
+	if index < len(metadata.AnimationFrames) && metadata.AnimationFrames[index] > 1 {
+		frames = metadata.AnimationFrames[index]
+	}
+	interval := DefaultAnimationInterval
+	if index < len(metadata.AnimationIntervals) && metadata.AnimationIntervals[index] > 0 {
+		interval = metadata.AnimationIntervals[index]
+	}
+	return frames, interval
+}
+
+// AnimationFrame returns the current frame of the tile animation at the global tick count.
+func AnimationFrame(imageName string, index int, count int) int {
+	frames, interval := Animation(imageName, index)
+	if frames <= 1 {
+		return 0
+	}
+	return (count / interval) % frames
+}
+
 func IsAutoTile(imageName string) bool {
 	metadata := assets.GetMetadata(imageName)
 	if metadata == nil {
@@ -0,0 +1,71 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tileset_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/tileset"
+)
+
+func TestAnimationFrame(t *testing.T) {
+	if err := assets.Set(nil, map[string]*data.AssetMetadata{
+		"images/static_metadata.json": {},
+		"images/animated_metadata.json": {
+			AnimationFrames:    []int{0, 1, 3, 4},
+			AnimationIntervals: []int{0, 0, 0, 10},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		ImageName string
+		Index     int
+		Count     int
+		Out       int
+	}{
+		{"static", 0, 0, 0},
+		{"static", 0, 100, 0},
+
+		// 0 and 1 frame mean a static tile.
+		{"animated", 0, 100, 0},
+		{"animated", 1, 100, 0},
+
+		// The default interval is used.
+		{"animated", 2, 0, 0},
+		{"animated", 2, DefaultAnimationInterval - 1, 0},
+		{"animated", 2, DefaultAnimationInterval, 1},
+		{"animated", 2, DefaultAnimationInterval * 2, 2},
+		{"animated", 2, DefaultAnimationInterval * 3, 0},
+
+		{"animated", 3, 9, 0},
+		{"animated", 3, 10, 1},
+		{"animated", 3, 35, 3},
+		{"animated", 3, 40, 0},
+
+		// Out of the range of the metadata.
+		{"animated", 4, 100, 0},
+	}
+	for _, tc := range cases {
+		got := AnimationFrame(tc.ImageName, tc.Index, tc.Count)
+		want := tc.Out
+		if got != want {
+			t.Errorf("AnimationFrame(%q, %d, %d): got: %d, want: %d", tc.ImageName, tc.Index, tc.Count, got, want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/character"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/consts"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/input"
+)
+
+const (
+	backlogTop          = 24
+	backlogPaddingX     = 8
+	backlogEntryMargin  = 6
+	backlogWheelScrollY = 16
+	backlogSpeakerX     = 3
+	backlogSpeakerWidth = 2
+)
+
+var (
+	backlogChoiceColor = color.RGBA{0xff, 0xe0, 0x80, 0xff}
+	backlogPlayerColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+	// backlogSpeakerColors is the colors of the speaker markers of the events. The color is chosen by the event ID.
+	backlogSpeakerColors = []color.RGBA{
+		{0x80, 0xc0, 0xff, 0xff},
+		{0xff, 0x90, 0x90, 0xff},
+		{0x90, 0xe0, 0x90, 0xff},
+		{0xff, 0xc0, 0x60, 0xff},
+		{0xd0, 0xa0, 0xff, 0xff},
+		{0x70, 0xe0, 0xe0, 0xff},
+	}
+)
+
+// BacklogEntry is a text shown in the backlog.
+type BacklogEntry struct {
+	Text   string
+	Choice bool
+
+	// EventID is the speaker. A marker in the color of the speaker is drawn at the left of the text.
+	// 0 means no speaker.
+	EventID int
+}
+
+func (e *BacklogEntry) speakerColor() (color.RGBA, bool) {
+	switch {
+	case e.EventID == 0:
+		return color.RGBA{}, false
+	case e.EventID == character.PlayerEventID:
+		return backlogPlayerColor, true
+	default:
+		n := len(backlogSpeakerColors)
+		return backlogSpeakerColors[((e.EventID%n)+n)%n], true
+	}
+}
+
+// Backlog is a scrollable panel to show the texts that were shown.
+type Backlog struct {
+	closeButton  *Button
+	blackImage   *ebiten.Image
+	screenHeight int
+	visible      bool
+	entries      []*BacklogEntry
+
+	// scrollY and contentHeight are in pixels.
+	scrollY       int
+	contentHeight int
+
+	dragging   bool
+	lastInputY int
+}
+
+func NewBacklog(screenHeight int) *Backlog {
+	closeButton := NewImageButton(
+		140,
+		4,
+		assets.GetImage("system/common/cancel_off.png"),
+		assets.GetImage("system/common/cancel_on.png"),
+		"system/cancel",
+	)
+
+	blackImage, _ := ebiten.NewImage(16, 16, ebiten.FilterNearest)
+	blackImage.Fill(color.Black)
+
+	b := &Backlog{
+		closeButton:  closeButton,
+		blackImage:   blackImage,
+		screenHeight: screenHeight,
+	}
+	closeButton.SetOnPressed(func(_ *Button) {
+		b.Hide()
+	})
+	return b
+}
+
+func (b *Backlog) SetEntries(entries []*BacklogEntry) {
+	b.entries = entries
+	b.contentHeight = 0
+	for _, e := range entries {
+		b.contentHeight += b.entryHeight(e) + backlogEntryMargin*consts.TileScale
+	}
+	b.clampScrollY()
+}
+
+func (b *Backlog) entryHeight(entry *BacklogEntry) int {
+	_, h := font.MeasureSize(entry.Text)
+	return h * consts.TextScale
+}
+
+func (b *Backlog) maxScrollY() int {
+	y := b.contentHeight - (b.screenHeight - backlogTop*consts.TileScale)
+	if y < 0 {
+		return 0
+	}
+	return y
+}
+
+func (b *Backlog) clampScrollY() {
+	if b.scrollY > b.maxScrollY() {
+		b.scrollY = b.maxScrollY()
+	}
+	if b.scrollY < 0 {
+		b.scrollY = 0
+	}
+}
+
+func (b *Backlog) Visible() bool {
+	return b.visible
+}
+
+// Show shows the backlog scrolled to the latest entry.
+func (b *Backlog) Show() {
+	b.visible = true
+	b.scrollY = b.maxScrollY()
+	b.dragging = false
+}
+
+func (b *Backlog) Hide() {
+	b.visible = false
+}
+
+func (b *Backlog) Update() {
+	if !b.visible {
+		return
+	}
+	b.closeButton.UpdateAsChild(b.visible, 0, 0)
+	if !b.visible {
+		return
+	}
+
+	_, y := input.Position()
+	if input.Pressed() {
+		if b.dragging {
+			b.scrollY += b.lastInputY - y
+		}
+		b.dragging = true
+		b.lastInputY = y
+	} else {
+		b.dragging = false
+	}
+	_, wy := input.Wheel()
+	b.scrollY -= int(wy * backlogWheelScrollY * consts.TileScale)
+	b.clampScrollY()
+}
+
+func (b *Backlog) Draw(screen *ebiten.Image) {
+	if !b.visible {
+		return
+	}
+
+	sw, sh := screen.Size()
+	w, h := b.blackImage.Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(sw)/float64(w), float64(sh)/float64(h))
+	op.ColorM.Scale(1, 1, 1, 0.8)
+	screen.DrawImage(b.blackImage, op)
+
+	top := backlogTop * consts.TileScale
+	y := top - b.scrollY
+	for _, e := range b.entries {
+		eh := b.entryHeight(e)
+		if top <= y+eh && y < sh {
+			text := e.Text
+			var clr color.Color = color.White
+			if e.Choice {
+				text = "> " + text
+				clr = backlogChoiceColor
+			}
+			font.DrawText(screen, text, backlogPaddingX*consts.TileScale, y, consts.TextScale, data.TextAlignLeft, clr, len([]rune(text)))
+			if sc, ok := e.speakerColor(); ok {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Scale(backlogSpeakerWidth*consts.TileScale/float64(w), float64(eh)/float64(h))
+				op.GeoM.Translate(backlogSpeakerX*consts.TileScale, float64(y))
+				op.ColorM.Translate(float64(sc.R)/0xff, float64(sc.G)/0xff, float64(sc.B)/0xff, 0)
+				screen.DrawImage(b.blackImage, op)
+			}
+		}
+		y += eh + backlogEntryMargin*consts.TileScale
+	}
+
+	// Cover the entries scrolled over the top.
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(sw)/float64(w), float64(top)/float64(h))
+	screen.DrawImage(b.blackImage, op)
+
+	b.closeButton.DrawAsChild(screen, 0, 0)
+}
@@ -33,7 +33,7 @@ import (
 )
 
 type SceneMaker interface {
-	NewMapScene() scene.Scene
+	NewMapScene(sceneManager *scene.Manager) scene.Scene
 	NewMapSceneWithGame(*gamestate.Game) scene.Scene
 	NewSettingsScene() scene.Scene
 }
@@ -125,7 +125,7 @@ func (t *TitleView) initUI(sceneManager *scene.Manager) {
 			}
 			sceneManager.GoToWithFading(t.sceneMaker.NewMapSceneWithGame(game), 30, 30)
 		} else {
-			sceneManager.GoToWithFading(t.sceneMaker.NewMapScene(), 30, 30)
+			sceneManager.GoToWithFading(t.sceneMaker.NewMapScene(sceneManager), 30, 30)
 		}
 	})
 	t.removeAdsButton.SetOnPressed(func(_ *Button) {
@@ -157,6 +157,12 @@ func (v *Variables) SetSelfSwitchValue(mapID, roomID, eventID int, id int, value
 	values[id] = value
 }
 
+// ClearSelfSwitches resets all the self switches of the event.
+func (v *Variables) ClearSelfSwitches(mapID, roomID, eventID int) {
+	key := fmt.Sprintf("%d_%d_%d", mapID, roomID, eventID)
+	delete(v.selfSwitches, key)
+}
+
 func (v *Variables) VariableValue(id int) int64 {
 	if len(v.variables) < id+1 {
 		zeros := make([]int64, id+1-len(v.variables))
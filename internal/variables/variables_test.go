@@ -29,3 +29,16 @@ func TestSelfSwitches(t *testing.T) {
 		t.Errorf("SelfSwitchValue(1, 2, 3) got: %v, want: %v", got, want)
 	}
 }
+
+func TestClearSelfSwitches(t *testing.T) {
+	v := &Variables{}
+	v.SetSelfSwitchValue(1, 2, 3, 0, true)
+	v.SetSelfSwitchValue(1, 2, 4, 0, true)
+	v.ClearSelfSwitches(1, 2, 3)
+	if got := v.SelfSwitchValue(1, 2, 3, 0); got {
+		t.Errorf("SelfSwitchValue(1, 2, 3) got: %v, want: %v", got, false)
+	}
+	if got := v.SelfSwitchValue(1, 2, 4, 0); !got {
+		t.Errorf("SelfSwitchValue(1, 2, 4) got: %v, want: %v", got, true)
+	}
+}
@@ -60,6 +60,12 @@ type balloon struct {
 	checked        bool
 
 	offscreen *ebiten.Image
+
+	// read is whether the content had been read before the balloon was opened. This is not dumped.
+	read bool
+
+	// proceedingCount is the frames since the balloon finished opening and typing. This is not dumped.
+	proceedingCount int
 }
 
 func (b *balloon) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -214,9 +220,9 @@ func balloonMargin(balloonType data.BalloonType) (int, int) {
 
 func balloonSizeFromContent(content string, balloonType data.BalloonType) (int, int, int, int) {
 	// content is already parsed here.
-	tw, th := font.MeasureSize(content)
-	tw = tw * consts.TextScale / consts.TileScale
-	th = th * consts.TextScale / consts.TileScale
+	tw, th := measureRichText(content, consts.TextScale)
+	tw /= consts.TileScale
+	th /= consts.TileScale
 	mx, my := balloonMargin(balloonType)
 	w := tw + 2*mx
 	h := th + 2*my
@@ -262,11 +268,18 @@ func (b *balloon) setContent(content string) {
 	b.offscreen = nil
 }
 
+// isTyping reports whether the content is being typed.
+func (b *balloon) isTyping() bool {
+	return b.opened && b.typingEffect.isAnimating()
+}
+
 func (b *balloon) trySkipTypingAnim() {
 	b.typingEffect.trySkipAnim()
 }
 
-func (b *balloon) arrowPosition(screenWidth int, character *character.Character) (int, int) {
+// arrowPosition returns the position of the arrow in the map.
+// The position follows the character drawn with the camera zoom.
+func (b *balloon) arrowPosition(screen *ebiten.Image, character *character.Character, offsetX, offsetY int, zoom float64) (int, int) {
 	if !b.hasArrow {
 		panic("windows: hasArrow must be true at arrowPosition")
 	}
@@ -274,20 +287,36 @@ func (b *balloon) arrowPosition(screenWidth int, character *character.Character)
 	w, _ := character.Size()
 	x := cx + w/2
 	y := cy
-	return x, y
+	_, sh := screen.Size()
+	return zoomPosition(sh, x, y, offsetX, offsetY, zoom)
 }
 
-func (b *balloon) position(screenWidth int, character *character.Character) (int, int) {
+// zoomPosition returns the position in the map that is at the same place in the screen as (x, y) in the map
+// zoomed by the camera. The map is zoomed at the center of the screen.
+func zoomPosition(screenHeight int, x, y int, offsetX, offsetY int, zoom float64) (int, int) {
+	if zoom == 1 || zoom == 0 {
+		return x, y
+	}
+	cx := float64(consts.MapWidth) / 2
+	cy := float64(consts.CeilDiv(screenHeight, consts.TileScale)) / 2
+	zx := (float64(x+offsetX)-cx)*zoom + cx - float64(offsetX)
+	zy := (float64(y+offsetY)-cy)*zoom + cy - float64(offsetY)
+	return int(math.Floor(zx)), int(math.Floor(zy))
+}
+
+// position returns the position of the balloon in the map.
+// offsetX is the horizontal offset of the map, and the balloon is kept in the screen.
+func (b *balloon) position(screen *ebiten.Image, character *character.Character, offsetX, offsetY int, zoom float64) (int, int) {
 	if !b.hasArrow {
 		return b.x, b.y
 	}
-	ax, ay := b.arrowPosition(screenWidth, character)
+	ax, ay := b.arrowPosition(screen, character, offsetX, offsetY, zoom)
 	x := ax - b.width/2
-	if consts.MapWidth < x+b.width {
-		x = consts.MapWidth - b.width
+	if consts.MapWidth-offsetX < x+b.width {
+		x = consts.MapWidth - offsetX - b.width
 	}
-	if x < 0 {
-		x = 0
+	if x < -offsetX {
+		x = -offsetX
 	}
 	y := ay - b.height - 4
 	return x, y
@@ -345,6 +374,9 @@ func (b *balloon) update(character *character.Character) {
 			b.playCharacterAnim(character)
 		}
 	}
+	if b.opened {
+		b.typingEffect.updateEffects()
+	}
 	if b.opened && b.typingEffect.isAnimating() {
 		b.typingEffect.update()
 		if !b.typingEffect.isAnimating() && b.characterAnimFinishTrigger() == data.FinishTriggerTypeMessage {
@@ -380,13 +412,12 @@ func (b *balloon) stopCharacterAnim(character *character.Character) {
 	character.RestoreStoredState()
 }
 
-func (b *balloon) geoMForRate(screen *ebiten.Image, character *character.Character) *ebiten.GeoM {
-	sw, _ := screen.Size()
-	x, y := b.position(sw, character)
+func (b *balloon) geoMForRate(screen *ebiten.Image, character *character.Character, offsetX, offsetY int, zoom float64) *ebiten.GeoM {
+	x, y := b.position(screen, character, offsetX, offsetY, zoom)
 	cx := float64(x + b.width/2)
 	cy := float64(y + b.height/2)
 	if b.hasArrow {
-		ax, ay := b.arrowPosition(sw, character)
+		ax, ay := b.arrowPosition(screen, character, offsetX, offsetY, zoom)
 		cx = float64(ax)
 		cy = float64(ay) + balloonArrowHeight
 	}
@@ -455,7 +486,8 @@ func (b *balloon) ensureOffscreen() {
 	}
 }
 
-func (b *balloon) draw(screen *ebiten.Image, character *character.Character, offsetX, offsetY int) {
+// zoom is the camera zoom of the map. The balloon itself is not zoomed, but follows the zoomed character.
+func (b *balloon) draw(screen *ebiten.Image, character *character.Character, offsetX, offsetY int, zoom float64) {
 	sw, _ := screen.Size()
 	dx := math.Floor(float64(sw/consts.TileScale-consts.MapWidth)/2 + float64(offsetX))
 	dy := math.Floor(float64(offsetY))
@@ -464,9 +496,9 @@ func (b *balloon) draw(screen *ebiten.Image, character *character.Character, off
 
 		img := b.assetImage()
 		op := &ebiten.DrawImageOptions{}
-		g := b.geoMForRate(screen, character)
+		g := b.geoMForRate(screen, character, offsetX, offsetY, zoom)
 		g.Translate(dx, dy)
-		tx, ty := b.position(sw, character)
+		tx, ty := b.position(screen, character, offsetX, offsetY, zoom)
 		op.GeoM.Translate(float64(tx), float64(ty))
 		op.GeoM.Concat(*g)
 		op.GeoM.Scale(consts.TileScale, consts.TileScale)
@@ -496,12 +528,12 @@ func (b *balloon) draw(screen *ebiten.Image, character *character.Character, off
 			default:
 				panic(fmt.Sprintf("window: invalid balloon type: %d", t))
 			}
-			ax, ay := b.arrowPosition(sw, character)
+			ax, ay := b.arrowPosition(screen, character, offsetX, offsetY, zoom)
 			tx := ax
 			ty := ay - balloonArrowHeight
 			tx += b.partSize()
 
-			const maxArrowX = consts.MapWidth - balloonArrowWidth - 8
+			maxArrowX := consts.MapWidth - offsetX - balloonArrowWidth - 8
 			if tx > maxArrowX {
 				op.GeoM.Scale(-1, 1)
 				tx = ax - b.partSize()
@@ -513,7 +545,7 @@ func (b *balloon) draw(screen *ebiten.Image, character *character.Character, off
 		}
 	}
 	if b.opened {
-		x, y := b.position(sw, character)
+		x, y := b.position(screen, character, offsetX, offsetY, zoom)
 		mx, my := b.margin()
 		x = (x + mx + b.contentOffsetX) * consts.TileScale
 		y = (y + my + b.contentOffsetY) * consts.TileScale
@@ -0,0 +1,44 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"testing"
+
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+func TestZoomPosition(t *testing.T) {
+	// The screen is 160x240 in the map's pixels, and the center is (80, 120).
+	const screenHeight = 720
+	cases := []struct {
+		X, Y             int
+		OffsetX, OffsetY int
+		Zoom             float64
+		OutX, OutY       int
+	}{
+		{10, 20, 0, 0, 1, 10, 20},
+		{80, 120, 0, 0, 2, 80, 120},
+		{100, 150, 0, 0, 2, 120, 180},
+		{100, 150, -10, 5, 2, 110, 185},
+		{0, 0, 0, 0, 0.5, 40, 60},
+	}
+	for _, c := range cases {
+		x, y := ZoomPosition(screenHeight, c.X, c.Y, c.OffsetX, c.OffsetY, c.Zoom)
+		if x != c.OutX || y != c.OutY {
+			t.Errorf("ZoomPosition(%d, %d, %d, %d, %d, %f): got: (%d, %d), want: (%d, %d)", screenHeight, c.X, c.Y, c.OffsetX, c.OffsetY, c.Zoom, x, y, c.OutX, c.OutY)
+		}
+	}
+}
@@ -52,6 +52,12 @@ type banner struct {
 	messageStyle  *data.MessageStyle
 	typingEffect  *typingEffect
 	eventID       int
+
+	// read is whether the content had been read before the banner was opened. This is not dumped.
+	read bool
+
+	// proceedingCount is the frames since the banner finished opening and typing. This is not dumped.
+	proceedingCount int
 }
 
 func (b *banner) EncodeMsgpack(enc *msgpack.Encoder) error {
@@ -178,6 +184,11 @@ func (b *banner) isAnimating() bool {
 	return b.openingCount > 0 || b.closingCount > 0 || b.typingEffect.isAnimating()
 }
 
+// isTyping reports whether the content is being typed.
+func (b *banner) isTyping() bool {
+	return b.opened && b.typingEffect.isAnimating()
+}
+
 func (b *banner) trySkipTypingAnim() {
 	b.typingEffect.trySkipAnim()
 }
@@ -213,6 +224,9 @@ func (b *banner) update(playerY int, character *character.Character) error {
 			b.playCharacterAnim(character)
 		}
 	}
+	if b.opened {
+		b.typingEffect.updateEffects()
+	}
 	if b.opened && b.typingEffect.isAnimating() {
 		b.typingEffect.update()
 		if !b.typingEffect.isAnimating() && b.characterAnimFinishTrigger() == data.FinishTriggerTypeMessage {
@@ -314,10 +328,10 @@ func (b *banner) draw(screen *ebiten.Image, offsetX, offsetY int) {
 	}
 
 	if b.opened {
-		_, th := font.MeasureSize(b.content)
+		_, th := b.typingEffect.size(textScale)
 		x, y := b.position(screen)
 		x = (x + bannerPaddingX) * consts.TileScale
-		y = (y + (bannerHeight-th*textScale/consts.TileScale)/2) * consts.TileScale
+		y = (y + (bannerHeight-th/consts.TileScale)/2) * consts.TileScale
 		switch b.textAlign {
 		case data.TextAlignLeft:
 		case data.TextAlignCenter:
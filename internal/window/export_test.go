@@ -0,0 +1,95 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+const MaxMessageLogLength = maxMessageLogLength
+
+// RichTextStyle is richTextStyle with exported fields for tests.
+type RichTextStyle struct {
+	Color   color.Color
+	Bold    bool
+	Outline color.Color
+	Effect  string
+	Size    int
+	Delay   int
+}
+
+func exportRichTextStyle(s richTextStyle) RichTextStyle {
+	return RichTextStyle{
+		Color:   s.color,
+		Bold:    s.bold,
+		Outline: s.outline,
+		Effect:  string(s.effect),
+		Size:    s.size,
+		Delay:   s.delay,
+	}
+}
+
+func importRichTextStyle(s RichTextStyle) richTextStyle {
+	return richTextStyle{
+		color:   s.Color,
+		bold:    s.Bold,
+		outline: s.Outline,
+		effect:  richTextEffect(s.Effect),
+		size:    s.Size,
+		delay:   s.Delay,
+	}
+}
+
+// RichTextGlyph is richTextGlyph with exported fields for tests.
+type RichTextGlyph struct {
+	Rune  rune
+	Icon  string
+	Style RichTextStyle
+}
+
+func ParseRichText(content string) []RichTextGlyph {
+	gs := []RichTextGlyph{}
+	for _, g := range parseRichText(content) {
+		gs = append(gs, RichTextGlyph{
+			Rune:  g.r,
+			Icon:  g.icon,
+			Style: exportRichTextStyle(g.style),
+		})
+	}
+	return gs
+}
+
+func DefaultRichTextStyle() RichTextStyle {
+	return exportRichTextStyle(defaultRichTextStyle())
+}
+
+// ApplyRichTextTag returns the style after the tag is applied, and whether the tag is applied.
+func ApplyRichTextTag(style RichTextStyle, name, arg string) (RichTextStyle, bool) {
+	s := importRichTextStyle(style)
+	ok := s.apply(name, arg)
+	return exportRichTextStyle(s), ok
+}
+
+var (
+	ParseRichTextColor = parseRichTextColor
+	MeasureRichText    = measureRichText
+	ZoomPosition       = zoomPosition
+)
+
+func (w *Windows) AppendMessageLogForTesting(logType MessageLogType, eventID int, contentID data.UUID, parser MessageSyntaxParser, game *data.Game) {
+	w.appendMessageLog(logType, eventID, contentID, parser, game)
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+// maxMessageLogLength is the maximum number of the entries in the message log.
+const maxMessageLogLength = 100
+
+type MessageLogType string
+
+const (
+	MessageLogTypeMessage MessageLogType = "message"
+	MessageLogTypeBalloon MessageLogType = "balloon"
+	MessageLogTypeChoice  MessageLogType = "choice"
+)
+
+// MessageLogEntry is a message, a balloon or a chosen choice that was shown.
+// The content is kept as its ID so that the log can be shown in any language.
+type MessageLogEntry struct {
+	Type MessageLogType
+
+	// EventID is the speaker. 0 means no speaker.
+	EventID   int
+	ContentID data.UUID
+
+	// Variables is the values of the variables that the content referred to when it was shown.
+	Variables map[int]int64
+}
+
+func (m *MessageLogEntry) EncodeMsgpack(enc *msgpack.Encoder) error {
+	e := easymsgpack.NewEncoder(enc)
+	e.BeginMap()
+
+	e.EncodeString("type")
+	e.EncodeString(string(m.Type))
+
+	e.EncodeString("eventId")
+	e.EncodeInt(m.EventID)
+
+	e.EncodeString("contentId")
+	e.EncodeInterface(&m.ContentID)
+
+	e.EncodeString("variables")
+	e.BeginMap()
+	for k, v := range m.Variables {
+		e.EncodeInt(k)
+		e.EncodeInt64(v)
+	}
+	e.EndMap()
+
+	e.EndMap()
+	return e.Flush()
+}
+
+func (m *MessageLogEntry) DecodeMsgpack(dec *msgpack.Decoder) error {
+	d := easymsgpack.NewDecoder(dec)
+	n := d.DecodeMapLen()
+	for i := 0; i < n; i++ {
+		switch k := d.DecodeString(); k {
+		case "type":
+			m.Type = MessageLogType(d.DecodeString())
+		case "eventId":
+			m.EventID = d.DecodeInt()
+		case "contentId":
+			d.DecodeInterface(&m.ContentID)
+		case "variables":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeMapLen()
+				m.Variables = map[int]int64{}
+				for i := 0; i < n; i++ {
+					k := d.DecodeInt()
+					m.Variables[k] = d.DecodeInt64()
+				}
+			}
+		default:
+			if err := d.Error(); err != nil {
+				return err
+			}
+			return fmt.Errorf("window: MessageLogEntry.DecodeMsgpack failed: unknown key: %s", k)
+		}
+	}
+	if err := d.Error(); err != nil {
+		return fmt.Errorf("window: MessageLogEntry.DecodeMsgpack failed: %v", err)
+	}
+	return nil
+}
+
+// MessageLogText is an entry of the message log with the text in the current language.
+type MessageLogText struct {
+	Type    MessageLogType
+	EventID int
+	Text    string
+}
+
+func (w *Windows) appendMessageLog(logType MessageLogType, eventID int, contentID data.UUID, parser MessageSyntaxParser, game *data.Game) {
+	w.messageLog = append(w.messageLog, &MessageLogEntry{
+		Type:      logType,
+		EventID:   eventID,
+		ContentID: contentID,
+		Variables: parser.MessageSyntaxVariables(game.Texts.Get(lang.Get(), contentID)),
+	})
+	if len(w.messageLog) > maxMessageLogLength {
+		w.messageLog = w.messageLog[len(w.messageLog)-maxMessageLogLength:]
+	}
+}
+
+// MessageLog returns the entries of the message log from the oldest.
+func (w *Windows) MessageLog() []*MessageLogEntry {
+	return w.messageLog
+}
+
+// MessageLogTexts returns the entries of the message log with the plain texts in the current language from the oldest.
+// The variables in the texts are shown with the values recorded when the entries were shown.
+func (w *Windows) MessageLogTexts(parser MessageSyntaxParser, game *data.Game) []*MessageLogText {
+	ts := make([]*MessageLogText, 0, len(w.messageLog))
+	for _, l := range w.messageLog {
+		content := game.Texts.Get(lang.Get(), l.ContentID)
+		content = parser.ParseMessageSyntaxWithVariables(content, l.Variables)
+		ts = append(ts, &MessageLogText{
+			Type:    l.Type,
+			EventID: l.EventID,
+			Text:    plainText(content),
+		})
+	}
+	return ts
+}
+
+// plainText returns the content without the controls and the markup tags.
+func plainText(content string) string {
+	rs := []rune{}
+	for _, g := range parseRichText(visibleContent(content)) {
+		if g.icon != "" {
+			continue
+		}
+		rs = append(rs, g.r)
+	}
+	return string(rs)
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+// testParser is a MessageSyntaxParser that shows the value of the variable 1 regardless of the content.
+type testParser struct {
+	value int64
+}
+
+func (p *testParser) ParseMessageSyntax(content string) string {
+	return p.ParseMessageSyntaxWithVariables(content, nil)
+}
+
+func (p *testParser) MessageSyntaxVariables(content string) map[int]int64 {
+	return map[int]int64{1: p.value}
+}
+
+func (p *testParser) ParseMessageSyntaxWithVariables(content string, variables map[int]int64) string {
+	v, ok := variables[1]
+	if !ok {
+		v = p.value
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+func newTestGame() *data.Game {
+	return &data.Game{
+		Texts: &data.Texts{},
+	}
+}
+
+func TestMessageLogLength(t *testing.T) {
+	w := &Windows{}
+	p := &testParser{}
+	g := newTestGame()
+	n := MaxMessageLogLength + 10
+	for i := 0; i < n; i++ {
+		w.AppendMessageLogForTesting(MessageLogTypeMessage, i+1, data.NewUUID(), p, g)
+	}
+
+	log := w.MessageLog()
+	if got, want := len(log), MaxMessageLogLength; got != want {
+		t.Fatalf("len(MessageLog()): got: %d, want: %d", got, want)
+	}
+	// The oldest entries are dropped.
+	if got, want := log[0].EventID, n-MaxMessageLogLength+1; got != want {
+		t.Errorf("MessageLog()[0].EventID: got: %d, want: %d", got, want)
+	}
+	if got, want := log[len(log)-1].EventID, n; got != want {
+		t.Errorf("MessageLog()[%d].EventID: got: %d, want: %d", len(log)-1, got, want)
+	}
+}
+
+func TestMessageLogTextsUseRecordedVariables(t *testing.T) {
+	w := &Windows{}
+	p := &testParser{value: 10}
+	g := newTestGame()
+	w.AppendMessageLogForTesting(MessageLogTypeMessage, 1, data.NewUUID(), p, g)
+	p.value = 20
+
+	ts := w.MessageLogTexts(p, g)
+	if got, want := len(ts), 1; got != want {
+		t.Fatalf("len(MessageLogTexts()): got: %d, want: %d", got, want)
+	}
+	if got, want := ts[0].Text, "10"; got != want {
+		t.Errorf("MessageLogTexts()[0].Text: got: %q, want: %q", got, want)
+	}
+}
+
+func TestMessageLogAfterMarshal(t *testing.T) {
+	w := &Windows{}
+	p := &testParser{value: 10}
+	g := newTestGame()
+	w.AppendMessageLogForTesting(MessageLogTypeBalloon, 1, data.NewUUID(), p, g)
+	p.value = -3
+	w.AppendMessageLogForTesting(MessageLogTypeMessage, -1, data.NewUUID(), p, g)
+	w.AppendMessageLogForTesting(MessageLogTypeChoice, 0, data.NewUUID(), p, g)
+
+	b, err := msgpack.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2 := &Windows{}
+	if err := msgpack.Unmarshal(b, w2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := w2.MessageLog(), w.MessageLog(); !reflect.DeepEqual(got, want) {
+		t.Errorf("MessageLog(): got: %v, want: %v", got, want)
+	}
+}
+
+func TestMessageLogEntryUnknownKey(t *testing.T) {
+	b, err := msgpack.Marshal(map[string]interface{}{
+		"unknown": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := msgpack.Unmarshal(b, &MessageLogEntry{}); err == nil {
+		t.Errorf("msgpack.Unmarshal with an unknown key must return an error")
+	}
+}
@@ -0,0 +1,564 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window
+
+import (
+	"image/color"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten"
+	"golang.org/x/text/language"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/assets"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/lang"
+)
+
+// Rich text markup tags in message contents:
+//
+//	\c[#rrggbb], \c[#rrggbbaa]  text color
+//	\b[1]                       bold
+//	\o[#rrggbb], \o[#rrggbbaa]  outline color
+//	\s[150]                     text size in percent
+//	\w[4]                       typing delay in ticks per character
+//	\e[shake], \e[wave]         text effect
+//	\g[name]                    inline icon in images/icons
+//
+// The argument "-" resets the style to the default, e.g. \c[-].
+// Unknown tags are shown as they are.
+var reRichTextTag = regexp.MustCompile(`^\\([a-zA-Z])\[([^\\\]]+)\]`)
+
+const richTextTagIcon = "g"
+
+// isRichTextIcon reports whether the tag is an icon tag for an existing icon.
+func isRichTextIcon(name, arg string) bool {
+	return name == richTextTagIcon && assets.ImageExists("icons/"+arg)
+}
+
+type richTextEffect string
+
+const (
+	richTextEffectNone  richTextEffect = ""
+	richTextEffectShake richTextEffect = "shake"
+	richTextEffectWave  richTextEffect = "wave"
+)
+
+type richTextStyle struct {
+	color   color.Color
+	bold    bool
+	outline color.Color
+	effect  richTextEffect
+
+	// size is the text size in percent. 0 means 100.
+	size int
+
+	// delay is the typing delay in ticks. -1 means the default delay.
+	delay int
+}
+
+func defaultRichTextStyle() richTextStyle {
+	return richTextStyle{
+		delay: -1,
+	}
+}
+
+// apply applies the tag to the style. apply returns false if the tag is unknown or invalid.
+func (s *richTextStyle) apply(name, arg string) bool {
+	reset := arg == "-"
+	switch name {
+	case "c":
+		if reset {
+			s.color = nil
+			return true
+		}
+		c, ok := parseRichTextColor(arg)
+		if !ok {
+			return false
+		}
+		s.color = c
+	case "b":
+		s.bold = !reset && arg != "0"
+	case "o":
+		if reset {
+			s.outline = nil
+			return true
+		}
+		c, ok := parseRichTextColor(arg)
+		if !ok {
+			return false
+		}
+		s.outline = c
+	case "s":
+		if reset {
+			s.size = 0
+			return true
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return false
+		}
+		s.size = n
+	case "w":
+		if reset {
+			s.delay = -1
+			return true
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return false
+		}
+		s.delay = n
+	case "e":
+		switch e := richTextEffect(arg); e {
+		case richTextEffectShake, richTextEffectWave:
+			s.effect = e
+		default:
+			if !reset {
+				return false
+			}
+			s.effect = richTextEffectNone
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// scale returns the text scale of the style based on textScale.
+func (s *richTextStyle) scale(textScale int) int {
+	if s.size == 0 {
+		return textScale
+	}
+	scale := (textScale*s.size + 50) / 100
+	if scale < 1 {
+		scale = 1
+	}
+	return scale
+}
+
+// offset returns the position offset of the index-th glyph by the effect.
+func (s *richTextStyle) offset(index int, count int, scale int) (int, int) {
+	unit := scale / 2
+	if unit < 1 {
+		unit = 1
+	}
+	switch s.effect {
+	case richTextEffectShake:
+		v := (index*31 + count/4*17) % 9
+		return (v%3 - 1) * unit, (v/3 - 1) * unit
+	case richTextEffectWave:
+		t := float64(count+index*6) * 2 * math.Pi / 60
+		return 0, int(math.Round(math.Sin(t) * float64(2*unit)))
+	}
+	return 0, 0
+}
+
+func parseRichTextColor(str string) (color.Color, bool) {
+	if !strings.HasPrefix(str, "#") {
+		return nil, false
+	}
+	str = str[1:]
+	if len(str) != 6 && len(str) != 8 {
+		return nil, false
+	}
+	v, err := strconv.ParseUint(str, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+	if len(str) == 6 {
+		v = v<<8 | 0xff
+	}
+	// Colors in ebiten are premultiplied.
+	a := uint32(v & 0xff)
+	r := uint32(v>>24) * a / 0xff
+	g := uint32(v>>16&0xff) * a / 0xff
+	b := uint32(v>>8&0xff) * a / 0xff
+	return color.RGBA{uint8(r), uint8(g), uint8(b), uint8(a)}, true
+}
+
+// richTextTag returns the name and the argument of the tag at the head of content,
+// and the tag's length in runes. The length is 0 when content doesn't start with a tag.
+func richTextTag(content []rune) (string, string, int) {
+	if len(content) == 0 || content[0] != '\\' {
+		return "", "", 0
+	}
+	m := reRichTextTag.FindStringSubmatch(string(content))
+	if m == nil {
+		return "", "", 0
+	}
+	return strings.ToLower(m[1]), m[2], len([]rune(m[0]))
+}
+
+// richTextStyleTagLen returns the length in runes of the style tag at the head of content.
+// Icon tags and unknown tags are not style tags.
+func richTextStyleTagLen(content []rune) int {
+	name, arg, n := richTextTag(content)
+	if n == 0 || name == richTextTagIcon {
+		return 0
+	}
+	s := defaultRichTextStyle()
+	if !s.apply(name, arg) {
+		return 0
+	}
+	return n
+}
+
+// richTextGlyph is a character or an icon in rich text.
+type richTextGlyph struct {
+	r     rune
+	icon  string
+	style richTextStyle
+
+	// pos is the index of the rune where the glyph starts in the parsed content.
+	pos int
+}
+
+func (g *richTextGlyph) isLineBreak() bool {
+	return g.icon == "" && g.r == '\n'
+}
+
+// parseRichText parses content with the markup tags into glyphs.
+func parseRichText(content string) []richTextGlyph {
+	content = strings.Replace(content, "\r\n", "\n", -1)
+	rs := []rune(content)
+	glyphs := []richTextGlyph{}
+	style := defaultRichTextStyle()
+	for i := 0; i < len(rs); {
+		if name, arg, n := richTextTag(rs[i:]); n > 0 {
+			if isRichTextIcon(name, arg) {
+				glyphs = append(glyphs, richTextGlyph{
+					icon:  arg,
+					style: style,
+					pos:   i,
+				})
+				i += n
+				continue
+			}
+			if style.apply(name, arg) {
+				i += n
+				continue
+			}
+		}
+		glyphs = append(glyphs, richTextGlyph{
+			r:     rs[i],
+			style: style,
+			pos:   i,
+		})
+		i++
+	}
+	return glyphs
+}
+
+func richTextIconScale(img *ebiten.Image, scale int) float64 {
+	_, h := img.Size()
+	if h > font.RenderingLineHeight {
+		return float64(font.RenderingLineHeight*scale) / float64(h)
+	}
+	return float64(scale)
+}
+
+func richTextIconWidth(icon string, scale int) int {
+	img := assets.GetIconImage(icon + ".png")
+	w, _ := img.Size()
+	return int(math.Ceil(float64(w) * richTextIconScale(img, scale)))
+}
+
+// richTextRun is a part of a line drawn at once: text glyphs in the same style, or an icon.
+// A glyph with a text effect is a run by itself since the effect moves each glyph.
+type richTextRun struct {
+	// start and end are the range of the glyphs.
+	start int
+	end   int
+
+	str string
+
+	// x and y are the upper-left position relative to the text origin.
+	x     int
+	y     int
+	scale int
+}
+
+// richText is content with the markup tags. The content is parsed once, and the layout is cached.
+type richText struct {
+	glyphs []richTextGlyph
+
+	// If plain is true, the content has no effective markup and str is the content.
+	// Plain content is drawn by font.DrawText as a whole so that kerning is kept.
+	plain bool
+	str   string
+
+	// The layout cache
+	laidOut     bool
+	layoutScale int
+	layoutAlign data.TextAlign
+	layoutLang  language.Tag
+	runs        []richTextRun
+	width       int
+	height      int
+}
+
+func newRichText(content string) *richText {
+	t := &richText{
+		glyphs: parseRichText(content),
+		plain:  true,
+	}
+	rs := make([]rune, 0, len(t.glyphs))
+	for _, g := range t.glyphs {
+		if g.icon != "" || g.style != defaultRichTextStyle() {
+			t.plain = false
+			break
+		}
+		rs = append(rs, g.r)
+	}
+	if t.plain {
+		t.str = string(rs)
+	}
+	return t
+}
+
+// layout updates the runs and the size at textScale.
+// Each line's X positions depend on textAlign as font.DrawText does, and the glyphs are aligned to the bottom of the line.
+func (t *richText) layout(textScale int, textAlign data.TextAlign) {
+	l := lang.Get()
+	if t.laidOut && t.layoutScale == textScale && t.layoutAlign == textAlign && t.layoutLang == l {
+		return
+	}
+	t.laidOut = true
+	t.layoutScale = textScale
+	t.layoutAlign = textAlign
+	t.layoutLang = l
+	t.runs = nil
+	t.width = 0
+	t.height = 0
+
+	// The trailing line breaks don't count for the height as font.MeasureSize.
+	lastLine := 0
+	for i := len(t.glyphs) - 1; i >= 0; i-- {
+		if !t.glyphs[i].isLineBreak() {
+			for _, g := range t.glyphs[:i] {
+				if g.isLineBreak() {
+					lastLine++
+				}
+			}
+			break
+		}
+	}
+
+	y := 0
+	for start, line := 0, 0; start <= len(t.glyphs); line++ {
+		end := start
+		for end < len(t.glyphs) && !t.glyphs[end].isLineBreak() {
+			end++
+		}
+
+		lineHeight := font.RenderingLineHeight * textScale
+		for _, g := range t.glyphs[start:end] {
+			if h := font.RenderingLineHeight * g.style.scale(textScale); h > lineHeight {
+				lineHeight = h
+			}
+		}
+
+		// The advance is used for the alignment, while the bounds width of the last run is used for the size
+		// so that the size of text without markup is the same as font.MeasureSize.
+		runs := []richTextRun{}
+		advances := []int{}
+		advance := 0
+		lineWidth := 0
+		for i := start; i < end; {
+			g := t.glyphs[i]
+			scale := g.style.scale(textScale)
+			if g.icon != "" {
+				w := richTextIconWidth(g.icon, scale)
+				runs = append(runs, richTextRun{
+					start: i,
+					end:   i + 1,
+					scale: scale,
+				})
+				advances = append(advances, w)
+				lineWidth = advance + w
+				advance += w
+				i++
+				continue
+			}
+			j := i + 1
+			if g.style.effect == richTextEffectNone {
+				for j < end && t.glyphs[j].icon == "" && t.glyphs[j].style == g.style {
+					j++
+				}
+			}
+			rs := make([]rune, 0, j-i)
+			for _, g := range t.glyphs[i:j] {
+				rs = append(rs, g.r)
+			}
+			str := string(rs)
+			runs = append(runs, richTextRun{
+				start: i,
+				end:   j,
+				str:   str,
+				scale: scale,
+			})
+			a := font.Advance(str, scale)
+			advances = append(advances, a)
+			lineWidth = advance + font.Width(str, scale)
+			advance += a
+			i = j
+		}
+		if lineWidth > t.width {
+			t.width = lineWidth
+		}
+
+		x := 0
+		switch textAlign {
+		case data.TextAlignCenter:
+			x = -advance / 2
+		case data.TextAlignRight:
+			x = -advance
+		}
+		for i := range runs {
+			runs[i].x = x
+			runs[i].y = y + lineHeight - font.RenderingLineHeight*runs[i].scale
+			x += advances[i]
+		}
+		t.runs = append(t.runs, runs...)
+
+		y += lineHeight
+		if line == lastLine {
+			t.height = y
+		}
+		start = end + 1
+	}
+}
+
+// size returns the size of the text in pixels at textScale.
+func (t *richText) size(textScale int) (int, int) {
+	if t.plain {
+		w, h := font.MeasureSize(t.str)
+		return w * textScale, h * textScale
+	}
+	t.layout(textScale, data.TextAlignLeft)
+	return t.width, t.height
+}
+
+// measureRichText returns the size of the content with the markup tags in pixels at textScale.
+func measureRichText(content string, textScale int) (int, int) {
+	return newRichText(content).size(textScale)
+}
+
+// draw draws the first count glyphs at (ox, oy).
+// effectCount is the tick count for the text effects.
+func (t *richText) draw(screen *ebiten.Image, count int, ox, oy int, textScale int, textAlign data.TextAlign, textColor color.Color, edgeColor color.Color, shadowColor color.Color, effectCount int) {
+	if count > len(t.glyphs) {
+		count = len(t.glyphs)
+	}
+
+	if t.plain {
+		s := textScale
+		if shadowColor != nil {
+			// Shadow
+			font.DrawText(screen, t.str, ox+s*2, oy, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox-s*2, oy, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox, oy+s*2, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox, oy-s*2, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox+s, oy+s, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox-s, oy+s, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox+s, oy-s, s, textAlign, shadowColor, count)
+			font.DrawText(screen, t.str, ox-s, oy-s, s, textAlign, shadowColor, count)
+
+			// Edge
+			font.DrawText(screen, t.str, ox+s, oy, s, textAlign, edgeColor, count)
+			font.DrawText(screen, t.str, ox-s, oy, s, textAlign, edgeColor, count)
+			font.DrawText(screen, t.str, ox, oy+s, s, textAlign, edgeColor, count)
+			font.DrawText(screen, t.str, ox, oy-s, s, textAlign, edgeColor, count)
+		}
+		font.DrawText(screen, t.str, ox, oy, s, textAlign, textColor, count)
+		return
+	}
+
+	t.layout(textScale, textAlign)
+
+	// Shadows, edges and outlines are drawn first so that they don't cover the other glyphs.
+	for pass := 0; pass < 3; pass++ {
+		for _, r := range t.runs {
+			if r.start >= count {
+				break
+			}
+			g := &t.glyphs[r.start]
+			dx, dy := g.style.offset(r.start, effectCount, r.scale)
+			x := ox + r.x + dx
+			y := oy + r.y + dy
+			if g.icon != "" {
+				if pass == 2 {
+					img := assets.GetIconImage(g.icon + ".png")
+					s := richTextIconScale(img, r.scale)
+					_, h := img.Size()
+					op := &ebiten.DrawImageOptions{}
+					op.GeoM.Scale(s, s)
+					op.GeoM.Translate(float64(x), float64(y+(font.RenderingLineHeight*r.scale-int(float64(h)*s))/2))
+					screen.DrawImage(img, op)
+				}
+				continue
+			}
+
+			str := r.str
+			if count < r.end {
+				str = string([]rune(str)[:count-r.start])
+			}
+			s := r.scale
+			switch pass {
+			case 0:
+				if shadowColor == nil {
+					continue
+				}
+				font.DrawTextAt(screen, str, x+s*2, y, s, shadowColor)
+				font.DrawTextAt(screen, str, x-s*2, y, s, shadowColor)
+				font.DrawTextAt(screen, str, x, y+s*2, s, shadowColor)
+				font.DrawTextAt(screen, str, x, y-s*2, s, shadowColor)
+				font.DrawTextAt(screen, str, x+s, y+s, s, shadowColor)
+				font.DrawTextAt(screen, str, x-s, y+s, s, shadowColor)
+				font.DrawTextAt(screen, str, x+s, y-s, s, shadowColor)
+				font.DrawTextAt(screen, str, x-s, y-s, s, shadowColor)
+			case 1:
+				// The edge is drawn with the shadow as font.DrawText's path, while an outline is always drawn.
+				var c color.Color
+				if shadowColor != nil {
+					c = edgeColor
+				}
+				if g.style.outline != nil {
+					c = g.style.outline
+				}
+				if c == nil {
+					continue
+				}
+				font.DrawTextAt(screen, str, x+s, y, s, c)
+				font.DrawTextAt(screen, str, x-s, y, s, c)
+				font.DrawTextAt(screen, str, x, y+s, s, c)
+				font.DrawTextAt(screen, str, x, y-s, s, c)
+			case 2:
+				c := textColor
+				if g.style.color != nil {
+					c = g.style.color
+				}
+				font.DrawTextAt(screen, str, x, y, s, c)
+				if g.style.bold {
+					font.DrawTextAt(screen, str, x+s/2+s%2, y, s, c)
+				}
+			}
+		}
+	}
+}
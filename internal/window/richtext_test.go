@@ -0,0 +1,215 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+func TestParseRichTextColor(t *testing.T) {
+	cases := []struct {
+		In    string
+		Out   color.Color
+		OutOK bool
+	}{
+		{"#ff0000", color.RGBA{0xff, 0, 0, 0xff}, true},
+		{"#00ff0080", color.RGBA{0, 0x80, 0, 0x80}, true},
+		{"#FFFFFF", color.RGBA{0xff, 0xff, 0xff, 0xff}, true},
+		{"#00000000", color.RGBA{0, 0, 0, 0}, true},
+		{"ff0000", nil, false},
+		{"#ff00", nil, false},
+		{"#ff00000", nil, false},
+		{"#gg0000", nil, false},
+		{"", nil, false},
+	}
+	for _, tc := range cases {
+		got, ok := ParseRichTextColor(tc.In)
+		if ok != tc.OutOK || !reflect.DeepEqual(got, tc.Out) {
+			t.Errorf("ParseRichTextColor(%q): got: %v, %t, want: %v, %t", tc.In, got, ok, tc.Out, tc.OutOK)
+		}
+	}
+}
+
+func TestRichTextStyleApply(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	styled := RichTextStyle{
+		Color:   red,
+		Bold:    true,
+		Outline: red,
+		Effect:  "shake",
+		Size:    150,
+		Delay:   4,
+	}
+	def := DefaultRichTextStyle()
+	with := func(f func(s *RichTextStyle)) RichTextStyle {
+		s := def
+		f(&s)
+		return s
+	}
+	withStyled := func(f func(s *RichTextStyle)) RichTextStyle {
+		s := styled
+		f(&s)
+		return s
+	}
+
+	cases := []struct {
+		Style RichTextStyle
+		Name  string
+		Arg   string
+		Out   RichTextStyle
+		OutOK bool
+	}{
+		{def, "c", "#ff0000", with(func(s *RichTextStyle) { s.Color = red }), true},
+		{def, "c", "red", def, false},
+		{def, "b", "1", with(func(s *RichTextStyle) { s.Bold = true }), true},
+		{styled, "b", "0", withStyled(func(s *RichTextStyle) { s.Bold = false }), true},
+		{def, "o", "#ff0000", with(func(s *RichTextStyle) { s.Outline = red }), true},
+		{def, "s", "150", with(func(s *RichTextStyle) { s.Size = 150 }), true},
+		{def, "s", "0", def, false},
+		{def, "s", "abc", def, false},
+		{def, "w", "0", with(func(s *RichTextStyle) { s.Delay = 0 }), true},
+		{def, "w", "-2", def, false},
+		{def, "e", "wave", with(func(s *RichTextStyle) { s.Effect = "wave" }), true},
+		{def, "e", "spin", def, false},
+
+		// "-" resets the style to the default.
+		{styled, "c", "-", withStyled(func(s *RichTextStyle) { s.Color = nil }), true},
+		{styled, "b", "-", withStyled(func(s *RichTextStyle) { s.Bold = false }), true},
+		{styled, "o", "-", withStyled(func(s *RichTextStyle) { s.Outline = nil }), true},
+		{styled, "s", "-", withStyled(func(s *RichTextStyle) { s.Size = 0 }), true},
+		{styled, "w", "-", withStyled(func(s *RichTextStyle) { s.Delay = -1 }), true},
+		{styled, "e", "-", withStyled(func(s *RichTextStyle) { s.Effect = "" }), true},
+
+		// Unknown tags are not applied.
+		{def, "x", "1", def, false},
+		{styled, "v", "-", styled, false},
+	}
+	for _, tc := range cases {
+		got, ok := ApplyRichTextTag(tc.Style, tc.Name, tc.Arg)
+		if ok != tc.OutOK || !reflect.DeepEqual(got, tc.Out) {
+			t.Errorf("apply(%q, %q) to %v: got: %v, %t, want: %v, %t", tc.Name, tc.Arg, tc.Style, got, ok, tc.Out, tc.OutOK)
+		}
+	}
+}
+
+func TestParseRichText(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	def := DefaultRichTextStyle()
+	redStyle := def
+	redStyle.Color = red
+	boldStyle := def
+	boldStyle.Bold = true
+
+	glyphs := func(str string, style RichTextStyle) []RichTextGlyph {
+		gs := []RichTextGlyph{}
+		for _, r := range str {
+			gs = append(gs, RichTextGlyph{
+				Rune:  r,
+				Style: style,
+			})
+		}
+		return gs
+	}
+	concat := func(gss ...[]RichTextGlyph) []RichTextGlyph {
+		r := []RichTextGlyph{}
+		for _, gs := range gss {
+			r = append(r, gs...)
+		}
+		return r
+	}
+
+	cases := []struct {
+		In  string
+		Out []RichTextGlyph
+	}{
+		{"", []RichTextGlyph{}},
+		{"abc", glyphs("abc", def)},
+		{"a\r\nb", glyphs("a\nb", def)},
+		{`a\c[#ff0000]bc`, concat(glyphs("a", def), glyphs("bc", redStyle))},
+		{`\c[#ff0000]a\c[-]b`, concat(glyphs("a", redStyle), glyphs("b", def))},
+		{`\B[1]a`, glyphs("a", boldStyle)},
+		{`\c[#ff0000]\c[-]a`, glyphs("a", def)},
+
+		// Unknown or invalid tags are shown as they are.
+		{`\x[1]a`, glyphs(`\x[1]a`, def)},
+		{`\c[red]a`, glyphs(`\c[red]a`, def)},
+		{`\c[#ff0000`, glyphs(`\c[#ff0000`, def)},
+		{`\v[1]`, glyphs(`\v[1]`, def)},
+	}
+	for _, tc := range cases {
+		got := ParseRichText(tc.In)
+		if !reflect.DeepEqual(got, tc.Out) {
+			t.Errorf("ParseRichText(%q): got: %v, want: %v", tc.In, got, tc.Out)
+		}
+	}
+}
+
+func TestMeasureRichText(t *testing.T) {
+	const scale = 2
+
+	// Text without markup is measured as font.MeasureSize.
+	for _, str := range []string{
+		"",
+		"abc",
+		"Hello, World!",
+		"abc\ndefgh",
+		"abc\n",
+		"abc\n\n\n",
+		"\nabc",
+		`\x[1]abc`,
+		`\c[-]abc`,
+	} {
+		w, h := MeasureRichText(str, scale)
+		fw, fh := font.MeasureSize(str)
+		if str == `\c[-]abc` {
+			fw, fh = font.MeasureSize("abc")
+		}
+		if w != fw*scale || h != fh*scale {
+			t.Errorf("MeasureRichText(%q, %d): got: (%d, %d), want: (%d, %d)", str, scale, w, h, fw*scale, fh*scale)
+		}
+	}
+
+	cases := []struct {
+		In    string
+		Plain string
+		OutH  int
+	}{
+		// Styles that don't change the size.
+		{`\c[#ff0000]abc`, "abc", font.RenderingLineHeight * scale},
+		{`a\c[#ff0000]bc\c[-]`, "abc", font.RenderingLineHeight * scale},
+		{`\c[#ff0000]abc` + "\ndefgh\n", "abc\ndefgh", 2 * font.RenderingLineHeight * scale},
+	}
+	for _, tc := range cases {
+		w, h := MeasureRichText(tc.In, scale)
+		fw, _ := font.MeasureSize(tc.Plain)
+		if w != fw*scale || h != tc.OutH {
+			t.Errorf("MeasureRichText(%q, %d): got: (%d, %d), want: (%d, %d)", tc.In, scale, w, h, fw*scale, tc.OutH)
+		}
+	}
+
+	// A bigger text makes the line taller.
+	w, h := MeasureRichText(`a\s[200]b`, 1)
+	if h != font.RenderingLineHeight*2 {
+		t.Errorf("MeasureRichText(%q, 1): got height: %d, want: %d", `a\s[200]b`, h, font.RenderingLineHeight*2)
+	}
+	if fw, _ := font.MeasureSize("ab"); w <= fw {
+		t.Errorf("MeasureRichText(%q, 1): got width: %d, want: > %d", `a\s[200]b`, w, fw)
+	}
+}
@@ -17,6 +17,7 @@ package window
 import (
 	"fmt"
 	"image/color"
+	"sort"
 	"strings"
 
 	"github.com/hajimehoshi/ebiten"
@@ -25,7 +26,6 @@ import (
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/audio"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
 	"github.com/hajimehoshi/rpgsnack-runtime/internal/easymsgpack"
-	"github.com/hajimehoshi/rpgsnack-runtime/internal/font"
 )
 
 type typingEffect struct {
@@ -37,6 +37,13 @@ type typingEffect struct {
 	index                     int
 	delayCount                int
 	isSEPlayedInPreviousFrame bool
+	effectCount               int
+
+	// text is the visible content. This is updated when the content is set.
+	text *richText
+
+	// glyphStarts is the indices in content where the glyphs of text start.
+	glyphStarts []int
 }
 
 func newTypingEffect(content string, delay int, soundEffect string) *typingEffect {
@@ -102,20 +109,19 @@ func visibleContent(content string) string {
 	return c
 }
 
-func (t *typingEffect) visibleContent() []rune {
-	return []rune(visibleContent(string(t.content)))
-}
-
+// visibleIndex returns the number of the glyphs already typed.
 func (t *typingEffect) visibleIndex() int {
-	i := t.index
-	i -= strings.Count(string(t.content[:t.index]), controlWaitShort) * len(controlWaitShort)
-	i -= strings.Count(string(t.content[:t.index]), controlWaitLong) * len(controlWaitLong)
+	return sort.SearchInts(t.glyphStarts, t.index)
+}
 
-	max := len(t.visibleContent())
-	if i > max {
-		i = max
+// currentDelay returns the typing delay at the current index, which can be changed by the markup.
+func (t *typingEffect) currentDelay() int {
+	if i := t.visibleIndex(); i > 0 {
+		if d := t.text.glyphs[i-1].style.delay; d >= 0 {
+			return d
+		}
 	}
-	return i
+	return t.delay
 }
 
 func (t *typingEffect) forceQuit() bool {
@@ -150,6 +156,30 @@ func (t *typingEffect) trySkipAnim() {
 
 func (t *typingEffect) SetContent(content string) {
 	t.content = []rune(content)
+
+	// Parse the visible content once. indices maps the visible runes to the indices in content.
+	// This must be consistent with visibleContent.
+	visible := []rune{}
+	indices := []int{}
+	for i := 0; i < t.lastIndex(); {
+		if t.hasControlAt(i, []rune(controlWaitShort)) || t.hasControlAt(i, []rune(controlWaitLong)) {
+			i += 2
+			continue
+		}
+		if t.content[i] == '\r' && t.hasControlAt(i+1, []rune("\n")) {
+			i++
+			continue
+		}
+		visible = append(visible, t.content[i])
+		indices = append(indices, i)
+		i++
+	}
+	t.text = newRichText(string(visible))
+	t.glyphStarts = make([]int, len(t.text.glyphs))
+	for i, g := range t.text.glyphs {
+		t.glyphStarts[i] = indices[g.pos]
+	}
+
 	t.delayCount = t.delay
 	if t.index > 0 || t.delay == 0 {
 		t.index = t.lastIndex()
@@ -172,20 +202,44 @@ func (t *typingEffect) update() {
 			return
 		}
 
+		// Style tags are consumed without any delay.
+		for t.index < t.lastIndex() {
+			n := richTextStyleTagLen(t.content[t.index:t.lastIndex()])
+			if n == 0 {
+				break
+			}
+			t.index += n
+		}
+
 		played := false
 		if t.index < t.lastIndex() {
-			t.index++
+			// An icon tag is typed as one character.
+			if name, arg, n := richTextTag(t.content[t.index:t.lastIndex()]); n > 0 && isRichTextIcon(name, arg) {
+				t.index += n
+			} else {
+				t.index++
+			}
 			if !t.isSEPlayedInPreviousFrame && !t.isLastRuneSpace() {
 				played = t.playSE()
 			}
 		}
 		t.isSEPlayedInPreviousFrame = played
 		if t.index < t.lastIndex() {
-			t.delayCount = t.delay
+			t.delayCount = t.currentDelay()
 		}
 	}
 }
 
+// updateEffects updates the text effects like shaking. This is called even after the typing finishes.
+func (t *typingEffect) updateEffects() {
+	t.effectCount++
+}
+
+// size returns the size of the visible content in pixels at textScale.
+func (t *typingEffect) size(textScale int) (int, int) {
+	return t.text.size(textScale)
+}
+
 func (t *typingEffect) isLastRuneSpace() bool {
 	if t.index == 0 {
 		return false
@@ -194,10 +248,14 @@ func (t *typingEffect) isLastRuneSpace() bool {
 }
 
 func (t *typingEffect) hasControl(control []rune) bool {
-	if t.index+len(control) > t.lastIndex() {
+	return t.hasControlAt(t.index, control)
+}
+
+func (t *typingEffect) hasControlAt(index int, control []rune) bool {
+	if index+len(control) > t.lastIndex() {
 		return false
 	}
-	if string(t.content[t.index:t.index+len(control)]) == string(control) {
+	if string(t.content[index:index+len(control)]) == string(control) {
 		return true
 	}
 	return false
@@ -212,24 +270,5 @@ func (t *typingEffect) playSE() bool {
 }
 
 func (t *typingEffect) draw(screen *ebiten.Image, x, y int, textScale int, textAlign data.TextAlign, textColor color.Color, edgeColor color.Color, shadowColor color.Color) {
-	i := t.visibleIndex()
-	str := string(t.visibleContent())
-	if shadowColor != nil {
-		// Shadow
-		font.DrawText(screen, str, x+textScale*2, y, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x-textScale*2, y, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x, y+textScale*2, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x, y-textScale*2, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x+textScale, y+textScale, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x-textScale, y+textScale, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x+textScale, y-textScale, textScale, textAlign, shadowColor, i)
-		font.DrawText(screen, str, x-textScale, y-textScale, textScale, textAlign, shadowColor, i)
-
-		// Edge
-		font.DrawText(screen, str, x+textScale, y, textScale, textAlign, edgeColor, i)
-		font.DrawText(screen, str, x-textScale, y, textScale, textAlign, edgeColor, i)
-		font.DrawText(screen, str, x, y+textScale, textScale, textAlign, edgeColor, i)
-		font.DrawText(screen, str, x, y-textScale, textScale, textAlign, edgeColor, i)
-	}
-	font.DrawText(screen, str, x, y, textScale, textAlign, textColor, i)
+	t.text.draw(screen, t.visibleIndex(), x, y, textScale, textAlign, textColor, edgeColor, shadowColor, t.effectCount)
 }
@@ -33,10 +33,22 @@ import (
 const (
 	choiceBalloonHeight        = 20
 	chosenBalloonWaitingFrames = 5
+
+	// The frames to wait before proceeding automatically after the typing finishes.
+	autoAdvanceBaseFrames    = 60
+	autoAdvanceFramesPerRune = 4
+	skipReadFrames           = 6
 )
 
 type MessageSyntaxParser interface {
 	ParseMessageSyntax(content string) string
+
+	// MessageSyntaxVariables returns the current values of the variables that content refers to.
+	MessageSyntaxVariables(content string) map[int]int64
+
+	// ParseMessageSyntaxWithVariables is like ParseMessageSyntax, but uses the values in variables
+	// instead of the current values.
+	ParseMessageSyntaxWithVariables(content string, variables map[int]int64) string
 }
 
 type Windows struct {
@@ -51,9 +63,12 @@ type Windows struct {
 	choosingInterpreterID     int
 	chosenBalloonWaitingCount int
 	hasChosenIndex            bool
+	messageLog                []*MessageLogEntry
 
 	// Not dump
-	lastLang language.Tag
+	lastLang    language.Tag
+	autoAdvance bool
+	skipRead    bool
 }
 
 type Choice struct {
@@ -108,6 +123,13 @@ func (w *Windows) EncodeMsgpack(enc *msgpack.Encoder) error {
 	e.EncodeString("hasChosenIndex")
 	e.EncodeBool(w.hasChosenIndex)
 
+	e.EncodeString("messageLog")
+	e.BeginArray()
+	for _, l := range w.messageLog {
+		e.EncodeInterface(l)
+	}
+	e.EndArray()
+
 	e.EndMap()
 	return e.Flush()
 }
@@ -165,6 +187,15 @@ func (w *Windows) DecodeMsgpack(dec *msgpack.Decoder) error {
 			w.chosenBalloonWaitingCount = d.DecodeInt()
 		case "hasChosenIndex":
 			w.hasChosenIndex = d.DecodeBool()
+		case "messageLog":
+			if !d.SkipCodeIfNil() {
+				n := d.DecodeArrayLen()
+				w.messageLog = make([]*MessageLogEntry, n)
+				for i := 0; i < n; i++ {
+					w.messageLog[i] = &MessageLogEntry{}
+					d.DecodeInterface(w.messageLog[i])
+				}
+			}
 		default:
 			if err := d.Error(); err != nil {
 				return err
@@ -291,9 +322,67 @@ func (w *Windows) CanProceed(interpreterID int) bool {
 	if !w.isOpened(interpreterID) {
 		return false
 	}
+	if w.proceedsAutomatically(interpreterID) {
+		return true
+	}
 	return inputTriggered()
 }
 
+// proceedsAutomatically reports whether the opened windows can proceed without input
+// in the auto-advance mode or the skip-read mode.
+func (w *Windows) proceedsAutomatically(interpreterID int) bool {
+	if w.IsBusyWithChoosing() {
+		return false
+	}
+	if w.IsAnimating(interpreterID) {
+		return false
+	}
+	// Each window counts its own frames so that a window doesn't proceed by the frames counted for another window.
+	proceeds := false
+	for _, b := range w.balloons {
+		if b == nil || !b.isOpened() {
+			continue
+		}
+		if interpreterID > 0 && b.interpreterID != interpreterID {
+			continue
+		}
+		f := w.autoProceedingFrames(b.content, b.read)
+		if f < 0 {
+			continue
+		}
+		if b.proceedingCount < f {
+			return false
+		}
+		proceeds = true
+	}
+	if w.banner != nil && w.banner.isOpened() && (interpreterID == 0 || w.banner.interpreterID == interpreterID) {
+		if f := w.autoProceedingFrames(w.banner.content, w.banner.read); f >= 0 {
+			if w.banner.proceedingCount < f {
+				return false
+			}
+			proceeds = true
+		}
+	}
+	return proceeds
+}
+
+// autoProceedingFrames returns the frames to wait before proceeding automatically after the typing finishes.
+// autoProceedingFrames returns -1 when the window doesn't proceed automatically.
+func (w *Windows) autoProceedingFrames(content string, read bool) int {
+	if w.skipRead && read {
+		return skipReadFrames
+	}
+	if w.autoAdvance {
+		return autoAdvanceBaseFrames + autoAdvanceFramesPerRune*len([]rune(plainText(content)))
+	}
+	return -1
+}
+
+// skips reports whether the typing animation of the window is skipped in the skip-read mode.
+func (w *Windows) skips(read bool) bool {
+	return w.skipRead && read
+}
+
 func (w *Windows) isOpened(interpreterID int) bool {
 	for _, b := range w.balloons {
 		if b == nil {
@@ -374,6 +463,8 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 	if w.lastLang == language.Und {
 		w.lastLang = lang.Get()
 	}
+	w.autoAdvance = sceneManager.AutoAdvance()
+	w.skipRead = sceneManager.SkipRead()
 
 	if w.lastLang != lang.Get() {
 		for _, b := range w.balloons {
@@ -405,12 +496,16 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 		// TODO: Don't use magic numbers.
 		if w.nextBalloon != nil && !w.IsAnimating(0) && !w.isOpened(0) {
 			w.balloons = []*balloon{w.nextBalloon}
+			w.balloons[0].read = sceneManager.IsTextRead(w.nextBalloon.contentID)
 			w.balloons[0].open()
+			w.appendMessageLog(MessageLogTypeBalloon, w.nextBalloon.eventID, w.nextBalloon.contentID, parser, sceneManager.Game())
 			w.nextBalloon = nil
 		}
 		if w.nextBanner != nil && !w.IsAnimating(0) && !w.isOpened(0) {
 			w.banner = w.nextBanner
+			w.banner.read = sceneManager.IsTextRead(w.nextBanner.contentID)
 			w.banner.open()
+			w.appendMessageLog(MessageLogTypeMessage, w.nextBanner.eventID, w.nextBanner.contentID, parser, sceneManager.Game())
 			w.nextBanner = nil
 		}
 	}
@@ -446,6 +541,7 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 			}
 			b.close()
 		}
+		w.appendMessageLog(MessageLogTypeChoice, 0, w.choiceBalloons[w.chosenIndex].contentID, parser, sceneManager.Game())
 		w.chosenBalloonWaitingCount = chosenBalloonWaitingFrames
 		w.choosing = false
 		w.choosingInterpreterID = 0
@@ -456,9 +552,16 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 			continue
 		}
 		b.update(w.findCharacterByEventID(characters, b.eventID))
-		if b.isAnimating() && inputTriggered() {
+		if b.isOpened() && !b.isAnimating() {
+			b.proceedingCount++
+		} else {
+			b.proceedingCount = 0
+		}
+		if b.isAnimating() && inputTriggered() || w.skips(b.read) && b.isTyping() {
 			b.trySkipTypingAnim()
 		} else if b.isClosed() {
+			// The text is marked as read when the balloon is closed, not when it is opened.
+			sceneManager.MarkTextRead(b.contentID)
 			w.balloons[i] = nil
 		}
 	}
@@ -473,30 +576,38 @@ func (w *Windows) Update(playerY int, parser MessageSyntaxParser, sceneManager *
 	}
 	if w.banner != nil {
 		w.banner.update(playerY, w.findCharacterByEventID(characters, w.banner.eventID))
-		if w.banner.isAnimating() && inputTriggered() {
+		if w.banner.isOpened() && !w.banner.isAnimating() {
+			w.banner.proceedingCount++
+		} else {
+			w.banner.proceedingCount = 0
+		}
+		if w.banner.isAnimating() && inputTriggered() || w.skips(w.banner.read) && w.banner.isTyping() {
 			w.banner.trySkipTypingAnim()
 		} else if w.banner.isClosed() {
+			sceneManager.MarkTextRead(w.banner.contentID)
 			w.banner = nil
 		}
 	}
 }
 
-func (w *Windows) Draw(screen *ebiten.Image, characters []*character.Character, offsetX, offsetY, windowOffsetY int) {
+// Draw draws the windows. zoom is the camera zoom of the map, and the balloons follow the zoomed characters.
+func (w *Windows) Draw(screen *ebiten.Image, characters []*character.Character, offsetX, offsetY, windowOffsetY int, zoom float64) {
 	for _, b := range w.balloons {
 		if b == nil {
 			continue
 		}
-		b.draw(screen, w.findCharacterByEventID(characters, b.eventID), offsetX, offsetY)
+		b.draw(screen, w.findCharacterByEventID(characters, b.eventID), offsetX, offsetY, zoom)
 	}
+	// Banners and choices are not bound to the map and don't scroll horizontally.
 	if w.banner != nil {
-		w.banner.draw(screen, offsetX, 0)
+		w.banner.draw(screen, 0, 0)
 	}
 	_, sh := screen.Size()
 	for _, b := range w.choiceBalloons {
 		if b == nil {
 			continue
 		}
-		b.draw(screen, nil, offsetX, sh/consts.TileScale-windowOffsetY-len(w.choiceBalloons)*choiceBalloonHeight)
+		b.draw(screen, nil, 0, sh/consts.TileScale-windowOffsetY-len(w.choiceBalloons)*choiceBalloonHeight, 1)
 	}
 
 }
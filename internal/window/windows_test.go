@@ -0,0 +1,157 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package window_test
+
+import (
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/scene"
+	. "github.com/hajimehoshi/rpgsnack-runtime/internal/window"
+)
+
+func newTestSceneManager(t *testing.T, game *data.Game, permanent *scene.Permanent) *scene.Manager {
+	b, err := msgpack.Marshal(permanent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scene.NewManager(480, 720, nil, game, nil, b, nil, 0)
+}
+
+// framesToProceed updates the windows until the interpreter can proceed,
+// and returns the frames after the windows of the interpreter finish animating.
+// framesToProceed returns -1 when the interpreter can't proceed in maxFrames frames.
+func framesToProceed(w *Windows, interpreterID int, sceneManager *scene.Manager, maxFrames int) int {
+	p := &testParser{}
+	animated := false
+	start := -1
+	for i := 0; i < maxFrames; i++ {
+		w.Update(0, p, sceneManager, nil)
+		if w.IsAnimating(interpreterID) {
+			animated = true
+		} else if animated && start < 0 {
+			start = i
+		}
+		if w.CanProceed(interpreterID) {
+			return i - start
+		}
+	}
+	return -1
+}
+
+func TestWindowsAutoAdvance(t *testing.T) {
+	g := newTestGame()
+	// testParser makes the content "0", which has one rune.
+	const want = 60 + 4*1 - 1
+
+	cases := []struct {
+		Name        string
+		AutoAdvance bool
+		Want        int
+	}{
+		{
+			Name:        "auto advance",
+			AutoAdvance: true,
+			Want:        want,
+		},
+		{
+			Name:        "no auto advance",
+			AutoAdvance: false,
+			Want:        -1,
+		},
+	}
+	for _, c := range cases {
+		sceneManager := newTestSceneManager(t, g, &scene.Permanent{AutoAdvance: c.AutoAdvance})
+		w := &Windows{}
+		w.ShowBalloon(data.NewUUID(), &testParser{}, g, data.BalloonTypeNormal, 1, 1, g.CreateDefaultMessageStyle())
+		if got := framesToProceed(w, 1, sceneManager, 300); got != c.Want {
+			t.Errorf("%s: frames to proceed: got: %d, want: %d", c.Name, got, c.Want)
+		}
+	}
+}
+
+func TestWindowsAutoAdvanceCountsPerWindow(t *testing.T) {
+	g := newTestGame()
+	const want = 60 + 4*1 - 1
+
+	sceneManager := newTestSceneManager(t, g, &scene.Permanent{AutoAdvance: true})
+	w := &Windows{}
+	w.ShowBalloon(data.NewUUID(), &testParser{}, g, data.BalloonTypeNormal, 1, 1, g.CreateDefaultMessageStyle())
+	if got := framesToProceed(w, 1, sceneManager, 300); got != want {
+		t.Fatalf("frames to proceed for the first balloon: got: %d, want: %d", got, want)
+	}
+
+	// The next balloon of another interpreter waits for its own frames.
+	w.CloseAll()
+	w.ShowBalloon(data.NewUUID(), &testParser{}, g, data.BalloonTypeNormal, 2, 2, g.CreateDefaultMessageStyle())
+	if w.CanProceed(2) {
+		t.Errorf("CanProceed(2) right after ShowBalloon: got: true, want: false")
+	}
+	if got := framesToProceed(w, 2, sceneManager, 300); got != want {
+		t.Errorf("frames to proceed for the second balloon: got: %d, want: %d", got, want)
+	}
+}
+
+func TestWindowsSkipRead(t *testing.T) {
+	g := newTestGame()
+	read := data.NewUUID()
+
+	cases := []struct {
+		Name      string
+		ContentID data.UUID
+		Want      int
+	}{
+		{
+			Name:      "read",
+			ContentID: read,
+			Want:      6 - 1,
+		},
+		{
+			Name:      "unread",
+			ContentID: data.NewUUID(),
+			Want:      -1,
+		},
+	}
+	for _, c := range cases {
+		sceneManager := newTestSceneManager(t, g, &scene.Permanent{SkipRead: true})
+		sceneManager.MarkTextRead(read)
+		w := &Windows{}
+		// The typing of a read text is skipped even with a slow typing effect.
+		style := &data.MessageStyle{TypingEffectDelay: 100}
+		w.ShowBalloon(c.ContentID, &testParser{}, g, data.BalloonTypeNormal, 1, 1, style)
+		if got := framesToProceed(w, 1, sceneManager, 300); got != c.Want {
+			t.Errorf("%s: frames to proceed: got: %d, want: %d", c.Name, got, c.Want)
+		}
+	}
+}
+
+func TestWindowsChoicesDontProceedAutomatically(t *testing.T) {
+	g := newTestGame()
+	read := data.NewUUID()
+	sceneManager := newTestSceneManager(t, g, &scene.Permanent{
+		AutoAdvance: true,
+		SkipRead:    true,
+	})
+	sceneManager.MarkTextRead(read)
+
+	w := &Windows{}
+	w.ShowBalloon(read, &testParser{}, g, data.BalloonTypeNormal, 1, 1, g.CreateDefaultMessageStyle())
+	w.ShowChoices(&testParser{}, g, []*Choice{{ID: read}, {ID: data.NewUUID()}}, 1)
+	if got := framesToProceed(w, 1, sceneManager, 300); got != -1 {
+		t.Errorf("frames to proceed: got: %d, want: -1", got)
+	}
+}
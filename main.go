@@ -45,7 +45,11 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := ebiten.Run(g.Update, sw, sh, game.Scale()*(*screenScale), ""); err != nil {
-		log.Fatal(err)
+	runErr := ebiten.Run(g.Update, sw, sh, game.Scale()*(*screenScale), "")
+	if err := g.Close(); err != nil {
+		log.Print(err)
+	}
+	if runErr != nil {
+		log.Fatal(runErr)
 	}
 }
@@ -0,0 +1,77 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+func showUsage() {
+	fmt.Fprintf(os.Stderr, "coverage -in PROJECT_PATH [-out MERGED_JSON_PATH] COVERAGE_JSON_PATH...\n")
+	flag.PrintDefaults()
+}
+
+func run(in, out string, paths []string) error {
+	b, err := ioutil.ReadFile(filepath.Join(in, "project.msgpack"))
+	if err != nil {
+		return err
+	}
+	var project *data.Project
+	if err := msgpack.Unmarshal(b, &project); err != nil {
+		return err
+	}
+	if project == nil || project.Data == nil {
+		return fmt.Errorf("coverage: no game data in %s", in)
+	}
+
+	r, err := Merge(paths)
+	if err != nil {
+		return err
+	}
+	if out != "" {
+		b, err := json.Marshal(r.Coverage())
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(out, b, 0644); err != nil {
+			return err
+		}
+	}
+	Report(os.Stdout, project.Data, r)
+	return nil
+}
+
+func main() {
+	in := flag.String("in", "", "input project path")
+	out := flag.String("out", "", "output path of the merged coverage JSON")
+	flag.Usage = showUsage
+	flag.Parse()
+	if *in == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := run(*in, *out, flag.Args()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/coverage"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+// Runs holds the number of runs that executed each command or branch.
+type Runs struct {
+	commands map[string]int
+	branches map[string]int
+}
+
+// Merge merges the coverage JSON files at paths.
+func Merge(paths []string) (*Runs, error) {
+	r := &Runs{
+		commands: map[string]int{},
+		branches: map[string]int{},
+	}
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var c *coverage.Coverage
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("coverage: parsing %s failed: %v", path, err)
+		}
+		for _, p := range c.Commands {
+			r.commands[p]++
+		}
+		for _, p := range c.Branches {
+			r.branches[p]++
+		}
+	}
+	return r, nil
+}
+
+// Coverage returns the commands and branches executed in at least one run.
+func (r *Runs) Coverage() *coverage.Coverage {
+	c := &coverage.Coverage{}
+	for p := range r.commands {
+		c.Commands = append(c.Commands, p)
+	}
+	for p := range r.branches {
+		c.Branches = append(c.Branches, p)
+	}
+	sort.Strings(c.Commands)
+	sort.Strings(c.Branches)
+	return c
+}
+
+type treeReport struct {
+	commands         int
+	executedCommands int
+	branches         int
+	takenBranches    int
+	missed           []string
+}
+
+func percentage(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// Report writes the coverage of each command tree in the game to w.
+func Report(w io.Writer, game *data.Game, r *Runs) {
+	trees := []string{}
+	reports := map[string]*treeReport{}
+	coverage.Walk(game, func(tree, path string, command *data.Command) {
+		t, ok := reports[tree]
+		if !ok {
+			t = &treeReport{}
+			reports[tree] = t
+			trees = append(trees, tree)
+		}
+		t.commands++
+		if r.commands[path] > 0 {
+			t.executedCommands++
+		} else {
+			t.missed = append(t.missed, fmt.Sprintf("not executed: %s (%s)", path, command.Name))
+		}
+		if command.Name != data.CommandNameIf && command.Name != data.CommandNameShowChoices {
+			return
+		}
+		for i := range command.Branches {
+			t.branches++
+			p := coverage.BranchPath(path, i)
+			if r.branches[p] > 0 {
+				t.takenBranches++
+				continue
+			}
+			// If the command itself is not executed, the branches are obviously not taken.
+			if r.commands[path] > 0 {
+				t.missed = append(t.missed, fmt.Sprintf("not taken: %s", p))
+			}
+		}
+	})
+
+	for _, tree := range trees {
+		t := reports[tree]
+		fmt.Fprintf(w, "%s: commands %d/%d (%.1f%%), branches %d/%d (%.1f%%)\n",
+			tree,
+			t.executedCommands, t.commands, percentage(t.executedCommands, t.commands),
+			t.takenBranches, t.branches, percentage(t.takenBranches, t.branches))
+		for _, m := range t.missed {
+			fmt.Fprintf(w, "\t%s\n", m)
+		}
+	}
+}
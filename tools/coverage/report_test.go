@@ -0,0 +1,98 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/tools/coverage"
+)
+
+func TestReport(t *testing.T) {
+	nop := &data.Command{
+		Name: data.CommandNameNop,
+	}
+	game := &data.Game{
+		CommonEvents: []*data.CommonEvent{
+			{
+				ID: 1,
+				Commands: []*data.Command{
+					{
+						Name: data.CommandNameIf,
+						Args: &data.CommandArgsIf{},
+						Branches: [][]*data.Command{
+							{nop},
+							{nop},
+						},
+					},
+					nop,
+				},
+			},
+			{
+				ID:       2,
+				Commands: []*data.Command{nop},
+			},
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "coverage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var paths []string
+	for i, s := range []string{
+		`{"commands":["commonEvents/1/commands/0","commonEvents/1/commands/0/branches/0/0"],"branches":["commonEvents/1/commands/0/branches/0"]}`,
+		`{"commands":["commonEvents/1/commands/0","commonEvents/1/commands/1"],"branches":[]}`,
+	} {
+		p := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		if err := ioutil.WriteFile(p, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	r, err := Merge(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCommands := []string{
+		"commonEvents/1/commands/0",
+		"commonEvents/1/commands/0/branches/0/0",
+		"commonEvents/1/commands/1",
+	}
+	if got := r.Coverage().Commands; !reflect.DeepEqual(got, wantCommands) {
+		t.Errorf("merged commands: got: %v, want: %v", got, wantCommands)
+	}
+
+	var buf bytes.Buffer
+	Report(&buf, game, r)
+	want := `commonEvents/1: commands 3/4 (75.0%), branches 1/2 (50.0%)
+	not taken: commonEvents/1/commands/0/branches/1
+	not executed: commonEvents/1/commands/0/branches/1/0 (nop)
+commonEvents/2: commands 0/1 (0.0%), branches 0/0 (100.0%)
+	not executed: commonEvents/2/commands/0 (nop)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Report:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
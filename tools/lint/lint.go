@@ -0,0 +1,381 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/commanditerator"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/variables"
+)
+
+// Finding is a likely mistake in the game data.
+//
+// Path is a slash-separated location like maps/1/rooms/2/events/3/pages/0/commands/4/branches/1/0.
+// Maps, rooms, events, common events, items, combines and hints are identified by their IDs.
+// Pages, commands and branches are identified by their indices.
+type Finding struct {
+	Path    string
+	Message string
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+type commandEntry struct {
+	path    string
+	command *data.Command
+}
+
+type linter struct {
+	game     *data.Game
+	findings []*Finding
+
+	// reads holds the first location where each variable is read.
+	reads       map[int]string
+	writes      map[int]struct{}
+	hasRefWrite bool
+}
+
+// Lint walks all the command trees in the game and returns the findings.
+func Lint(game *data.Game) []*Finding {
+	l := &linter{
+		game:   game,
+		reads:  map[int]string{},
+		writes: map[int]struct{}{},
+	}
+
+	for _, m := range game.Maps {
+		for _, r := range m.Rooms() {
+			for _, e := range r.Events {
+				for pi, p := range e.Pages() {
+					path := fmt.Sprintf("maps/%d/rooms/%d/events/%d/pages/%d", m.ID(), r.ID, e.ID(), pi)
+					for _, c := range p.Conditions {
+						l.readCondition(c, path)
+					}
+					l.lintCommands(p.Commands, path, m)
+					if p.Route != nil {
+						l.lintCommands(p.Route.Commands, path+"/route", m)
+					}
+				}
+			}
+		}
+	}
+	for _, e := range game.CommonEvents {
+		l.lintCommands(e.Commands, fmt.Sprintf("commonEvents/%d", e.ID), nil)
+	}
+	for _, i := range game.Items {
+		l.lintCommands(i.Commands, fmt.Sprintf("items/%d", i.ID), nil)
+	}
+	for _, c := range game.Combines {
+		l.lintCommands(c.Commands, fmt.Sprintf("combines/%d", c.ID), nil)
+	}
+	for _, h := range game.Hints {
+		l.lintCommands(h.Commands, fmt.Sprintf("hints/%d", h.ID), nil)
+	}
+
+	// A variable written via a reference can be any variable. Don't report unwritten variables in this case.
+	if !l.hasRefWrite {
+		ids := []int{}
+		for id := range l.reads {
+			if _, ok := l.writes[id]; ok {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			l.report(l.reads[id], "variable %d is read but never written", id)
+		}
+	}
+
+	return l.findings
+}
+
+func (l *linter) report(path string, format string, args ...interface{}) {
+	l.findings = append(l.findings, &Finding{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// flatten returns the commands in the same order as CommandIterator visits them.
+func flatten(commands []*data.Command, path string) []*commandEntry {
+	var entries []*commandEntry
+	commanditerator.Walk(commands, path, func(path string, command *data.Command) {
+		entries = append(entries, &commandEntry{
+			path:    path,
+			command: command,
+		})
+	})
+	return entries
+}
+
+// lintCommands lints one command tree.
+// m is the map where the commands are executed, or nil if the map is not determined.
+func (l *linter) lintCommands(commands []*data.Command, path string, m *data.Map) {
+	entries := flatten(commands, path+"/commands")
+
+	// Labels are shared in one command tree. The first label is used when there are duplicated ones.
+	labels := map[string]int{}
+	for i, e := range entries {
+		if e.command.Name != data.CommandNameLabel {
+			continue
+		}
+		name := e.command.Args.(*data.CommandArgsLabel).Name
+		if first, ok := labels[name]; ok {
+			l.report(e.path, "duplicated label %q (first defined at %s)", name, entries[first].path)
+			continue
+		}
+		labels[name] = i
+	}
+
+	for i, e := range entries {
+		c := e.command
+		switch c.Name {
+		case data.CommandNameGoto:
+			name := c.Args.(*data.CommandArgsGoto).Label
+			li, ok := labels[name]
+			if !ok {
+				l.report(e.path, "goto to undefined label %q", name)
+				break
+			}
+			if li < i && !hasBlockingCommand(entries[li+1:i]) {
+				l.report(e.path, "loop to label %q has no wait or blocking command", name)
+			}
+		case data.CommandNameShowChoices:
+			args := c.Args.(*data.CommandArgsShowChoices)
+			if len(c.Branches) == 0 {
+				l.report(e.path, "show_choices has no branches")
+			}
+			for _, cc := range args.Conditions {
+				if cc == nil {
+					continue
+				}
+				if cc.Visible != nil {
+					l.readCondition(cc.Visible, e.path)
+				}
+				if cc.Checked != nil {
+					l.readCondition(cc.Checked, e.path)
+				}
+			}
+		case data.CommandNameIf:
+			for _, cond := range c.Args.(*data.CommandArgsIf).Conditions {
+				l.readCondition(cond, e.path)
+			}
+		case data.CommandNameSetVariable:
+			l.lintSetVariable(c.Args.(*data.CommandArgsSetVariable), e.path)
+		case data.CommandNameLoadPermanent:
+			l.write(c.Args.(*data.CommandArgsLoadPermanent).VariableID)
+		case data.CommandNameSavePermanent:
+			l.read(c.Args.(*data.CommandArgsSavePermanent).VariableID, e.path)
+		case data.CommandNameTransfer:
+			args := c.Args.(*data.CommandArgsTransfer)
+			if args.ValueType == data.ValueTypeVariable {
+				l.read(args.RoomID, e.path)
+				l.read(args.X, e.path)
+				l.read(args.Y, e.path)
+				break
+			}
+			if !l.roomExists(m, args.RoomID) {
+				l.report(e.path, "transfer to nonexistent room %d", args.RoomID)
+			}
+		case data.CommandNameAddItem:
+			if args := c.Args.(*data.CommandArgsAddItem); args.IDValueType == data.ValueTypeVariable {
+				l.read(args.ID, e.path)
+			}
+		case data.CommandNameRemoveItem:
+			if args := c.Args.(*data.CommandArgsRemoveItem); args.IDValueType == data.ValueTypeVariable {
+				l.read(args.ID, e.path)
+			}
+		case data.CommandNameShowItem:
+			if args := c.Args.(*data.CommandArgsShowItem); args.IDValueType == data.ValueTypeVariable {
+				l.read(args.ID, e.path)
+			}
+		case data.CommandNameSetRoute:
+			l.lintCommands(c.Args.(*data.CommandArgsSetRoute).Commands, e.path+"/route", m)
+		}
+	}
+}
+
+func (l *linter) lintSetVariable(args *data.CommandArgsSetVariable, path string) {
+	switch args.IDType {
+	case data.SetVariableIDTypeRef:
+		l.read(args.ID, path)
+		l.hasRefWrite = true
+	default:
+		l.write(args.ID)
+	}
+
+	switch args.ValueType {
+	case data.SetVariableValueTypeConstant:
+		if args.Op == data.SetVariableOpDiv || args.Op == data.SetVariableOpMod {
+			if v, ok := data.InterfaceToInt(args.Value); ok && v == 0 {
+				l.report(path, "set_variable divides variable %d by zero", args.ID)
+			}
+		}
+	case data.SetVariableValueTypeVariable, data.SetVariableValueTypeVariableRef:
+		if v, ok := data.InterfaceToInt(args.Value); ok {
+			l.read(v, path)
+		}
+	}
+}
+
+func (l *linter) readCondition(c *data.Condition, path string) {
+	if c == nil || c.Type != data.ConditionTypeVariable {
+		return
+	}
+	l.read(c.ID, path)
+	if c.ValueType == data.ConditionValueTypeVariable {
+		if v, ok := data.InterfaceToInt(c.Value); ok {
+			l.read(v, path)
+		}
+	}
+}
+
+func (l *linter) read(id int, path string) {
+	// Reserved variables are managed by the runtime.
+	if id >= variables.ReservedID {
+		return
+	}
+	if _, ok := l.reads[id]; ok {
+		return
+	}
+	l.reads[id] = path
+}
+
+func (l *linter) write(id int) {
+	l.writes[id] = struct{}{}
+}
+
+func (l *linter) roomExists(m *data.Map, roomID int) bool {
+	maps := l.game.Maps
+	if m != nil {
+		maps = []*data.Map{m}
+	}
+	for _, m := range maps {
+		for _, r := range m.Rooms() {
+			if r.ID == roomID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasBlockingCommand reports whether any of the commands might take one or more frames.
+func hasBlockingCommand(entries []*commandEntry) bool {
+	for _, e := range entries {
+		c := e.command
+		switch c.Name {
+		case data.CommandNameWait,
+			data.CommandNameShowBalloon,
+			data.CommandNameShowMessage,
+			data.CommandNameShowChoices,
+			data.CommandNameShowHint,
+			data.CommandNameCallEvent,
+			data.CommandNameCallCommonEvent,
+			data.CommandNameReturn,
+			data.CommandNameEraseEvent,
+			data.CommandNameGotoTitle,
+			data.CommandNameSave,
+			data.CommandNamePurchase,
+			data.CommandNameShowAds,
+			data.CommandNameShare,
+			data.CommandNameOpenLink,
+			data.CommandNameShowShop,
+			data.CommandNameShowMainShop,
+			data.CommandNameShowMinigame,
+			data.CommandNameRequestReview,
+			data.CommandNameMoveCharacter,
+			data.CommandNameTurnCharacter,
+			data.CommandNameRotateCharacter,
+			data.CommandNameJumpCharacter:
+			return true
+		case data.CommandNameTransfer:
+			if c.Args.(*data.CommandArgsTransfer).Transition != data.TransferTransitionTypeNone {
+				return true
+			}
+		case data.CommandNameSetRoute:
+			if c.Args.(*data.CommandArgsSetRoute).Wait {
+				return true
+			}
+		case data.CommandNameSetAmbientLight:
+			if c.Args.(*data.CommandArgsSetAmbientLight).Wait {
+				return true
+			}
+		case data.CommandNameShowEmotion:
+			if c.Args.(*data.CommandArgsShowEmotion).Wait {
+				return true
+			}
+		case data.CommandNamePlayCharacterAnimation:
+			if c.Args.(*data.CommandArgsPlayCharacterAnimation).Wait {
+				return true
+			}
+		case data.CommandNameSetCharacterProperty:
+			if c.Args.(*data.CommandArgsSetCharacterProperty).Wait {
+				return true
+			}
+		case data.CommandNameShake:
+			if c.Args.(*data.CommandArgsShake).Wait {
+				return true
+			}
+		case data.CommandNameControlCamera:
+			if c.Args.(*data.CommandArgsControlCamera).Wait {
+				return true
+			}
+		case data.CommandNameGatherFollowers:
+			if a := c.Args.(*data.CommandArgsGatherFollowers); a.Gather && a.Wait {
+				return true
+			}
+		case data.CommandNameTintScreen:
+			if c.Args.(*data.CommandArgsTintScreen).Wait {
+				return true
+			}
+		case data.CommandNameSetCharacterOpacity:
+			if c.Args.(*data.CommandArgsSetCharacterOpacity).Wait {
+				return true
+			}
+		case data.CommandNameShowInventory:
+			if c.Args.(*data.CommandArgsShowInventory).Wait {
+				return true
+			}
+		case data.CommandNameMovePicture:
+			if c.Args.(*data.CommandArgsMovePicture).Wait {
+				return true
+			}
+		case data.CommandNameScalePicture:
+			if c.Args.(*data.CommandArgsScalePicture).Wait {
+				return true
+			}
+		case data.CommandNameRotatePicture:
+			if c.Args.(*data.CommandArgsRotatePicture).Wait {
+				return true
+			}
+		case data.CommandNameFadePicture:
+			if c.Args.(*data.CommandArgsFadePicture).Wait {
+				return true
+			}
+		case data.CommandNameTintPicture:
+			if c.Args.(*data.CommandArgsTintPicture).Wait {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main_test
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+	. "github.com/hajimehoshi/rpgsnack-runtime/tools/lint"
+)
+
+func label(name string) *data.Command {
+	return &data.Command{
+		Name: data.CommandNameLabel,
+		Args: &data.CommandArgsLabel{Name: name},
+	}
+}
+
+func gotoLabel(name string) *data.Command {
+	return &data.Command{
+		Name: data.CommandNameGoto,
+		Args: &data.CommandArgsGoto{Label: name},
+	}
+}
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Commands []*data.Command
+		Out      []string
+	}{
+		{
+			Name: "undefined label",
+			Commands: []*data.Command{
+				gotoLabel("foo"),
+			},
+			Out: []string{
+				`commonEvents/1/commands/0: goto to undefined label "foo"`,
+			},
+		},
+		{
+			Name: "duplicated label",
+			Commands: []*data.Command{
+				label("foo"),
+				{
+					Name: data.CommandNameIf,
+					Args: &data.CommandArgsIf{},
+					Branches: [][]*data.Command{
+						{},
+						{label("foo")},
+					},
+				},
+			},
+			Out: []string{
+				`commonEvents/1/commands/1/branches/1/0: duplicated label "foo" (first defined at commonEvents/1/commands/0)`,
+			},
+		},
+		{
+			Name: "busy loop",
+			Commands: []*data.Command{
+				label("foo"),
+				{
+					Name: data.CommandNameSetSwitch,
+					Args: &data.CommandArgsSetSwitch{ID: 1, Value: true},
+				},
+				gotoLabel("foo"),
+			},
+			Out: []string{
+				`commonEvents/1/commands/2: loop to label "foo" has no wait or blocking command`,
+			},
+		},
+		{
+			Name: "loop with wait",
+			Commands: []*data.Command{
+				label("foo"),
+				{
+					Name: data.CommandNameWait,
+					Args: &data.CommandArgsWait{Time: 1},
+				},
+				gotoLabel("foo"),
+			},
+			Out: nil,
+		},
+		{
+			Name: "choices without branches",
+			Commands: []*data.Command{
+				{
+					Name: data.CommandNameShowChoices,
+					Args: &data.CommandArgsShowChoices{},
+				},
+			},
+			Out: []string{
+				`commonEvents/1/commands/0: show_choices has no branches`,
+			},
+		},
+		{
+			Name: "division by zero",
+			Commands: []*data.Command{
+				{
+					Name: data.CommandNameSetVariable,
+					Args: &data.CommandArgsSetVariable{
+						ID:        1,
+						IDType:    data.SetVariableIDTypeVal,
+						Op:        data.SetVariableOpDiv,
+						ValueType: data.SetVariableValueTypeConstant,
+						Value:     0,
+					},
+				},
+			},
+			Out: []string{
+				`commonEvents/1/commands/0: set_variable divides variable 1 by zero`,
+			},
+		},
+		{
+			Name: "nonexistent room",
+			Commands: []*data.Command{
+				{
+					Name: data.CommandNameTransfer,
+					Args: &data.CommandArgsTransfer{
+						ValueType: data.ValueTypeConstant,
+						RoomID:    2,
+					},
+				},
+			},
+			Out: []string{
+				`commonEvents/1/commands/0: transfer to nonexistent room 2`,
+			},
+		},
+		{
+			Name: "unwritten variable",
+			Commands: []*data.Command{
+				{
+					Name: data.CommandNameSetVariable,
+					Args: &data.CommandArgsSetVariable{
+						ID:        1,
+						IDType:    data.SetVariableIDTypeVal,
+						Op:        data.SetVariableOpAssign,
+						ValueType: data.SetVariableValueTypeVariable,
+						Value:     2,
+					},
+				},
+			},
+			Out: []string{
+				`commonEvents/1/commands/0: variable 2 is read but never written`,
+			},
+		},
+	}
+	for _, c := range cases {
+		game := &data.Game{
+			CommonEvents: []*data.CommonEvent{
+				{
+					ID:       1,
+					Commands: c.Commands,
+				},
+			},
+		}
+		got := []string{}
+		for _, f := range Lint(game) {
+			got = append(got, f.String())
+		}
+		if len(got) != len(c.Out) {
+			t.Errorf("%s: got: %v, want: %v", c.Name, got, c.Out)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.Out[i] {
+				t.Errorf("%s: got: %v, want: %v", c.Name, got, c.Out)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 Hajime Hoshi
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/hajimehoshi/rpgsnack-runtime/internal/data"
+)
+
+func run(in string) ([]*Finding, error) {
+	b, err := ioutil.ReadFile(filepath.Join(in, "project.msgpack"))
+	if err != nil {
+		return nil, err
+	}
+	var project *data.Project
+	if err := msgpack.Unmarshal(b, &project); err != nil {
+		return nil, err
+	}
+	if project == nil || project.Data == nil {
+		return nil, fmt.Errorf("lint: no game data in %s", in)
+	}
+	return Lint(project.Data), nil
+}
+
+func main() {
+	in := flag.String("in", "", "input project path")
+	flag.Parse()
+	if *in == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	findings, err := run(*in)
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+}